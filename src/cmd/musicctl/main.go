@@ -0,0 +1,209 @@
+// musicctl manages the music library from a terminal. Library commands use
+// the HTTP API of the server, migrate connects to the database directly.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"music/pkg/client"
+	"os"
+	"slices"
+	"strings"
+)
+
+const usage = `Usage: musicctl [flags] <command> [arguments]
+
+Commands:
+  songs list [filter flags]            list songs
+  songs get GROUP SONG                 show a song with its lyrics
+  songs add --group G --song S ...     add a song
+  songs update GROUP SONG [--field]... change the given fields of a song
+  songs delete GROUP SONG              delete a song
+  lyrics show GROUP SONG [--lang L]    print the lyrics of a song
+  import FILE                          import songs from a JSON or YAML file, - for stdin
+  export [filter flags] [--file F]     export songs in the import format
+  migrate up|down|redo|status          run the database migrations
+  migrate to VERSION                   migrate up or down to the version
+  users create LOGIN                   register a user, the password is read from stdin
+
+Flags:
+`
+
+// command is a leaf command, args are the arguments after its name.
+type command func(a *app, args []string) error
+
+var commands = map[string]map[string]command{
+	"songs": {
+		"list":   songsList,
+		"get":    songsGet,
+		"add":    songsAdd,
+		"update": songsUpdate,
+		"delete": songsDelete,
+	},
+	"lyrics": {
+		"show": lyricsShow,
+	},
+	"import": {"": importSongs},
+	"export": {"": exportSongs},
+	"migrate": {
+		"up":     migrateUp,
+		"down":   migrateDown,
+		"redo":   migrateRedo,
+		"to":     migrateTo,
+		"status": migrateStatus,
+	},
+	"users": {
+		"create": usersCreate,
+	},
+}
+
+// app is the state shared by the commands.
+type app struct {
+	profile Profile
+	output  string
+	stdin   io.Reader
+	stdout  io.Writer
+
+	client *client.Client
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "musicctl:", err)
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) && len(apiErr.Suggestions) > 0 {
+			fmt.Fprintln(os.Stderr, "Did you mean:")
+			for _, s := range apiErr.Suggestions {
+				fmt.Fprintf(os.Stderr, "  %s - %s\n", s.Group, s.Song)
+			}
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("musicctl", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	profileName := fs.String("profile", os.Getenv("MUSICCTL_PROFILE"), "profile of the profiles file")
+	url := fs.String("url", "", "server URL, overrides the profile")
+	apiKey := fs.String("api-key", "", "API key, overrides the profile")
+	token := fs.String("token", "", "bearer token, overrides the profile")
+	output := fs.String("o", "table", "output format: table, json or yaml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch *output {
+	case "table", "json", "yaml":
+	default:
+		return fmt.Errorf("Unknown output format %q, expected table, json or yaml", *output)
+	}
+
+	profile, err := loadProfile(*profileName)
+	if err != nil {
+		return err
+	}
+	if *url != "" {
+		profile.URL = *url
+	}
+	if *apiKey != "" {
+		profile.APIKey = *apiKey
+	}
+	if *token != "" {
+		profile.Token = *token
+	}
+
+	a := &app{profile: profile, output: *output, stdin: os.Stdin, stdout: os.Stdout}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	group, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("Unknown command %q, see musicctl -h", args[0])
+	}
+	if cmd, ok := group[""]; ok {
+		return cmd(a, args[1:])
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("%s needs a subcommand: %s", args[0], subcommands(group))
+	}
+	cmd, ok := group[args[1]]
+	if !ok {
+		return fmt.Errorf("Unknown command %q, expected one of: %s", args[0]+" "+args[1], subcommands(group))
+	}
+
+	return cmd(a, args[2:])
+}
+
+func subcommands(group map[string]command) string {
+	return strings.Join(slices.Sorted(maps.Keys(group)), ", ")
+}
+
+// api returns the client of the profile server.
+func (a *app) api() (*client.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+
+	opts := []client.Option{client.WithUserAgent("musicctl")}
+	switch {
+	case a.profile.Token != "":
+		opts = append(opts, client.WithAuth(client.Bearer(a.profile.Token)))
+	case a.profile.APIKey != "":
+		opts = append(opts, client.WithAuth(client.APIKey(a.profile.APIKey)))
+	}
+
+	c, err := client.New(a.profile.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	a.client = c
+
+	return c, nil
+}
+
+// parse parses the flags of a command that takes exactly want positional
+// arguments, in any order with the flags.
+func parse(fs *flag.FlagSet, args []string, want ...string) ([]string, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: musicctl %s", fs.Name())
+		for _, name := range want {
+			fmt.Fprintf(fs.Output(), " %s", name)
+		}
+		fmt.Fprintln(fs.Output(), " [flags]")
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) != len(want) {
+		fs.Usage()
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", fs.Name(), len(want), len(positional))
+	}
+
+	return positional, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"music/pkg/client"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		lang    string
+		wantErr bool
+	}{
+		{name: "positional", args: []string{"Muse", "Hysteria"}, want: []string{"Muse", "Hysteria"}},
+		{name: "flags between", args: []string{"Muse", "--lang", "ru", "Hysteria"}, want: []string{"Muse", "Hysteria"}, lang: "ru"},
+		{name: "flags first", args: []string{"-lang=en", "Muse", "Hysteria"}, want: []string{"Muse", "Hysteria"}, lang: "en"},
+		{name: "missing", args: []string{"Muse"}, wantErr: true},
+		{name: "extra", args: []string{"Muse", "Hysteria", "Absolution"}, wantErr: true},
+		{name: "unknown flag", args: []string{"--year", "2003", "Muse", "Hysteria"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("lyrics show", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			lang := fs.String("lang", "", "")
+
+			got, err := parse(fs, tt.args, "GROUP", "SONG")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) || *lang != tt.lang {
+				t.Errorf("parse() = %q with lang %q, want %q with lang %q", got, *lang, tt.want, tt.lang)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Setenv("MUSICCTL_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "output", args: []string{"-o", "xml", "songs", "list"}, want: `Unknown output format "xml"`},
+		{name: "command", args: []string{"albums", "list"}, want: `Unknown command "albums"`},
+		{name: "no subcommand", args: []string{"songs"}, want: "songs needs a subcommand: add, delete, get, list, update"},
+		{name: "subcommand", args: []string{"migrate", "sideways"}, want: `Unknown command "migrate sideways"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := run(tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("run() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	t.Setenv("MUSICCTL_CONFIG", path)
+
+	if got, err := loadProfile(""); err != nil || got != (Profile{URL: defaultURL}) {
+		t.Errorf("loadProfile() without a file = %+v, %v, want the local server", got, err)
+	}
+	if _, err := loadProfile("prod"); err == nil {
+		t.Error("loadProfile(prod) without a file error = nil, want an error")
+	}
+
+	data := "current: prod\nprofiles:\n  prod:\n    url: https://music.example.com\n    api_key: secret\n  local:\n    token: abc\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		want    Profile
+		wantErr bool
+	}{
+		{name: "", want: Profile{URL: "https://music.example.com", APIKey: "secret"}},
+		{name: "local", want: Profile{URL: defaultURL, Token: "abc"}},
+		{name: "staging", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadProfile(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("loadProfile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeSongs(t *testing.T) {
+	want := []client.SongRequest{{Group: "Muse", Song: "Hysteria", ReleaseDate: "2003-12-01", Text: "It's bugging me\nGrating me"}}
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		want    []client.SongRequest
+		wantErr bool
+	}{
+		{
+			name:   "json",
+			data:   `[{"group":"Muse","song":"Hysteria","release_date":"2003-12-01","text":"It's bugging me\nGrating me"}]`,
+			format: "json",
+			want:   want,
+		},
+		{
+			name:   "yaml",
+			data:   "- group: Muse\n  song: Hysteria\n  release_date: \"2003-12-01\"\n  text: |-\n    It's bugging me\n    Grating me\n",
+			format: "yaml",
+			want:   want,
+		},
+		{name: "unknown field", data: `[{"group":"Muse","artist":"Muse"}]`, format: "json", wantErr: true},
+		{name: "unknown format", data: `[]`, format: "csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSongs([]byte(tt.data), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeSongs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeSongs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileFormat(t *testing.T) {
+	tests := []struct {
+		path, format, want string
+	}{
+		{path: "songs.yml", want: "yaml"},
+		{path: "songs.yaml", want: "yaml"},
+		{path: "songs.json", want: "json"},
+		{path: "-", want: "json"},
+		{path: "songs.txt", format: "yaml", want: "yaml"},
+	}
+
+	for _, tt := range tests {
+		if got := fileFormat(tt.path, tt.format); got != tt.want {
+			t.Errorf("fileFormat(%q, %q) = %q, want %q", tt.path, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "line", input: "s3cret\nrest\n", want: "s3cret"},
+		{name: "CRLF", input: "s3cret\r\n", want: "s3cret"},
+		{name: "no newline", input: "s3cret", want: "s3cret"},
+		{name: "empty line", input: "\n", wantErr: true},
+		{name: "empty input", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPassword(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("readPassword() = %q, %v, want %q, error %t", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	songs := []client.Song{{ID: 1, Group: "Muse", Song: "Hysteria", Text: "one\ntwo"}}
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{
+			output: "table",
+			want:   "ID  GROUP  SONG      ALBUM  RELEASE DATE\n1   Muse   Hysteria         \n",
+		},
+		{
+			output: "json",
+			want:   "[\n  {\n    \"ID\": 1,\n    \"group\": \"Muse\",\n    \"song\": \"Hysteria\",\n    \"album\": \"\",\n    \"release_date\": \"\",\n    \"text\": \"one\\ntwo\"\n  }\n]\n",
+		},
+		{
+			output: "yaml",
+			want:   "- ID: 1\n  group: Muse\n  song: Hysteria\n  album: \"\"\n  release_date: \"\"\n  text: |-\n    one\n    two\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			var out bytes.Buffer
+			a := &app{output: tt.output, stdout: &out}
+			if err := a.print(songs, songsTable(songs)); err != nil {
+				t.Fatalf("print() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("print() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
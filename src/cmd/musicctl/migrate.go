@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"music/internal/base"
+	"music/internal/config"
+	"strconv"
+
+	"github.com/pressly/goose/v3"
+)
+
+// migrator connects to the database of the profile, or of the server
+// config.env when the profile has none. The migrations are embedded, so only
+// the config.env fallback depends on running from the src directory.
+func (a *app) migrator(name string, args []string, fn func(ctx context.Context, m *base.Migrator, args []string) error, want ...string) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	dsn := fs.String("dsn", a.profile.Database, "database connection string, config.env by default")
+	positional, err := parse(fs, args, want...)
+	if err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		cfg, err := config.NewConfig()
+		if err != nil {
+			return err
+		}
+		*dsn = cfg.GetConfigSQL()
+	}
+
+	m, db, err := base.NewMigrator(*dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(context.Background(), m, positional)
+}
+
+func migrateUp(a *app, args []string) error {
+	return a.migrator("migrate up", args, func(ctx context.Context, m *base.Migrator, _ []string) error {
+		results, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+
+		return a.printResults(results)
+	})
+}
+
+// migrateDown rolls back the latest applied migration.
+func migrateDown(a *app, args []string) error {
+	return a.migrator("migrate down", args, func(ctx context.Context, m *base.Migrator, _ []string) error {
+		result, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+
+		return a.printResults([]*goose.MigrationResult{result})
+	})
+}
+
+// migrateRedo rolls back the latest applied migration and applies it again.
+func migrateRedo(a *app, args []string) error {
+	return a.migrator("migrate redo", args, func(ctx context.Context, m *base.Migrator, _ []string) error {
+		results, err := m.Redo(ctx)
+		if err != nil {
+			return err
+		}
+
+		return a.printResults(results)
+	})
+}
+
+// migrateTo migrates up or down to the version, 0 rolls back everything.
+func migrateTo(a *app, args []string) error {
+	return a.migrator("migrate to", args, func(ctx context.Context, m *base.Migrator, args []string) error {
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("Invalid version: %s", args[0])
+		}
+
+		results, err := m.To(ctx, version)
+		if err != nil {
+			return err
+		}
+
+		return a.printResults(results)
+	}, "VERSION")
+}
+
+func migrateStatus(a *app, args []string) error {
+	return a.migrator("migrate status", args, func(ctx context.Context, m *base.Migrator, _ []string) error {
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		type status struct {
+			Version   int64  `json:"version"`
+			File      string `json:"file"`
+			State     string `json:"state"`
+			AppliedAt string `json:"applied_at,omitempty"`
+		}
+		list := make([]status, 0, len(statuses))
+		t := table{header: []string{"VERSION", "FILE", "STATE", "APPLIED AT"}}
+		for _, s := range statuses {
+			var appliedAt string
+			if !s.AppliedAt.IsZero() {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			list = append(list, status{s.Source.Version, s.Source.Path, string(s.State), appliedAt})
+			t.rows = append(t.rows, []string{fmt.Sprint(s.Source.Version), s.Source.Path, string(s.State), appliedAt})
+		}
+
+		return a.print(list, t)
+	})
+}
+
+func (a *app) printResults(results []*goose.MigrationResult) error {
+	type result struct {
+		Version   int64  `json:"version"`
+		File      string `json:"file"`
+		Direction string `json:"direction"`
+		Duration  string `json:"duration"`
+	}
+	list := make([]result, 0, len(results))
+	t := table{header: []string{"VERSION", "FILE", "DIRECTION", "DURATION"}}
+	for _, r := range results {
+		list = append(list, result{r.Source.Version, r.Source.Path, r.Direction, r.Duration.String()})
+		t.rows = append(t.rows, []string{fmt.Sprint(r.Source.Version), r.Source.Path, r.Direction, r.Duration.String()})
+	}
+	if len(list) == 0 && a.output == "table" {
+		_, err := fmt.Fprintln(a.stdout, "No migrations to run")
+		return err
+	}
+
+	return a.print(list, t)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"music/pkg/client"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// table is the tabular form of a value, rows have a cell per header column.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes v as JSON or YAML, or its table in the table format.
+func (a *app) print(v any, t table) error {
+	switch a.output {
+	case "json":
+		return writeJSON(a.stdout, v)
+	case "yaml":
+		return writeYAML(a.stdout, v)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeYAML converts v through JSON, so the keys are the json tags of the API
+// types and keep their order.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// blockStyle drops the flow style the JSON input gave the node, keeping
+// multiline strings such as lyrics readable.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func songsTable(songs []client.Song) table {
+	t := table{header: []string{"ID", "GROUP", "SONG", "ALBUM", "RELEASE DATE"}}
+	for _, song := range songs {
+		t.rows = append(t.rows, []string{fmt.Sprint(song.ID), song.Group, song.Song, song.Album, song.ReleaseDate})
+	}
+
+	return t
+}
+
+// fields is a table of one record, a row per field.
+func fields(pairs ...string) table {
+	t := table{header: []string{"FIELD", "VALUE"}}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		t.rows = append(t.rows, []string{pairs[i], pairs[i+1]})
+	}
+
+	return t
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultURL = "http://localhost:8888"
+
+// Profile is a server musicctl talks to. Database is a lib/pq connection
+// string used by migrate, the server config.env is used when it is empty.
+type Profile struct {
+	URL      string `yaml:"url"`
+	APIKey   string `yaml:"api_key"`
+	Token    string `yaml:"token"`
+	Database string `yaml:"database"`
+}
+
+// profiles is the profiles file:
+//
+//	current: local
+//	profiles:
+//	  local:
+//	    url: http://localhost:8888
+//	    api_key: <key>
+type profiles struct {
+	Current  string             `yaml:"current"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// profilesPath is $MUSICCTL_CONFIG or musicctl/profiles.yaml in the user
+// config directory.
+func profilesPath() (string, error) {
+	if path := os.Getenv("MUSICCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "musicctl", "profiles.yaml"), nil
+}
+
+// loadProfile returns the named profile, or the current one when name is
+// empty. Without a profiles file the local server is used.
+func loadProfile(name string) (Profile, error) {
+	path, err := profilesPath()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && name == "" {
+		return Profile{URL: defaultURL}, nil
+	}
+	if err != nil {
+		return Profile{}, fmt.Errorf("Failed to read profiles. Error: %w", err)
+	}
+
+	var file profiles
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Profile{}, fmt.Errorf("Failed to parse profiles %s. Error: %w", path, err)
+	}
+
+	if name == "" {
+		name = file.Current
+	}
+	if name == "" {
+		return Profile{URL: defaultURL}, nil
+	}
+	profile, ok := file.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("Profile %q is not in %s", name, path)
+	}
+	if profile.URL == "" {
+		profile.URL = defaultURL
+	}
+
+	return profile, nil
+}
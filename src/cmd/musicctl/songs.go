@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"music/pkg/client"
+	"os"
+	"strings"
+)
+
+// list is a repeatable string flag.
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// filterFlags registers the /music/filter criteria on fs.
+func filterFlags(fs *flag.FlagSet) *client.SongFilter {
+	var filter client.SongFilter
+	fs.Var((*list)(&filter.Group), "group", "group name, repeatable")
+	fs.Var((*list)(&filter.Song), "song", "song name, repeatable")
+	fs.Var((*list)(&filter.Album), "album", "album, repeatable")
+	fs.Var((*list)(&filter.ReleaseDate), "release-date", "release date, repeatable")
+	fs.Var((*list)(&filter.Tags), "tag", "tag, repeatable")
+	fs.StringVar(&filter.TagMode, "tag-mode", "", "all (default) or any of the tags")
+	fs.StringVar(&filter.Sort, "sort", "", "group, song, album or release_date, descending with a leading -")
+
+	return &filter
+}
+
+func songsList(a *app, args []string) error {
+	fs := flag.NewFlagSet("songs list", flag.ContinueOnError)
+	filter := filterFlags(fs)
+	limit := fs.Int("limit", 0, "maximum number of songs, 0 for all")
+	pageSize := fs.Int("page-size", client.DefaultPageSize, "songs per request")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+
+	songs := make([]client.Song, 0)
+	for song, err := range api.FilterSongs(context.Background(), *filter, *pageSize) {
+		if err != nil {
+			return err
+		}
+		songs = append(songs, song)
+		if *limit > 0 && len(songs) == *limit {
+			break
+		}
+	}
+
+	return a.print(songs, songsTable(songs))
+}
+
+func songsGet(a *app, args []string) error {
+	fs := flag.NewFlagSet("songs get", flag.ContinueOnError)
+	positional, err := parse(fs, args, "GROUP", "SONG")
+	if err != nil {
+		return err
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+	filter := client.SongFilter{Group: []string{positional[0]}, Song: []string{positional[1]}}
+	song, err := api.Filter(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	return a.print(song, fields(
+		"ID", fmt.Sprint(song.ID),
+		"GROUP", song.Group,
+		"SONG", song.Song,
+		"ALBUM", song.Album,
+		"RELEASE DATE", song.ReleaseDate,
+		"LINES", fmt.Sprint(strings.Count(song.Text, "\n")+min(len(song.Text), 1)),
+	))
+}
+
+// songFlags registers the song fields on fs. Text is read from a file, -
+// for stdin.
+func songFlags(fs *flag.FlagSet) (song *client.SongRequest, textFile *string) {
+	song = &client.SongRequest{}
+	fs.StringVar(&song.Group, "group", "", "group name")
+	fs.StringVar(&song.Song, "song", "", "song name")
+	fs.StringVar(&song.Album, "album", "", "album")
+	fs.StringVar(&song.ReleaseDate, "release-date", "", "release date, YYYY-MM-DD")
+	fs.StringVar(&song.Text, "text", "", "lyrics")
+	textFile = fs.String("text-file", "", "read the lyrics from a file, - for stdin")
+
+	return song, textFile
+}
+
+func (a *app) readFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(a.stdin)
+	}
+
+	return os.ReadFile(path)
+}
+
+func songsAdd(a *app, args []string) error {
+	fs := flag.NewFlagSet("songs add", flag.ContinueOnError)
+	song, textFile := songFlags(fs)
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if *textFile != "" {
+		text, err := a.readFile(*textFile)
+		if err != nil {
+			return err
+		}
+		song.Text = string(text)
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+	if err := api.AddSong(context.Background(), *song); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Added %s - %s\n", song.Group, song.Song)
+	return nil
+}
+
+func songsUpdate(a *app, args []string) error {
+	fs := flag.NewFlagSet("songs update", flag.ContinueOnError)
+	song, textFile := songFlags(fs)
+	positional, err := parse(fs, args, "GROUP", "SONG")
+	if err != nil {
+		return err
+	}
+
+	// Only the flags given on the command line are changed.
+	var update client.SongUpdateRequest
+	var readErr error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "group":
+			update.Group = &song.Group
+		case "song":
+			update.Song = &song.Song
+		case "album":
+			update.Album = &song.Album
+		case "release-date":
+			update.ReleaseDate = &song.ReleaseDate
+		case "text":
+			update.Text = &song.Text
+		case "text-file":
+			var text []byte
+			text, readErr = a.readFile(*textFile)
+			song.Text = string(text)
+			update.Text = &song.Text
+		}
+	})
+	if readErr != nil {
+		return readErr
+	}
+	if update == (client.SongUpdateRequest{}) {
+		return errors.New("At least one field flag is required")
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+	if err := api.UpdateSong(context.Background(), positional[0], positional[1], update); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Updated %s - %s\n", positional[0], positional[1])
+	return nil
+}
+
+func songsDelete(a *app, args []string) error {
+	fs := flag.NewFlagSet("songs delete", flag.ContinueOnError)
+	positional, err := parse(fs, args, "GROUP", "SONG")
+	if err != nil {
+		return err
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+	if err := api.DeleteSong(context.Background(), positional[0], positional[1]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Deleted %s - %s\n", positional[0], positional[1])
+	return nil
+}
+
+func lyricsShow(a *app, args []string) error {
+	fs := flag.NewFlagSet("lyrics show", flag.ContinueOnError)
+	lang := fs.String("lang", "", "translation language, BCP-47")
+	positional, err := parse(fs, args, "GROUP", "SONG")
+	if err != nil {
+		return err
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+	text, err := api.Lyrics(context.Background(), positional[0], positional[1], *lang)
+	if err != nil {
+		return err
+	}
+
+	if a.output == "table" {
+		_, err := fmt.Fprintln(a.stdout, text)
+		return err
+	}
+
+	return a.print(map[string]string{"group": positional[0], "song": positional[1], "text": text}, table{})
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"music/pkg/client"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// importBatch keeps an import request well below the server body limit.
+const importBatch = 500
+
+func importSongs(a *app, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "json or yaml, detected by the file extension by default")
+	batch := fs.Int("batch", importBatch, "songs per request")
+	positional, err := parse(fs, args, "FILE")
+	if err != nil {
+		return err
+	}
+	if *batch < 1 {
+		return fmt.Errorf("Batch must be positive, got %d", *batch)
+	}
+
+	data, err := a.readFile(positional[0])
+	if err != nil {
+		return err
+	}
+	songs, err := decodeSongs(data, fileFormat(positional[0], *format))
+	if err != nil {
+		return fmt.Errorf("Failed to parse %s. Error: %w", positional[0], err)
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+
+	var total client.ImportResult
+	for start := 0; start < len(songs); start += *batch {
+		result, err := api.Import(context.Background(), songs[start:min(start+*batch, len(songs))])
+		if err != nil {
+			return fmt.Errorf("Failed to import songs %d-%d. Error: %w", start+1, min(start+*batch, len(songs)), err)
+		}
+		total.Imported += result.Imported
+		total.Skipped += result.Skipped
+	}
+
+	return a.print(total, table{
+		header: []string{"IMPORTED", "SKIPPED"},
+		rows:   [][]string{{fmt.Sprint(total.Imported), fmt.Sprint(total.Skipped)}},
+	})
+}
+
+func fileFormat(path, format string) string {
+	if format != "" {
+		return format
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+
+	return "json"
+}
+
+// decodeSongs reads a list of songs in the import format. YAML is converted
+// through JSON so both formats use the json keys of the API.
+func decodeSongs(data []byte, format string) ([]client.SongRequest, error) {
+	switch format {
+	case "json":
+	case "yaml":
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Unknown format %q, expected json or yaml", format)
+	}
+
+	var songs []client.SongRequest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&songs); err != nil {
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+// exportSongs writes the matching songs in the import format, so an export
+// can be imported into another server.
+func exportSongs(a *app, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	filter := filterFlags(fs)
+	file := fs.String("file", "", "output file, stdout by default")
+	pageSize := fs.Int("page-size", client.DefaultPageSize, "songs per request")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+
+	songs := make([]client.SongRequest, 0)
+	for song, err := range api.FilterSongs(context.Background(), *filter, *pageSize) {
+		if err != nil {
+			return err
+		}
+		songs = append(songs, client.SongRequest{
+			Group:       song.Group,
+			Song:        song.Song,
+			Album:       song.Album,
+			ReleaseDate: song.ReleaseDate,
+			Text:        song.Text,
+		})
+	}
+
+	write := writeJSON
+	if a.output == "yaml" || fileFormat(*file, "") == "yaml" {
+		write = writeYAML
+	}
+	if *file == "" {
+		return write(a.stdout, songs)
+	}
+
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	if err := write(f, songs); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d songs to %s\n", len(songs), *file)
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"music/pkg/client"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// usersCreate registers a user. The password is the first line of stdin, so
+// it does not end up in the shell history. A terminal does not echo it.
+func usersCreate(a *app, args []string) error {
+	fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+	positional, err := parse(fs, args, "LOGIN")
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword(a.stdin)
+	if err != nil {
+		return err
+	}
+
+	api, err := a.api()
+	if err != nil {
+		return err
+	}
+	user, err := api.Register(context.Background(), client.Credentials{Login: positional[0], Password: password})
+	if err != nil {
+		return err
+	}
+
+	return a.print(user, fields(
+		"ID", fmt.Sprint(user.ID),
+		"LOGIN", user.Login,
+		"CREATED AT", user.CreatedAt.Format("2006-01-02 15:04:05"),
+	))
+}
+
+// readPassword reads the first line of stdin. On a terminal it prompts on
+// stderr and turns the echo off while the password is typed.
+func readPassword(stdin io.Reader) (string, error) {
+	var (
+		password string
+		err      error
+	)
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		var typed []byte
+		typed, err = term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(os.Stderr)
+		password = string(typed)
+	} else {
+		password, err = bufio.NewReader(stdin).ReadString('\n')
+		password = strings.TrimRight(password, "\r\n")
+	}
+
+	if password == "" {
+		if err != nil {
+			return "", fmt.Errorf("Failed to read the password. Error: %w", err)
+		}
+		return "", errors.New("Password is required")
+	}
+
+	return password, nil
+}
@@ -1,39 +1,141 @@
 package main
 
 import (
+	"context"
 	"log"
+	"music/internal/auth"
 	"music/internal/base"
+	"music/internal/cache"
 	"music/internal/config"
+	"music/internal/feed"
+	"music/internal/outbox"
+	"music/internal/rpc"
 	"music/internal/service"
+	"music/internal/webhook"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 	_ "music/docs"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+// @title Music library API
+// @version 1.0
+// @description Онлайн библиотека песен
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT в формате "Bearer <token>"
 func main(){
+	if err := run(); err != nil{
+		log.Fatalln(err)
+	}
+}
 
-
+// run работает до сигнала остановки или ошибки одного из серверов, отложенные вызовы останавливают gRPC и закрывают базу
+func run() error{
 	config, err := config.NewConfig()
 	if err != nil{
-		log.Fatalln(err)
+		return err
 	}
 
 	repository, err := base.NewRepository(config)
 	if err != nil{
-		log.Fatalln(err)
+		return err
+	}
+
+	// Запросы библиотеки, фильтра и текстов кэшируются, если задан CACHE_TTL
+	if ttl, size := config.GetCache(); ttl > 0 && size > 0 {
+		repository = cache.NewRepository(repository, cache.NewLRU(size, ttl))
+	}
+
+	authenticator, err := auth.NewAuthenticator(config,repository)
+	if err != nil{
+		return err
 	}
 
-	service := service.NewService(config,repository)
+	// События изменений публикуются из outbox, вебхуки доставляются в фоне до остановки сервера
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	dispatcher := webhook.NewDispatcher(repository)
+	hub := feed.NewHub(1000)
+	sinks := []outbox.Sink{outbox.LogSink{}, dispatcher, hub}
+	// Конверты событий публикуются в шину внутри процесса, если задан EVENT_BUS_PREFIX
+	if prefix := config.GetEventBusPrefix(); prefix != "" {
+		bus := outbox.NewMemoryBus()
+		bus.Subscribe(prefix+".>", func(subject string, data []byte) {
+			log.Printf("Bus message %s: %s", subject, data)
+		})
+		sinks = append(sinks, outbox.NewBusSink(bus, prefix))
+	}
+	events := outbox.NewDispatcher(repository, config.GetOutboxRetention(), sinks...)
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		events.Run(ctx)
+	}()
+
+	// База закрывается после того, как фоновые доставки вернули свои аренды
+	service := service.NewService(config,repository,authenticator,dispatcher,hub)
 	defer func(){
+		stop()
+		workers.Wait()
 		if err := service.Close(); err != nil{
-			log.Fatalln(err)
+			log.Println(err)
 		}
 	}()
 
+	// gRPC работает на отдельном порту, если задан GRPC_PORT. Ошибка gRPC останавливает и HTTP-сервер
+	grpcErr := make(chan error, 1)
+	if port := config.GetGRPCPort(); port != "" {
+		listener, err := net.Listen("tcp", port)
+		if err != nil {
+			return err
+		}
+		grpcServer := rpc.NewServer(config, repository, authenticator)
+		defer grpcServer.GracefulStop()
+		go func() {
+			log.Printf("gRPC server running on port %s", port)
+			if err := grpcServer.Serve(listener); err != nil {
+				grpcErr <- err
+				stop()
+			}
+		}()
+	}
+
     // Подключение Swagger UI
     service.Router().PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
+    // По сигналу остановки потоки событий закрываются, остальные запросы завершаются
+    go func() {
+        <-ctx.Done()
+        shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        if err := service.Shutdown(shutdownCtx); err != nil {
+            log.Println(err)
+        }
+    }()
+
     if err := service.Run(); err != nil {
-        log.Fatalln(err)
+        return err
+    }
+
+    select {
+    case err := <-grpcErr:
+        return err
+    default:
+        return nil
     }
 }
\ No newline at end of file
@@ -15,9 +15,78 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/music": {
+        "/artists/{group}/lyrics/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Суммирует статистику текстов всех песен группы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Статистика текстов группы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык стоп-слов, по умолчанию определяется по текстам",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество частых слов, от 1 до 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статистика",
+                        "schema": {
+                            "$ref": "#/definitions/service.ArtistLyricsStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid top",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
             "post": {
-                "description": "Добавляет новую песню в библиотеку, если она еще не существует",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Схема описывает песни, исполнителей, альбомы, теги и тексты. Песни фильтруются, сортируются и разбиваются на страницы так же, как в /music/filter. Запросы глубже 8 уровней или с оценкой более 20000 полей (списки считаются по их size, списки без size, например теги, — по 10) отклоняются до выполнения. На запросы действует лимит тяжелых запросов. IDE GraphiQL доступна на /graphiql/",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,49 +94,93 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "music"
+                    "graphql"
                 ],
-                "summary": "Добавить песню",
+                "summary": "GraphQL",
                 "parameters": [
                     {
-                        "description": "Информация о новой песне",
-                        "name": "song",
+                        "description": "GraphQL-запрос",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Song"
+                            "$ref": "#/definitions/graph.Request"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Песня успешно добавлена"
+                    "200": {
+                        "description": "Результат с полями data и errors",
+                        "schema": {
+                            "type": "object"
+                        }
                     },
                     "400": {
-                        "description": "Ошибка декодирования JSON",
+                        "description": "Malformed request or the query exceeds the depth or complexity limit",
                         "schema": {
-                            "type": "string"
+                            "type": "object"
                         }
                     },
-                    "500": {
-                        "description": "Что-то пошло не так",
+                    "401": {
+                        "description": "Authentication required when reads are not public",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
             }
         },
-        "/music/filter": {
+        "/me": {
             "get": {
-                "description": "Возвращает список песен, отфильтрованных по заданным критериям",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "music"
+                    "users"
                 ],
-                "summary": "Фильтрация песен",
+                "summary": "Текущий пользователь",
+                "responses": {
+                    "200": {
+                        "description": "Текущий пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Principal"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/favorites": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает избранные песни, поддерживает те же фильтры, что и /music/filter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Избранное",
                 "parameters": [
                     {
                         "type": "string",
@@ -78,7 +191,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список отфильтрованных песен",
+                        "description": "Избранные песни",
                         "schema": {
                             "type": "array",
                             "items": {
@@ -86,25 +199,123 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/favorites/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Добавить в избранное",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Песня добавлена в избранное"
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
                     "404": {
                         "description": "Song not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Удалить из избранного",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Песня удалена из избранного"
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song is not in favorites",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
             }
         },
-        "/music/filter/{page}/{size}": {
+        "/me/favorites/{page}/{size}": {
             "get": {
-                "description": "Возвращает список песен c фильтром и пагинацией",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "music"
+                    "users"
                 ],
-                "summary": "Получить библиотеку песен c фильтром и пагинацией",
+                "summary": "Избранное с пагинацией",
                 "parameters": [
                     {
                         "type": "integer",
@@ -119,11 +330,17 @@ const docTemplate = `{
                         "name": "size",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Критерии фильтрации в формате ключ=значение",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Избранные песни",
                         "schema": {
                             "type": "array",
                             "items": {
@@ -134,50 +351,62 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid page number or size",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Song not found",
+                    "401": {
+                        "description": "User session required",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
             }
         },
-        "/music/library": {
+        "/me/lists": {
             "get": {
-                "description": "Возвращает полную библиотеку песен",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "music"
+                    "users"
                 ],
-                "summary": "Получить библиотеку песен",
+                "summary": "Списки прослушивания",
                 "responses": {
                     "200": {
-                        "description": "Полный список песен",
+                        "description": "Списки пользователя",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Song"
+                                "$ref": "#/definitions/model.List"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Failed to fetch library data",
+                    "401": {
+                        "description": "User session required",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/music/{group}/{song}": {
-            "put": {
-                "description": "Обновляет информацию о песне на основе имени группы и названия песни",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -185,278 +414,4585 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "music"
+                    "users"
                 ],
-                "summary": "Обновить песню",
+                "summary": "Создать список",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя группы",
-                        "name": "group",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Название песни",
-                        "name": "song",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Обновленная информация о песне",
-                        "name": "song",
+                        "description": "Название списка",
+                        "name": "list",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Song"
+                            "$ref": "#/definitions/dto.ListRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Песня успешно обновлена"
+                    "201": {
+                        "description": "Созданный список",
+                        "schema": {
+                            "$ref": "#/definitions/model.List"
+                        }
                     },
                     "400": {
                         "description": "Invalid request payload",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Failed to update song",
+                    "401": {
+                        "description": "User session required",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "List already exists",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/me/lists/{list}": {
             "delete": {
-                "description": "Удаляет песню на основе имени группы и названия песни",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "tags": [
-                    "music"
+                    "users"
                 ],
-                "summary": "Удалить песню",
+                "summary": "Удалить список",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя группы",
-                        "name": "group",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Название песни",
-                        "name": "song",
+                        "type": "integer",
+                        "description": "ID списка",
+                        "name": "list",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "Песня успешно удалена"
+                        "description": "Список удален"
                     },
-                    "500": {
-                        "description": "Failed to delete song",
+                    "400": {
+                        "description": "Invalid list",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
                         }
-                    }
-                }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "List not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
             }
         },
-        "/music/{group}/{song}/lyrics": {
+        "/me/lists/{list}/songs": {
             "get": {
-                "description": "Возвращает текст песни на основе имени группы и названия песни",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает песни списка в порядке добавления, с пагинацией при указании page и size",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "music"
+                    "users"
                 ],
-                "summary": "Получить текст песни",
+                "summary": "Песни списка",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя группы",
-                        "name": "group",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Название песни",
-                        "name": "song",
+                        "type": "integer",
+                        "description": "ID списка",
+                        "name": "list",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Текст песни",
+                        "description": "Песни списка",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid list, page number or size",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
                         }
                     },
                     "404": {
-                        "description": "Song not found",
+                        "description": "List not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
             }
         },
-        "/music/{group}/{song}/lyrics/{page}/{size}": {
-            "get": {
-                "description": "Returns lyrics for a given group and title with pagination support",
-                "produces": [
-                    "application/json"
+        "/me/lists/{list}/songs/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "tags": [
-                    "music"
+                    "users"
                 ],
-                "summary": "Get lyrics with pagination",
+                "summary": "Добавить песню в список",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Group name",
-                        "name": "group",
+                        "type": "integer",
+                        "description": "ID списка",
+                        "name": "list",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Song title",
-                        "name": "song",
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Песня добавлена"
+                    },
+                    "400": {
+                        "description": "Invalid list or id",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
                     },
+                    "404": {
+                        "description": "List or song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Удалить песню из списка",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
+                        "description": "ID списка",
+                        "name": "list",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Page size",
-                        "name": "size",
+                        "description": "ID песни",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Lyrics",
+                    "204": {
+                        "description": "Песня удалена"
+                    },
+                    "400": {
+                        "description": "Invalid list or id",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/service.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Invalid page number or size",
+                    "401": {
+                        "description": "User session required",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
                         }
                     },
                     "404": {
-                        "description": "Song not found",
+                        "description": "List not found or song is not in the list",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
             }
         },
-        "/music/{page}/{size}": {
-            "get": {
-                "description": "Возвращает список песен с пагинацией",
+        "/music": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет новую песню в библиотеку, если она еще не существует",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "music"
                 ],
-                "summary": "Получить библиотеку песен с пагинацией",
+                "summary": "Добавить песню",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "path",
-                        "required": true
+                        "description": "Информация о новой песне",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SongRequest"
+                        }
                     },
                     {
-                        "type": "integer",
-                        "description": "Размер страницы",
-                        "name": "size",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Ключ идемпотентности, повтор с тем же ключом возвращает сохраненный ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Песня успешно добавлена"
+                    },
+                    "400": {
+                        "description": "Invalid JSON body",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Song"
-                            }
+                            "$ref": "#/definitions/service.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Invalid page number or size",
+                    "401": {
+                        "description": "Authentication required",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Song is already in the library or a request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to fetch library data",
+                        "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "model.Song": {
-            "type": "object",
-            "properties": {
-                "group": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "release_date": {
-                    "type": "string"
-                },
-                "song": {
-                    "type": "string"
-                },
-                "text": {
-                    "type": "string"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет все песни из библиотеки транзакциями по 500 песен. Каждая транзакция ограничена QUERY_TIMEOUT; при ошибке уже удаленные песни не восстанавливаются, повторный запрос удаляет оставшиеся",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Очистить библиотеку",
+                "responses": {
+                    "204": {
+                        "description": "Библиотека очищена"
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
                 }
             }
+        },
+        "/music/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выполняет до 500 операций create, update и delete по порядку. Песни адресуются по id или по group и song, values содержит новую песню или измененные поля. В режиме atomic (по умолчанию) пакет выполняется одной транзакцией и откатывается первой неудачной операцией, в режиме per_item каждая операция выполняется отдельно. Результат каждой операции содержит статус: 201 для create, 200 для update и delete, статус ошибки для неудачной операции и 424 для операций откаченного пакета",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Пакетные изменения",
+                "parameters": [
+                    {
+                        "description": "Операции",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности, повтор с тем же ключом возвращает сохраненный ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результаты операций",
+                        "schema": {
+                            "$ref": "#/definitions/service.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events с событиями song.created, song.updated и song.deleted. Данные события — конверт с полями id, type, created_at и data. После переподключения с заголовком Last-Event-ID (или параметром last_event_id) поток продолжается с пропущенных событий, если они еще в буфере, иначе сначала отправляется событие reset: клиент должен перезагрузить данные. Поток получает только события, опубликованные этим экземпляром сервера. Каждые 15 секунд отправляется комментарий heartbeat",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Поток изменений",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Только события этих групп",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid last event id",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required when reads are not public",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/filter": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список песен, отфильтрованных по заданным критериям",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Фильтрация песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Альбом",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выхода",
+                        "name": "release_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Теги, параметр можно повторять",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "all (по умолчанию) — песня содержит все теги, any — хотя бы один",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки: group, song, album или release_date, с префиксом - по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список отфильтрованных песен",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required when reads are not public",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Unsupported Accept",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/filter/{page}/{size}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список песен c фильтром и пагинацией",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Получить библиотеку песен c фильтром и пагинацией",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "size",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Альбом",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выхода",
+                        "name": "release_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Теги, параметр можно повторять",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "all (по умолчанию) — песня содержит все теги, any — хотя бы один",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки: group, song, album или release_date, с префиксом - по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid page number, size or filter",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required when reads are not public",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Unsupported Accept",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет список песен транзакциями по 500 песен, пропуская уже существующие. Каждая транзакция ограничена QUERY_TIMEOUT; при ошибке уже добавленные части остаются в библиотеке, повторный импорт того же списка пропускает их",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Импортировать песни",
+                "parameters": [
+                    {
+                        "description": "Список песен",
+                        "name": "songs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SongRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности, повтор с тем же ключом возвращает сохраненный ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат импорта",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/library": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает полную библиотеку песен. Формат ответа выбирается заголовком Accept: JSON (по умолчанию), NDJSON, CSV или MessagePack",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Получить библиотеку песен",
+                "responses": {
+                    "200": {
+                        "description": "Полный список песен",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Song"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required when reads are not public",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Unsupported Accept",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/suggest": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ищет песни по группе и названию без учета регистра и диакритики, по префиксу и по сходству триграмм",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Подсказки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Строка поиска",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество подсказок, от 1 до 50, по умолчанию 10",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подсказки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Suggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Missing query or invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/{group}/{song}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет информацию о песне на основе имени группы и названия песни",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Обновить песню",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновленная информация о песне",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SongUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня успешно обновлена"
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found, suggestions lists similar songs",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет песню на основе имени группы и названия песни",
+                "tags": [
+                    "music"
+                ],
+                "summary": "Удалить песню",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Песня успешно удалена"
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found, suggestions lists similar songs",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/{group}/{song}/lyrics": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает текст песни на основе имени группы и названия песни. Язык выбирается по параметру lang или заголовку Accept-Language, если перевода нет, возвращается оригинал. С заголовком Accept: text/plain текст возвращается без JSON",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Получить текст песни",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык в формате BCP-47, имеет приоритет над Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые языки",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Текст песни",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "Язык текста"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required when reads are not public",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found, suggestions lists similar songs",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Unsupported Accept",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/{group}/{song}/lyrics/{page}/{size}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns lyrics for a given group and title with pagination support",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Get lyrics with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song title",
+                        "name": "song",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 1000",
+                        "name": "size",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lyrics",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid page number or size",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required when reads are not public",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Unsupported Accept",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/music/{page}/{size}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список песен с пагинацией",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Получить библиотеку песен с пагинацией",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "size",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid page number or size",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required when reads are not public",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Unsupported Accept",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Database query timed out",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает публичные плейлисты, а также плейлисты, которыми пользователь владеет или которые редактирует",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Список плейлистов",
+                "responses": {
+                    "200": {
+                        "description": "Плейлисты",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Playlist"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Создать плейлист",
+                "parameters": [
+                    {
+                        "description": "Плейлист",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PlaylistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный плейлист",
+                        "schema": {
+                            "$ref": "#/definitions/model.Playlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Получить плейлист",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Плейлист",
+                        "schema": {
+                            "$ref": "#/definitions/model.Playlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Удалить плейлист",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Плейлист удален"
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner may delete the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет название, описание и видимость плейлиста. Доступно только владельцу",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Изменить плейлист",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "fields",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PlaylistUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Измененный плейлист",
+                        "schema": {
+                            "$ref": "#/definitions/model.Playlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner may change the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist}/collaborators/{user}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Добавить соавтора",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Соавтор добавлен"
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner may change the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Удалить соавтора",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Соавтор удален"
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner may change the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found or user is not a collaborator",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist}/entries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает записи плейлиста в порядке позиций",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Песни плейлиста",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Записи плейлиста",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PlaylistEntry"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Вставляет песню на указанную позицию, сдвигая следующие записи. Без позиции песня добавляется в конец",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Добавить песню в плейлист",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Песня и позиция",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EntryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Добавленная запись",
+                        "schema": {
+                            "$ref": "#/definitions/model.PlaylistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or position",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner and collaborators may edit the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist or song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist}/entries/{entry}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Удалить песню из плейлиста",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "entry",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Запись удалена"
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner and collaborators may edit the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist or entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Переместить песню в плейлисте",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "entry",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая позиция",
+                        "name": "position",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MoveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Запись перемещена"
+                    },
+                    "400": {
+                        "description": "Invalid request payload or position",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner and collaborators may edit the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist or entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{playlist}/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выгружает плейлист в формате M3U или XSPF. Песни указываются как urn:music:song:{id}",
+                "produces": [
+                    "audio/x-mpegurl",
+                    "application/xspf+xml"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Экспорт плейлиста",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плейлиста",
+                        "name": "playlist",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат: m3u (по умолчанию) или xspf",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Плейлист",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Playlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "post": {
+                "description": "Проверяет логин и пароль и выдает токен сессии для заголовка Authorization: Bearer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Вход",
+                "parameters": [
+                    {
+                        "description": "Логин и пароль",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Токен сессии",
+                        "schema": {
+                            "$ref": "#/definitions/service.SessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid login or password",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает токен сессии, переданный в заголовке Authorization",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Выход",
+                "responses": {
+                    "204": {
+                        "description": "Сессия завершена"
+                    },
+                    "401": {
+                        "description": "User session required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Группирует песни с одинаковыми названиями после нормализации (регистр, диакритика, пробелы, пометки feat.) и песни одной группы с похожими текстами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Дубликаты песен",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Минимальное сходство текстов от 0 до 1, по умолчанию 0.8",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Группы вероятных дубликатов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dedup.Cluster"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid threshold",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/merge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переносит в песню-победителя выбранные поля, записи плейлистов, избранное, списки, теги и тексты остальных песен, затем удаляет их",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "music"
+                ],
+                "summary": "Объединить песни",
+                "parameters": [
+                    {
+                        "description": "Победитель, объединяемые песни и выбор полей",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Объединенная песня",
+                        "schema": {
+                            "$ref": "#/definitions/model.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает текст песни в формате LRC, JSON или обычным текстом. С параметром at возвращает строку, активную в указанный момент, в том же формате",
+                "produces": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Получить синхронизированный текст",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат: json (по умолчанию), lrc или text. С at: строка JSON, строка LRC или текст строки",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент времени в формате mm:ss.xx",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Синхронизированный текст",
+                        "schema": {
+                            "$ref": "#/definitions/service.SyncedLyricsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported format or invalid time",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found, song has no synced lyrics or no line is active yet",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Принимает файл LRC или расширенный LRC с метками слов и заменяет синхронизированный текст песни. Если у песни нет обычного текста, он заполняется строками файла",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Загрузить синхронизированный текст",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Файл LRC",
+                        "name": "lyrics",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Разобранные строки",
+                        "schema": {
+                            "$ref": "#/definitions/service.SyncedLyricsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed LRC file",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет строки с метками времени, обычный текст песни сохраняется",
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Удалить синхронизированный текст",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Синхронизированный текст удален"
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song has no synced lyrics",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/aligned": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сопоставляет куплеты оригинала и перевода по порядку. Куплеты разделяются пустой строкой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Оригинал и перевод рядом",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык перевода в формате BCP-47, имеет приоритет над Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Предпочитаемые языки",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Куплеты",
+                        "schema": {
+                            "$ref": "#/definitions/service.AlignedLyrics"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found or no translation in the language",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Считает куплеты, строки и слова, долю уникальных слов, самые частые слова без стоп-слов и время чтения. Результат кешируется до изменения текста",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Статистика текста",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык стоп-слов, по умолчанию определяется по тексту",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество частых слов, от 1 до 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статистика",
+                        "schema": {
+                            "$ref": "#/definitions/lyrics.Stats"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid top",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/variants": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает оригинал и переводы текста песни, оригинал первым",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Варианты текста",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Варианты текста",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.LyricsVariant"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/variants/{lang}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает или заменяет текст песни на указанном языке. Вариант, отмеченный как оригинал, заменяет текст песни",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Сохранить вариант текста",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык в формате BCP-47",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Текст",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VariantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Вариант сохранен"
+                    },
+                    "400": {
+                        "description": "Invalid language tag or payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет перевод. Оригинал удалить нельзя",
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Удалить вариант текста",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык в формате BCP-47",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Вариант удален"
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Variant not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The original can not be deleted",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Теги песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Теги песни",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Tag"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмечает песню тегом, создавая тег при необходимости",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Добавить тег песне",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тег",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Тег",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/tags/{tag}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Снять тег с песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID тега",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Тег снят"
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Song is not tagged with the tag",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Список тегов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Тип тега: genre, mood, language или other",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Теги",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Создать тег",
+                "parameters": [
+                    {
+                        "description": "Тег",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный тег",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Tag already exists",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/cloud": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает теги с количеством песен, начиная с самых популярных",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Облако тегов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Тип тега: genre, mood, language или other",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Облако тегов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TagCount"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{tag}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет тег и снимает его со всех песен",
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Удалить тег",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID тега",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Тег удален"
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Editor role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "description": "Создает учетную запись пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Регистрация",
+                "parameters": [
+                    {
+                        "description": "Логин и пароль",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Login is already taken",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Список вебхуков",
+                "responses": {
+                    "200": {
+                        "description": "Вебхуки без секретов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Подписывает URL на события song.created, song.updated и song.deleted, пустой список событий подписывает на все. Время отправки в секундах Unix и тело запроса, соединенные точкой, подписываются HMAC-SHA256 с секретом вебхука: время передается в заголовке X-Music-Timestamp, подпись — в заголовке X-Music-Signature в виде sha256=\u003chex\u003e. Получатель отклоняет запросы старше 5 минут. Если секрет не задан, он генерируется и возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Создать вебхук",
+                "parameters": [
+                    {
+                        "description": "URL, секрет и события",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный вебхук с секретом",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает последние 100 доставок всех вебхуков, исчерпавших попытки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Недоставленные события",
+                "responses": {
+                    "200": {
+                        "description": "Недоставленные события",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{delivery}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает недоставленное событие в очередь с новым набором попыток",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Повторить доставку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID доставки",
+                        "name": "delivery",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Доставка в очереди",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid delivery id",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Delivery is not dead",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "webhook",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Вебхук без секрета",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет вебхук вместе с его журналом доставок",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удалить вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "webhook",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Вебхук удален"
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает последние 100 доставок вебхука, начиная с новых",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Журнал доставок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "webhook",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус: pending, delivered или dead",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Доставки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/service.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.Principal": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "base.Violation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dedup.Cluster": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Song"
+                    }
+                }
+            }
+        },
+        "dto.BatchOperationRequest": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "values": {
+                    "$ref": "#/definitions/dto.SongUpdateRequest"
+                }
+            }
+        },
+        "dto.BatchRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "per_item"
+                    ]
+                },
+                "operations": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchOperationRequest"
+                    }
+                }
+            }
+        },
+        "dto.Credentials": {
+            "type": "object",
+            "required": [
+                "login",
+                "password"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 3
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                }
+            }
+        },
+        "dto.EntryRequest": {
+            "type": "object",
+            "required": [
+                "song_id"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "song_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ListRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "dto.MergeRequest": {
+            "type": "object",
+            "required": [
+                "fields",
+                "losers",
+                "winner"
+            ],
+            "properties": {
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "losers": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "winner": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.MoveRequest": {
+            "type": "object",
+            "required": [
+                "position"
+            ],
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.PlaylistRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 4096
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "public": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.PlaylistUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 4096
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "public": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.SongRequest": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "album": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 65536
+                }
+            }
+        },
+        "dto.SongUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "group": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 65536
+                }
+            }
+        },
+        "dto.TagRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name"
+            ],
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "genre",
+                        "mood",
+                        "language",
+                        "other"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "dto.VariantRequest": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "original": {
+                    "type": "boolean"
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 65536
+                }
+            }
+        },
+        "dto.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "graph.Request": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "lyrics.Pair": {
+            "type": "object",
+            "properties": {
+                "original": {
+                    "type": "string"
+                },
+                "translation": {
+                    "type": "string"
+                }
+            }
+        },
+        "lyrics.Stats": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "integer"
+                },
+                "reading_time_seconds": {
+                    "type": "integer"
+                },
+                "top_words": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/lyrics.WordCount"
+                    }
+                },
+                "unique_ratio": {
+                    "type": "number"
+                },
+                "unique_words": {
+                    "type": "integer"
+                },
+                "verses": {
+                    "type": "integer"
+                },
+                "words": {
+                    "type": "integer"
+                }
+            }
+        },
+        "lyrics.WordCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "word": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ImportResult": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.List": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LyricLine": {
+            "type": "object",
+            "properties": {
+                "position": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "time_ms": {
+                    "type": "integer"
+                },
+                "words": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LyricWord"
+                    }
+                }
+            }
+        },
+        "model.LyricWord": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "time_ms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.LyricsVariant": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string"
+                },
+                "original": {
+                    "type": "boolean"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Playlist": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PlaylistEntry": {
+            "type": "object",
+            "properties": {
+                "added_by": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "playlist_id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "song": {
+                    "$ref": "#/definitions/model.Song"
+                },
+                "song_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Song": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Suggestion": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Tag": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TagCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.AlignedLyrics": {
+            "type": "object",
+            "properties": {
+                "original": {
+                    "type": "string"
+                },
+                "translation": {
+                    "type": "string"
+                },
+                "verses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/lyrics.Pair"
+                    }
+                }
+            }
+        },
+        "service.ArtistLyricsStats": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "integer"
+                },
+                "reading_time_seconds": {
+                    "type": "integer"
+                },
+                "songs": {
+                    "type": "integer"
+                },
+                "top_words": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/lyrics.WordCount"
+                    }
+                },
+                "unique_ratio": {
+                    "type": "number"
+                },
+                "unique_words": {
+                    "type": "integer"
+                },
+                "verses": {
+                    "type": "integer"
+                },
+                "words": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BatchOperationResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/service.Problem"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Song"
+                    }
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BatchOperationResult"
+                    }
+                }
+            }
+        },
+        "service.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/base.Violation"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "suggestions": {
+                    "description": "Suggestions lists similar songs when a song was not found.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Suggestion"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.SyncedLyricsResponse": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LyricLine"
+                    }
+                },
+                "song_id": {
+                    "type": "integer"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT в формате \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1.0",
 	Host:             "",
 	BasePath:         "",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Music library API",
+	Description:      "Онлайн библиотека песен",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
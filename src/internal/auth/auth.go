@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"music/internal/base"
+	"music/internal/config"
+	"music/internal/model"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Sessions resolves the session tokens issued to users at login.
+type Sessions interface {
+	SessionUser(ctx context.Context, tokenHash string) (model.User, error)
+}
+
+type Authenticator struct {
+	keys     map[string]Role
+	jwks     *keySet
+	parser   *jwt.Parser
+	sessions Sessions
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Role  string   `json:"role"`
+	Roles []string `json:"roles"`
+}
+
+func NewAuthenticator(cfg config.Config, sessions Sessions) (*Authenticator, error) {
+	a := &Authenticator{
+		keys:     make(map[string]Role),
+		parser:   jwt.NewParser(jwt.WithValidMethods([]string{"HS256", "RS256"}), jwt.WithExpirationRequired()),
+		sessions: sessions,
+	}
+
+	for key, name := range cfg.GetAPIKeys() {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid API key role. Error: %s", err.Error())
+		}
+		a.keys[key] = role
+	}
+
+	if path := cfg.GetJWKSFile(); path != "" {
+		set, err := loadKeySet(path)
+		if err != nil {
+			return nil, err
+		}
+		a.jwks = set
+	}
+
+	return a, nil
+}
+
+// Authenticate identifies the caller by an API key, a session token or a JWT.
+// Requests without credentials are anonymous, invalid credentials are an error.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	return a.Resolve(r.Context(), r.Header.Get(APIKeyHeader), r.Header.Get("Authorization"))
+}
+
+// Resolve identifies the caller from the values of the API key and
+// Authorization headers, for transports other than HTTP.
+func (a *Authenticator) Resolve(ctx context.Context, key, header string) (Principal, error) {
+	if key != "" {
+		return a.apiKey(key)
+	}
+
+	if header == "" {
+		return Principal{Role: Anonymous}, nil
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Principal{}, base.Unauthenticated("Unsupported authorization scheme")
+	}
+
+	token = strings.TrimSpace(token)
+	if !strings.Contains(token, ".") {
+		return a.session(ctx, token)
+	}
+
+	return a.bearer(token)
+}
+
+func (a *Authenticator) apiKey(key string) (Principal, error) {
+	for known, role := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(key)) == 1 {
+			sum := sha256.Sum256([]byte(known))
+			return Principal{Subject: "api-key:" + hex.EncodeToString(sum[:8]), Role: role}, nil
+		}
+	}
+
+	return Principal{}, base.Unauthenticated("Invalid API key")
+}
+
+// session resolves an opaque session token. Users act with the reader role
+// on the catalog and own their favorites and lists.
+func (a *Authenticator) session(ctx context.Context, token string) (Principal, error) {
+	user, err := a.sessions.SessionUser(ctx, HashToken(token))
+	if errors.Is(err, base.ErrNotFound) {
+		return Principal{}, base.Unauthenticated("Invalid or expired session")
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{Subject: fmt.Sprintf("user:%d", user.ID), Role: Reader, UserID: user.ID}, nil
+}
+
+func (a *Authenticator) bearer(raw string) (Principal, error) {
+	if a.jwks == nil {
+		return Principal{}, base.Unauthenticated("Bearer tokens are not accepted")
+	}
+
+	var c claims
+	if _, err := a.parser.ParseWithClaims(raw, &c, a.jwks.keyFunc); err != nil {
+		// The parser error tells which key ids and algorithms are accepted,
+		// it is only logged.
+		log.Printf("Invalid bearer token. Error: %s", err)
+		return Principal{}, base.Unauthenticated("Invalid bearer token")
+	}
+
+	names := c.Roles
+	if c.Role != "" {
+		names = append(names, c.Role)
+	}
+
+	p := Principal{Subject: c.Subject, Role: Anonymous}
+	for _, name := range names {
+		if role, err := ParseRole(name); err == nil && role > p.Role {
+			p.Role = role
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"music/internal/base"
+	"music/internal/config"
+	"music/internal/model"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+type testConfig struct {
+	config.Config
+	keys map[string]string
+	jwks string
+}
+
+func (c testConfig) GetAPIKeys() map[string]string {
+	return c.keys
+}
+
+func (c testConfig) GetJWKSFile() string {
+	return c.jwks
+}
+
+type sessions map[string]model.User
+
+func (s sessions) SessionUser(ctx context.Context, tokenHash string) (model.User, error) {
+	user, ok := s[tokenHash]
+	if !ok {
+		return model.User{}, base.ErrNotFound
+	}
+
+	return user, nil
+}
+
+var secret = []byte("0123456789abcdef0123456789abcdef")
+
+// writeKeySet writes a JWKS file with a symmetric key "hs" and the RSA key
+// "rs" of the private key.
+func writeKeySet(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+	encode := base64.RawURLEncoding.EncodeToString
+	doc := map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hs", "k": encode(secret)},
+		{"kty": "RSA", "kid": "rs", "n": encode(key.N.Bytes()), "e": encode(big.NewInt(int64(key.E)).Bytes())},
+	}}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, c jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, c)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestResolve(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAuthenticator(testConfig{
+		keys: map[string]string{"reader-key": "reader", "admin-key": "admin"},
+		jwks: writeKeySet(t, private),
+	}, sessions{HashToken("session"): {ID: 7}})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name    string
+		key     string
+		header  string
+		want    Principal
+		wantErr bool
+	}{
+		{name: "anonymous", want: Principal{Role: Anonymous}},
+		{name: "api key", key: "admin-key", want: Principal{Role: Admin}},
+		{name: "unknown api key", key: "other", wantErr: true},
+		{name: "basic scheme", header: "Basic dXNlcjpwYXNz", wantErr: true},
+		{name: "session", header: "Bearer session", want: Principal{Subject: "user:7", Role: Reader, UserID: 7}},
+		{name: "unknown session", header: "bearer expired", wantErr: true},
+		{
+			name:   "hs256",
+			header: "Bearer " + sign(t, jwt.SigningMethodHS256, "hs", secret, jwt.MapClaims{"sub": "alice", "role": "editor", "exp": exp}),
+			want:   Principal{Subject: "alice", Role: Editor},
+		},
+		{
+			name:   "rs256 highest role",
+			header: "Bearer " + sign(t, jwt.SigningMethodRS256, "rs", private, jwt.MapClaims{"sub": "bob", "roles": []string{"reader", "admin", "owner"}, "exp": exp}),
+			want:   Principal{Subject: "bob", Role: Admin},
+		},
+		{
+			name:   "no known role",
+			header: "Bearer " + sign(t, jwt.SigningMethodHS256, "hs", secret, jwt.MapClaims{"sub": "carol", "role": "owner", "exp": exp}),
+			want:   Principal{Subject: "carol", Role: Anonymous},
+		},
+		{
+			name:    "expired",
+			header:  "Bearer " + sign(t, jwt.SigningMethodHS256, "hs", secret, jwt.MapClaims{"role": "admin", "exp": time.Now().Add(-time.Hour).Unix()}),
+			wantErr: true,
+		},
+		{
+			name:    "no expiration",
+			header:  "Bearer " + sign(t, jwt.SigningMethodHS256, "hs", secret, jwt.MapClaims{"role": "admin"}),
+			wantErr: true,
+		},
+		{
+			name:    "unknown key id",
+			header:  "Bearer " + sign(t, jwt.SigningMethodHS256, "other", secret, jwt.MapClaims{"role": "admin", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "rsa key as hmac secret",
+			header:  "Bearer " + sign(t, jwt.SigningMethodHS256, "rs", private.N.Bytes(), jwt.MapClaims{"role": "admin", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "unsigned",
+			header:  "Bearer " + sign(t, jwt.SigningMethodNone, "hs", jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"role": "admin", "exp": exp}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Resolve(context.Background(), tt.key, tt.header)
+			if tt.wantErr {
+				if !errors.Is(err, &base.Error{Kind: base.KindUnauthenticated}) {
+					t.Errorf("Resolve() error = %v, want unauthenticated", err)
+				}
+				// JWTs start with the encoded {" of their header.
+				if strings.HasPrefix(tt.header, "Bearer ey") && err.Error() != "Invalid bearer token" {
+					t.Errorf("Resolve() error = %v, want the parser error hidden", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if tt.key != "" {
+				// API keys are identified by a hash of the key.
+				got.Subject = ""
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBearerWithoutKeySet(t *testing.T) {
+	a, err := NewAuthenticator(testConfig{}, sessions{})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	token := sign(t, jwt.SigningMethodHS256, "hs", secret, jwt.MapClaims{"role": "admin", "exp": time.Now().Add(time.Hour).Unix()})
+	if _, err := a.Resolve(context.Background(), "", "Bearer "+token); !errors.Is(err, &base.Error{Kind: base.KindUnauthenticated}) {
+		t.Errorf("Resolve() error = %v, want unauthenticated", err)
+	}
+}
+
+func TestNewAuthenticatorErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  testConfig
+	}{
+		{name: "unknown role", cfg: testConfig{keys: map[string]string{"key": "owner"}}},
+		{name: "missing jwks", cfg: testConfig{jwks: filepath.Join(t.TempDir(), "missing.json")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAuthenticator(tt.cfg, sessions{}); err == nil {
+				t.Error("NewAuthenticator() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestAPIKeySubject(t *testing.T) {
+	a, err := NewAuthenticator(testConfig{keys: map[string]string{"one": "reader", "two": "reader"}}, sessions{})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	one, _ := a.Resolve(context.Background(), "one", "")
+	two, _ := a.Resolve(context.Background(), "two", "")
+	if one.Subject == two.Subject || one.Subject == "" {
+		t.Errorf("subjects = %q and %q, want distinct subjects per key", one.Subject, two.Subject)
+	}
+}
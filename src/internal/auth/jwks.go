@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// keySet holds the verification keys of a local JWKS file, indexed by key ID.
+type keySet struct {
+	hmac map[string][]byte
+	rsa  map[string]*rsa.PublicKey
+}
+
+func loadKeySet(path string) (*keySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read JWKS file: %s. Error: %s", path, err.Error())
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("Failed to parse JWKS file: %s. Error: %s", path, err.Error())
+	}
+
+	set := &keySet{
+		hmac: make(map[string][]byte),
+		rsa:  make(map[string]*rsa.PublicKey),
+	}
+	for _, key := range doc.Keys {
+		switch key.Kty {
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid symmetric key %s. Error: %s", key.Kid, err.Error())
+			}
+			set.hmac[key.Kid] = secret
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(key.N)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid RSA modulus of key %s. Error: %s", key.Kid, err.Error())
+			}
+			e, err := base64.RawURLEncoding.DecodeString(key.E)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid RSA exponent of key %s. Error: %s", key.Kid, err.Error())
+			}
+			set.rsa[key.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		default:
+			return nil, fmt.Errorf("Unsupported key type %s of key %s", key.Kty, key.Kid)
+		}
+	}
+
+	return set, nil
+}
+
+// keyFunc selects the key by the kid header. The key type must match the
+// signing method, so an RSA public key can never be used as an HMAC secret.
+func (s *keySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if key, ok := s.hmac[kid]; ok {
+			return key, nil
+		}
+	case *jwt.SigningMethodRSA:
+		if key, ok := s.rsa[kid]; ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No %s key with id %q", token.Method.Alg(), kid)
+}
@@ -0,0 +1,48 @@
+package auth
+
+import "testing"
+
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if hash == "correct horse" {
+		t.Fatal("HashPassword() returned the password")
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: "correct horse", want: true},
+		{password: "Correct horse", want: false},
+		{password: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := CheckPassword(hash, tt.password); got != tt.want {
+			t.Errorf("CheckPassword(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+	if CheckPassword("", "dummy password") {
+		t.Error("CheckPassword() matched an empty hash")
+	}
+}
+
+func TestNewSessionToken(t *testing.T) {
+	token, hash, err := NewSessionToken()
+	if err != nil {
+		t.Fatalf("NewSessionToken() error = %v", err)
+	}
+	if hash != HashToken(token) {
+		t.Errorf("hash = %q, want HashToken(token)", hash)
+	}
+
+	other, _, err := NewSessionToken()
+	if err != nil {
+		t.Fatalf("NewSessionToken() error = %v", err)
+	}
+	if token == other {
+		t.Error("NewSessionToken() returned the same token twice")
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+type Role int
+
+const (
+	Anonymous Role = iota
+	Reader
+	Editor
+	Admin
+)
+
+var roleNames = map[string]Role{
+	"reader": Reader,
+	"editor": Editor,
+	"admin":  Admin,
+}
+
+func ParseRole(name string) (Role, error) {
+	role, ok := roleNames[name]
+	if !ok {
+		return Anonymous, fmt.Errorf("Unknown role: %s", name)
+	}
+
+	return role, nil
+}
+
+func (r Role) String() string {
+	for name, role := range roleNames {
+		if role == r {
+			return name
+		}
+	}
+
+	return "anonymous"
+}
+
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string `json:"subject"`
+	Role    Role   `json:"role" swaggertype:"string"`
+	UserID  uint   `json:"user_id,omitempty"`
+}
+
+// Allows reports whether the principal may use a route that requires role.
+func (p Principal) Allows(role Role) bool {
+	return p.Role >= role
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller of the request, or an anonymous principal.
+func FromContext(ctx context.Context) Principal {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	if !ok {
+		return Principal{Role: Anonymous}
+	}
+
+	return p
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Role
+		wantErr bool
+	}{
+		{name: "reader", want: Reader},
+		{name: "editor", want: Editor},
+		{name: "admin", want: Admin},
+		{name: "Admin", wantErr: true},
+		{name: "anonymous", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRole(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRole() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.name {
+				t.Errorf("String() = %q, want %q", got.String(), tt.name)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{role: Anonymous, required: Anonymous, want: true},
+		{role: Anonymous, required: Reader, want: false},
+		{role: Editor, required: Reader, want: true},
+		{role: Editor, required: Admin, want: false},
+		{role: Admin, required: Editor, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role.String()+"/"+tt.required.String(), func(t *testing.T) {
+			if got := (Principal{Role: tt.role}).Allows(tt.required); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got.Role != Anonymous {
+		t.Errorf("FromContext() of an empty context role = %v, want anonymous", got.Role)
+	}
+
+	p := Principal{Subject: "alice", Role: Editor}
+	if got := FromContext(WithPrincipal(context.Background(), p)); got != p {
+		t.Errorf("FromContext() = %+v, want %+v", got, p)
+	}
+}
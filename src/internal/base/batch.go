@@ -0,0 +1,122 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"music/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+type BatchRepository interface {
+	BatchSongs(ctx context.Context, ops []model.BatchOperation, atomic bool) ([]BatchResult, error)
+}
+
+// BatchResult is the outcome of an operation of a batch: the songs it
+// created, updated or deleted, or why it failed. Operations of a rolled back
+// batch other than the failed one have neither.
+type BatchResult struct {
+	Songs []model.Song
+	Err   error
+}
+
+// BatchSongs applies the operations in order. An atomic batch runs in one
+// transaction and is rolled back by the first operation that fails, otherwise
+// every operation commits or fails on its own. The error is returned for
+// failures of the batch as a whole, an atomic batch also fails as a whole when
+// an operation fails for any other reason than a missing song, a conflict or
+// an invalid value.
+func (r *repository) BatchSongs(ctx context.Context, ops []model.BatchOperation, atomic bool) ([]BatchResult, error) {
+	log.Printf("Trying to apply a batch of %d operations, atomic: %t", len(ops), atomic)
+	results := make([]BatchResult, len(ops))
+	if !atomic {
+		for i, op := range ops {
+			err := r.withContext(ctx, func(db *gorm.DB) error {
+				var err error
+				results[i].Songs, err = applyBatchOperation(db, op)
+				return err
+			})
+			if err != nil {
+				results[i] = BatchResult{Err: fmt.Errorf("Failed to apply operation %d. Error: %w", i, err)}
+			}
+		}
+
+		return results, nil
+	}
+
+	failed := -1
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		for i, op := range ops {
+			songs, err := applyBatchOperation(db, op)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results[i].Songs = songs
+		}
+
+		return nil
+	})
+	if err == nil {
+		log.Printf("Batch of %d operations committed", len(ops))
+		return results, nil
+	}
+
+	var domain *Error
+	if failed < 0 || !errors.As(err, &domain) || !operationKinds[domain.Kind] {
+		return nil, fmt.Errorf("Failed to apply a batch of %d operations. Error: %w", len(ops), err)
+	}
+	results = make([]BatchResult, len(ops))
+	results[failed].Err = fmt.Errorf("Failed to apply operation %d. Error: %w", failed, err)
+	log.Printf("Batch of %d operations rolled back by operation %d", len(ops), failed)
+
+	return results, nil
+}
+
+// operationKinds are the failures an operation is blamed for.
+var operationKinds = map[Kind]bool{
+	KindNotFound:   true,
+	KindConflict:   true,
+	KindValidation: true,
+}
+
+func applyBatchOperation(db *gorm.DB, op model.BatchOperation) ([]model.Song, error) {
+	if op.Op == model.BatchCreate {
+		song, err := addSong(db, op.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		return []model.Song{song}, nil
+	}
+
+	var songs []model.Song
+	if op.ID != 0 {
+		song, err := getSong(db, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		songs = []model.Song{song}
+	} else {
+		// Suggestions cost a trigram scan, a batch of missing songs would
+		// run one per operation.
+		var err error
+		if songs, err = songsNamed(db, op.Group, op.Song); err != nil {
+			return nil, err
+		}
+		if len(songs) == 0 {
+			return nil, nameNotFound(op.Group, op.Song)
+		}
+	}
+
+	switch op.Op {
+	case model.BatchUpdate:
+		return updateSongs(db, songs, op.Changes)
+	case model.BatchDelete:
+		return songs, deleteSongs(db, songs)
+	}
+
+	return nil, Validation("Unsupported operation: %s", op.Op)
+}
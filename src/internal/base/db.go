@@ -1,43 +1,58 @@
 package base
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"music/internal/config"
 	"music/internal/model"
-	"strings"
+	"slices"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
 	_ "github.com/lib/pq"
-	"github.com/pressly/goose/v3"
 )
 
 type Repository interface {
-	AddSong(newSong model.Song) error
-	Find(group, song string) (bool, error)
-	GetLibrary() ([]model.Song, error)
-	GetLyrics(group, song string) (string, error)
-	FindWithFilter(filter string) (model.Song, error)
-	GetLyricsWithPagination(group, song string, page, size int) ([]string, error)
-	GetLibraryWithPagination(page, size int) ([]model.Song, error)
-	FindWithFilterAndPagination(filter string, page, size int) ([]model.Song, error)
-	DeleteSong(group, song string) error
-	UpdateSong(group, song string, updateSong model.Song) error
+	UserRepository
+	PlaylistRepository
+	TagRepository
+	LyricsRepository
+	VariantRepository
+	MergeRepository
+	WebhookRepository
+	OutboxRepository
+	BatchRepository
+	IdempotencyRepository
+
+	AddSong(ctx context.Context, newSong model.Song) (model.Song, error)
+	Find(ctx context.Context, group, song string) (bool, error)
+	GetLibrary(ctx context.Context) ([]model.Song, error)
+	GetSong(ctx context.Context, songID uint) (model.Song, error)
+	GetArtistSongs(ctx context.Context, group string) ([]model.Song, error)
+	GetSongsOfArtists(ctx context.Context, groups []string) (map[string][]model.Song, error)
+	GetArtists(ctx context.Context, page, size int) ([]model.Artist, error)
+	Suggest(ctx context.Context, query string, limit int) ([]model.Suggestion, error)
+	FindWithFilter(ctx context.Context, filter string) (model.Song, error)
+	GetLyricsWithPagination(ctx context.Context, group, song string, page, size int) ([]string, error)
+	GetLibraryWithPagination(ctx context.Context, page, size int) ([]model.Song, error)
+	FindWithFilterAndPagination(ctx context.Context, filter string, page, size int) ([]model.Song, error)
+	DeleteSong(ctx context.Context, group, song string) error
+	UpdateSong(ctx context.Context, group, song string, update model.SongUpdate) error
+	ImportSongs(ctx context.Context, songs []model.Song) (model.ImportResult, error)
+	Purge(ctx context.Context) (int64, error)
 	Close() error
 }
 
-type repository struct {
-	base *gorm.DB
-}
+// bulkChunk is how many songs an import or a purge changes per transaction,
+// each transaction has its own query timeout.
+const bulkChunk = 500
 
-func applyMigrations(db *sql.DB) error {
-	goose.SetDialect("postgres")
-	if err := goose.Up(db, "internal/base/migrations"); err != nil {
-		return err
-	}
-	return nil
+type repository struct {
+	base    *gorm.DB
+	timeout time.Duration
 }
 
 func NewRepository(cfg config.Config) (Repository, error) {
@@ -55,91 +70,130 @@ func NewRepository(cfg config.Config) (Repository, error) {
 	log.Println("Connecting to database: success")
 
 	log.Print("Running migrations...")
-	if err := applyMigrations(sqlDB); err != nil {
+	if err := applyMigrations(sqlDB, cfg.GetAutoMigrate()); err != nil {
 		return nil, fmt.Errorf("Failed to make migrations. Error: %s", err.Error())
 	}
 	log.Println("Database migrations completed")
 
 	return &repository{
-		base: b,
+		base:    b,
+		timeout: cfg.GetQueryTimeout(),
 	}, nil
 }
 
-func encode(filter string) string {
-	query := strings.Split(filter, "&")
+// withContext runs fn in a transaction bound to ctx and limited by the query
+// timeout, so a disconnected client or a slow query is cancelled in the database.
+func (r *repository) withContext(ctx context.Context, fn func(db *gorm.DB) error) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
 
-	all := ""
-	for _, val := range query {
-		if all != "" {
-			all += " and "
-		}
+	tx := r.base.BeginTx(ctx, nil)
+	if tx.Error != nil {
+		return classify(contextError(ctx, tx.Error))
+	}
 
-		twice := strings.Split(val, "=")
-		if twice[0] == "group" {
-			twice[0] = "group_name"
-		}
-		twice[1] = strings.Replace(twice[1],"+", " ", -1)
-		temple := twice[0] + " = " + "'" + twice[1] + "'"
-		all += temple
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return classify(contextError(ctx, err))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return classify(contextError(ctx, err))
+	}
+
+	return nil
+}
+
+// contextError prefers the context error, because drivers report a cancelled
+// statement with their own messages.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
 	}
 
-	return all
+	return err
 }
 
-func (r *repository) Find(group, song string) (bool, error) {
+func (r *repository) Find(ctx context.Context, group, song string) (bool, error) {
 	var target model.Song
 	status := true
-	if err := r.base.Where("group_name = ? and song = ? ", group, song).First(&target).Error; err != nil {
-		if err.Error() == gorm.ErrRecordNotFound.Error() {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where(sameSong, group, song).First(&target).Error
+	})
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
 			status = false
 		} else {
-			return false, fmt.Errorf("Error executing search request: %s", err.Error())
+			return false, fmt.Errorf("Error executing search request: %w", err)
 		}
 	}
 
 	return status, nil
 }
 
-func (r *repository) FindWithFilter(filter string) (model.Song, error) {
+func (r *repository) FindWithFilter(ctx context.Context, filter string) (model.Song, error) {
 	log.Printf("Trying to find with filter: %s", filter)
-	query := encode(filter)
 	var target model.Song
-	if err := r.base.Where(query).First(&target).Error; err != nil {
-		return model.Song{}, fmt.Errorf("Failed to find with filter: %s. Error: %s", filter, err.Error())
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		query, err := filtered(db, filter)
+		if err != nil {
+			return err
+		}
+
+		return query.First(&target).Error
+	})
+	if gorm.IsRecordNotFoundError(err) {
+		return model.Song{}, NotFound("No song matches filter: %s", filter)
+	}
+	if err != nil {
+		return model.Song{}, fmt.Errorf("Failed to find with filter: %s. Error: %w", filter, err)
 	}
 
 	return target, nil
 }
 
-func (r *repository) GetLibraryWithPagination(page, size int) ([]model.Song, error) {
-	log.Printf("Trying to get library withpage: %d, size: %s", page, size)
+func (r *repository) GetLibraryWithPagination(ctx context.Context, page, size int) ([]model.Song, error) {
+	log.Printf("Trying to get library with page: %d, size: %d", page, size)
 	offset := (page - 1) * size
 	var songs []model.Song
-	if err := r.base.Offset(offset).Limit(size).Find(&songs).Error; err != nil {
-		return nil, fmt.Errorf("Failed to get library with page: %d, size: %s", page, size)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Order("id").Offset(offset).Limit(size).Find(&songs).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get library with page: %d, size: %d. Error: %w", page, size, err)
 	}
 
 	return songs, nil
 }
 
-func (r *repository) FindWithFilterAndPagination(filter string, page, size int) ([]model.Song, error) {
-	log.Printf("Trying to find with filter: %s, page: %d, size: %s", filter, page, size)
+func (r *repository) FindWithFilterAndPagination(ctx context.Context, filter string, page, size int) ([]model.Song, error) {
+	log.Printf("Trying to find with filter: %s, page: %d, size: %d", filter, page, size)
 	offset := (page - 1) * size
 	var songs []model.Song
-	query := encode(filter)
-	if err := r.base.Where(query).Offset(offset).Limit(size).Find(&songs).Error; err != nil {
-		return nil, fmt.Errorf("Failed to find with filter: %s, page: %d, size: %s", filter, page, size)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		query, err := filtered(db, filter)
+		if err != nil {
+			return err
+		}
+
+		return query.Order("songs.id").Offset(offset).Limit(size).Find(&songs).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find with filter: %s, page: %d, size: %d. Error: %w", filter, page, size, err)
 	}
 
 	return songs, nil
 }
 
-func (r *repository) GetLyricsWithPagination(group, song string, page, size int) ([]string, error) {
+func (r *repository) GetLyricsWithPagination(ctx context.Context, group, song string, page, size int) ([]string, error) {
 	log.Printf("Trying to get lyrics of group: %s, song: %s, with page: %d, size: %d", group, song, page, size)
 	offset := (page - 1) * size
 	var songs []model.Song
-	if err := r.base.Where("group_name = ? AND song = ?", group, song).Offset(offset).Limit(size).Find(&songs).Error; err != nil {
-		return nil, fmt.Errorf("Failed to get lyrics of group: %s, song: %s, with page: %d, size: %d", group, song)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where(sameSong, group, song).Offset(offset).Limit(size).Find(&songs).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get lyrics of group: %s, song: %s, with page: %d, size: %d. Error: %w", group, song, page, size, err)
 	}
 
 	lyrics := make([]string, 0)
@@ -150,56 +204,343 @@ func (r *repository) GetLyricsWithPagination(group, song string, page, size int)
 	return lyrics, nil
 }
 
-func (r *repository) AddSong(newSong model.Song) error {
+func (r *repository) AddSong(ctx context.Context, newSong model.Song) (model.Song, error) {
 	log.Printf("Trying to add group: %s, song: %s", newSong.Group_name, newSong.Song)
-	r.base.Create(&newSong)
-	if r.base.Error != nil {
-		return fmt.Errorf("Failed to add group: %s, song: %s. Error:%s", newSong.Group_name, newSong.Song, r.base.Error.Error())
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var err error
+		newSong, err = addSong(db, newSong)
+		return err
+	})
+	if err != nil {
+		return model.Song{}, fmt.Errorf("Failed to add group: %s, song: %s. Error: %w", newSong.Group_name, newSong.Song, err)
 	}
 
 	log.Printf("Group: %s, song: %s added with ID:%d", newSong.Group_name, newSong.Song, newSong.ID)
-	return nil
+	return newSong, nil
 }
 
-func (r *repository) GetLibrary() ([]model.Song, error) {
+func (r *repository) GetLibrary(ctx context.Context) ([]model.Song, error) {
 	log.Print("Trying to fetching library data...")
 	data := make([]model.Song, 0)
 
-	if err := r.base.Find(&data).Error; err != nil {
-		return nil, fmt.Errorf("Failed to fetch library data. Error: %s ", err.Error())
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Find(&data).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch library data. Error: %w", err)
 	}
 
 	return data, nil
 }
 
-func (r *repository) GetLyrics(group, song string) (string, error) {
-	log.Printf("Trying to get lyrics of group: %s, song: %s", group, song)
-	var target model.Song
-	if err := r.base.Where("group_name = ? and song = ? ", group, song).First(&target).Error; err != nil {
-		return "", fmt.Errorf("Failed to get lyrics of group: %s, song: %s. Error: %s", group, song, err.Error())
+func (r *repository) GetSong(ctx context.Context, songID uint) (model.Song, error) {
+	var song model.Song
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var err error
+		song, err = getSong(db, songID)
+		return err
+	})
+	if err != nil {
+		return model.Song{}, fmt.Errorf("Failed to get song: %d. Error: %w", songID, err)
+	}
+
+	return song, nil
+}
+
+func (r *repository) GetArtistSongs(ctx context.Context, group string) ([]model.Song, error) {
+	log.Printf("Trying to get songs of group: %s", group)
+	songs := make([]model.Song, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := db.Where("music_fold(group_name) = music_fold(?)", group).Order("id").Find(&songs).Error; err != nil {
+			return err
+		}
+		if len(songs) == 0 {
+			return NotFound("Group not found: %s", group)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get songs of group: %s. Error: %w", group, err)
+	}
+
+	return songs, nil
+}
+
+// GetSongsOfArtists loads the songs of several groups at once, keyed by the
+// group name as stored.
+func (r *repository) GetSongsOfArtists(ctx context.Context, groups []string) (map[string][]model.Song, error) {
+	var songs []model.Song
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where("group_name in (?)", groups).Order("album, release_date, id").Find(&songs).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get songs of %d groups. Error: %w", len(groups), err)
 	}
 
-	return target.Lyrics, nil
+	result := make(map[string][]model.Song, len(groups))
+	for _, song := range songs {
+		result[song.Group_name] = append(result[song.Group_name], song)
+	}
+
+	return result, nil
+}
+
+func (r *repository) GetArtists(ctx context.Context, page, size int) ([]model.Artist, error) {
+	log.Printf("Trying to get artists with page: %d, size: %d", page, size)
+	artists := make([]model.Artist, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		query := db.Table("songs").
+			Select("group_name as name, count(*) as songs, count(distinct nullif(album, '')) as albums").
+			Group("group_name").
+			Order("group_name")
+
+		return paginated(query, page, size).Scan(&artists).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get artists with page: %d, size: %d. Error: %w", page, size, err)
+	}
+
+	return artists, nil
 }
 
-func (r *repository) DeleteSong(group, song string) error {
+func (r *repository) DeleteSong(ctx context.Context, group, song string) error {
 	log.Printf("Trying to delete group: %s, song: %s", group, song)
-	if err := r.base.Where("group_name = ? and song = ?", group, song).Delete(&model.Song{}).Error; err != nil {
-		return fmt.Errorf("Failed to delete group: %s, song: %s. Error: %s ", group, song, err.Error())
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		deleted, err := findSongs(db, group, song)
+		if err != nil {
+			return err
+		}
+
+		return deleteSongs(db, deleted)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete group: %s, song: %s. Error: %w", group, song, err)
 	}
 
 	return nil
 }
 
-func (r *repository) UpdateSong(group, song string, updateSong model.Song) error {
+func (r *repository) UpdateSong(ctx context.Context, group, song string, update model.SongUpdate) error {
 	log.Printf("Trying to update group: %s, song: %s", group, song)
-	if err := r.base.Model(&model.Song{}).Where("group_name = ? and song = ?", group, song).Update(&updateSong).Error; err != nil {
-		return fmt.Errorf("Failed to update group: %s, song: %s. Error: %s", group, song, err.Error())
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		songs, err := findSongs(db, group, song)
+		if err != nil {
+			return err
+		}
+
+		_, err = updateSongs(db, songs, update)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to update group: %s, song: %s. Error: %w", group, song, err)
+	}
+
+	return nil
+}
+
+// ImportSongs adds the songs in transactions of bulkChunk songs, so a large
+// import is not bounded by a single query timeout. Chunks committed before a
+// failure stay imported, importing the same list again skips them.
+func (r *repository) ImportSongs(ctx context.Context, songs []model.Song) (model.ImportResult, error) {
+	log.Printf("Trying to import %d songs", len(songs))
+	var result model.ImportResult
+	for chunk := range slices.Chunk(songs, bulkChunk) {
+		var imported model.ImportResult
+		err := r.withContext(ctx, func(db *gorm.DB) error {
+			imported = model.ImportResult{}
+			return importSongs(db, chunk, &imported)
+		})
+		if err != nil {
+			return result, fmt.Errorf("Failed to import songs after %d imported. Error: %w", result.Imported, err)
+		}
+		result.Imported += imported.Imported
+		result.Skipped += imported.Skipped
+	}
+
+	log.Printf("Imported %d songs, skipped %d", result.Imported, result.Skipped)
+	return result, nil
+}
+
+// importSongs adds the songs in the transaction and counts them in result.
+func importSongs(db *gorm.DB, songs []model.Song, result *model.ImportResult) error {
+	for _, song := range songs {
+		// A song already in the library violates the unique index, the
+		// savepoint keeps the transaction usable to skip it.
+		if err := db.Exec("savepoint import_song").Error; err != nil {
+			return err
+		}
+		if _, err := addSong(db, song); err != nil {
+			if !errors.Is(err, ErrConflict) {
+				return err
+			}
+			if err := db.Exec("rollback to savepoint import_song").Error; err != nil {
+				return err
+			}
+			result.Skipped++
+			continue
+		}
+		if err := db.Exec("release savepoint import_song").Error; err != nil {
+			return err
+		}
+		result.Imported++
 	}
 
 	return nil
 }
 
+// Purge deletes the songs in transactions of bulkChunk songs, oldest first,
+// until none is left. Chunks committed before a failure stay deleted.
+func (r *repository) Purge(ctx context.Context) (int64, error) {
+	log.Print("Trying to purge library...")
+	var deleted int64
+	for {
+		var songs []model.Song
+		err := r.withContext(ctx, func(db *gorm.DB) error {
+			songs = nil
+			if err := db.Order("id").Limit(bulkChunk).Find(&songs).Error; err != nil {
+				return err
+			}
+			if len(songs) == 0 {
+				return nil
+			}
+
+			return deleteSongs(db, songs)
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("Failed to purge library after %d deleted. Error: %w", deleted, err)
+		}
+		if len(songs) == 0 {
+			break
+		}
+		deleted += int64(len(songs))
+	}
+
+	log.Printf("Purged %d songs", deleted)
+	return deleted, nil
+}
+
+// addSong creates the song and records its event. A song with the same
+// folded name is a conflict. The unique name index enforces it once
+// migration 012 is applied, until then the name is locked for the rest of
+// the transaction and looked up, so no write adds a duplicate while the
+// existing ones are merged.
+func addSong(db *gorm.DB, song model.Song) (model.Song, error) {
+	err := db.Exec("select pg_advisory_xact_lock(hashtext(music_fold(?) || chr(31) || music_fold(?)))", song.Group_name, song.Song).Error
+	if err != nil {
+		return model.Song{}, err
+	}
+	existing, err := songsNamed(db, song.Group_name, song.Song)
+	if err != nil {
+		return model.Song{}, err
+	}
+	if len(existing) > 0 {
+		return model.Song{}, Conflict("Group: %s, song: %s is already in the library", song.Group_name, song.Song)
+	}
+
+	if err := db.Create(&song).Error; err != nil {
+		if violates(err, songNameIndex) {
+			return model.Song{}, Conflict("Group: %s, song: %s is already in the library", song.Group_name, song.Song)
+		}
+		return model.Song{}, err
+	}
+
+	return song, recordEvent(db, model.SongCreated, song)
+}
+
+// findSongs returns the songs with the name, ordered by id. When there are
+// none the not found error suggests similar songs.
+func findSongs(db *gorm.DB, group, song string) ([]model.Song, error) {
+	songs, err := songsNamed(db, group, song)
+	if err == nil && len(songs) == 0 {
+		return nil, songNotFound(db, group, song)
+	}
+
+	return songs, err
+}
+
+// songsNamed returns the songs with the name, ordered by id, none is not an
+// error.
+func songsNamed(db *gorm.DB, group, song string) ([]model.Song, error) {
+	var songs []model.Song
+	err := db.Where(sameSong, group, song).Order("id").Find(&songs).Error
+
+	return songs, err
+}
+
+func songIDs(songs []model.Song) []uint {
+	ids := make([]uint, 0, len(songs))
+	for _, song := range songs {
+		ids = append(ids, song.ID)
+	}
+
+	return ids
+}
+
+// updateSongs sets the non-nil fields of update on the songs, an empty album
+// or text clears it. It records their events and returns them as updated.
+func updateSongs(db *gorm.DB, songs []model.Song, update model.SongUpdate) ([]model.Song, error) {
+	ids := songIDs(songs)
+	if err := db.Model(&model.Song{}).Where("id in (?)", ids).Updates(songColumns(update)).Error; err != nil {
+		return nil, err
+	}
+	if update.Lyrics != nil {
+		// The original variant mirrors the song lyrics.
+		err := db.Model(&model.LyricsVariant{}).Where("song_id in (?) and original", ids).Update("text", *update.Lyrics).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var updated []model.Song
+	if err := db.Where("id in (?)", ids).Order("id").Find(&updated).Error; err != nil {
+		return nil, err
+	}
+
+	return updated, recordEvents(db, model.SongUpdated, updated)
+}
+
+// songColumns maps the changed fields to their columns, so an empty string
+// clears the column rather than being skipped as a zero value.
+func songColumns(update model.SongUpdate) map[string]any {
+	columns := make(map[string]any)
+	fields := []struct {
+		column string
+		value  *string
+	}{
+		{"group_name", update.Group_name},
+		{"song", update.Song},
+		{"album", update.Album},
+		{"release_date", update.ReleaseDate},
+		{"lyrics", update.Lyrics},
+	}
+	for _, field := range fields {
+		if field.value != nil {
+			columns[field.column] = *field.value
+		}
+	}
+
+	return columns
+}
+
+// deleteSongs deletes the songs, closes the gaps they leave in playlists and
+// records their events.
+func deleteSongs(db *gorm.DB, songs []model.Song) error {
+	ids := songIDs(songs)
+	playlists, err := lockPlaylistsOfSongs(db, ids)
+	if err != nil {
+		return err
+	}
+	if err := db.Where("id in (?)", ids).Delete(&model.Song{}).Error; err != nil {
+		return err
+	}
+	if err := compactPositions(db, playlists); err != nil {
+		return err
+	}
+
+	return recordEvents(db, model.SongDeleted, songs)
+}
+
 func (r *repository) Close() error {
 	if err := r.base.Close(); err != nil {
 		return fmt.Errorf("Failed to close database. Error: %s", err.Error())
@@ -0,0 +1,72 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// queries records the SQL gorm sends, the queries themselves fail because
+// nothing listens on the address.
+type queries []string
+
+func (q *queries) Print(values ...any) {
+	if len(values) > 4 && values[0] == "sql" {
+		*q = append(*q, fmt.Sprint(values[3], " ", values[4]))
+	}
+}
+
+func offlineDB(t *testing.T) (*gorm.DB, *queries) {
+	t.Helper()
+	sqlDB, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, _ := gorm.Open("postgres", sqlDB)
+	logged := &queries{}
+	db.SetLogger(logged)
+
+	return db.LogMode(true), logged
+}
+
+func TestWithContext(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		timeout time.Duration
+		want    error
+	}{
+		{name: "database down", ctx: context.Background(), timeout: time.Minute, want: ErrUnavailable},
+		{name: "expired request", ctx: expired, timeout: time.Minute, want: ErrTimeout},
+		{name: "query timeout", ctx: context.Background(), timeout: time.Nanosecond, want: ErrTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := offlineDB(t)
+			r := &repository{base: db, timeout: tt.timeout}
+
+			called := false
+			err := r.withContext(tt.ctx, func(db *gorm.DB) error {
+				called = true
+				return nil
+			})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("withContext() error = %v, want %v", err, tt.want)
+			}
+			if called {
+				t.Error("withContext() ran the function without a transaction")
+			}
+		})
+	}
+}
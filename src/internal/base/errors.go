@@ -0,0 +1,172 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"music/internal/model"
+	"net"
+
+	"github.com/lib/pq"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnavailable
+	KindTimeout
+	KindUnauthenticated
+	KindForbidden
+	KindRateLimited
+	KindNotAcceptable
+	KindFailedDependency
+	KindUnprocessable
+)
+
+// Error is a domain error returned by the repository. Message is safe to show
+// to clients, Err keeps the underlying cause for the logs.
+type Error struct {
+	Kind       Kind
+	Message    string
+	Violations []Violation
+	// Suggestions lists similar songs for a song that was not found.
+	Suggestions []model.Suggestion
+	Err         error
+}
+
+// Violation describes a single invalid field of a validation error.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+var (
+	ErrNotFound    = &Error{Kind: KindNotFound, Message: "not found"}
+	ErrConflict    = &Error{Kind: KindConflict, Message: "conflict"}
+	ErrValidation  = &Error{Kind: KindValidation, Message: "validation failed"}
+	ErrUnavailable = &Error{Kind: KindUnavailable, Message: "database is unavailable"}
+	ErrTimeout     = &Error{Kind: KindTimeout, Message: "database query timed out"}
+)
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Err.Error())
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind, so errors.Is(err, ErrNotFound) works for
+// any not found error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind
+}
+
+func NotFound(format string, args ...any) error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...any) error {
+	return &Error{Kind: KindConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(format string, args ...any) error {
+	return &Error{Kind: KindValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid reports all violations of a request at once.
+func Invalid(violations []Violation) error {
+	return &Error{Kind: KindValidation, Message: "Request validation failed", Violations: violations}
+}
+
+func Unauthenticated(format string, args ...any) error {
+	return &Error{Kind: KindUnauthenticated, Message: fmt.Sprintf(format, args...)}
+}
+
+func Forbidden(format string, args ...any) error {
+	return &Error{Kind: KindForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func RateLimited(format string, args ...any) error {
+	return &Error{Kind: KindRateLimited, Message: fmt.Sprintf(format, args...)}
+}
+
+// NotAcceptable reports that no representation matches the Accept header.
+func NotAcceptable(format string, args ...any) error {
+	return &Error{Kind: KindNotAcceptable, Message: fmt.Sprintf(format, args...)}
+}
+
+// FailedDependency reports an operation that was not applied because another
+// one failed.
+func FailedDependency(format string, args ...any) error {
+	return &Error{Kind: KindFailedDependency, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unprocessable reports a well-formed request that cannot be processed.
+func Unprocessable(format string, args ...any) error {
+	return &Error{Kind: KindUnprocessable, Message: fmt.Sprintf(format, args...)}
+}
+
+func Unavailable(err error) error {
+	return &Error{Kind: KindUnavailable, Message: ErrUnavailable.Message, Err: err}
+}
+
+// songNameIndex is the unique index of the folded song names.
+const songNameIndex = "songs_name_unique"
+
+// violates reports whether err is a unique violation of the index.
+func violates(err error, index string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == index
+}
+
+// classify converts context, driver and postgres errors into domain errors.
+// Errors it does not recognise are returned unchanged and treated as internal.
+func classify(err error) error {
+	var domain *Error
+	if errors.As(err, &domain) {
+		return err
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: KindTimeout, Message: ErrTimeout.Message, Err: err}
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return Unavailable(err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case violates(err, songNameIndex):
+			return &Error{Kind: KindConflict, Message: "song is already in the library", Err: err}
+		case pqErr.Code == "23505":
+			return &Error{Kind: KindConflict, Message: "record already exists", Err: err}
+		case pqErr.Code == "42703":
+			return &Error{Kind: KindValidation, Message: "unknown field", Err: err}
+		case pqErr.Code.Class() == "22":
+			return &Error{Kind: KindValidation, Message: "invalid value", Err: err}
+		case pqErr.Code.Class() == "08", pqErr.Code.Class() == "53", pqErr.Code.Class() == "57":
+			return Unavailable(err)
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return Unavailable(err)
+	}
+
+	return err
+}
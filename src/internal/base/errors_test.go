@@ -0,0 +1,46 @@
+package base
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantKind    Kind
+		wantMessage string
+	}{
+		{name: "song name taken", err: &pq.Error{Code: "23505", Constraint: songNameIndex}, wantKind: KindConflict, wantMessage: "song is already in the library"},
+		{name: "other unique violation", err: &pq.Error{Code: "23505", Constraint: "users_login_key"}, wantKind: KindConflict, wantMessage: "record already exists"},
+		{name: "wrapped", err: fmt.Errorf("insert: %w", &pq.Error{Code: "23505", Constraint: songNameIndex}), wantKind: KindConflict, wantMessage: "song is already in the library"},
+		{name: "unknown column", err: &pq.Error{Code: "42703"}, wantKind: KindValidation, wantMessage: "unknown field"},
+		{name: "invalid value", err: &pq.Error{Code: "22P02"}, wantKind: KindValidation, wantMessage: "invalid value"},
+		{name: "connection", err: &pq.Error{Code: "08006"}, wantKind: KindUnavailable, wantMessage: ErrUnavailable.Message},
+		{name: "bad connection", err: driver.ErrBadConn, wantKind: KindUnavailable, wantMessage: ErrUnavailable.Message},
+		{name: "deadline", err: context.DeadlineExceeded, wantKind: KindTimeout, wantMessage: ErrTimeout.Message},
+		{name: "domain", err: Conflict("Group: Muse, song: Hysteria is already in the library"), wantKind: KindConflict, wantMessage: "Group: Muse, song: Hysteria is already in the library"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var domain *Error
+			if !errors.As(classify(tt.err), &domain) {
+				t.Fatalf("classify(%v) is not a domain error", tt.err)
+			}
+			if domain.Kind != tt.wantKind || domain.Message != tt.wantMessage {
+				t.Errorf("classify(%v) = %d %q, want %d %q", tt.err, domain.Kind, domain.Message, tt.wantKind, tt.wantMessage)
+			}
+		})
+	}
+
+	if err := errors.New("boom"); classify(err) != err {
+		t.Error("classify() changed an unknown error")
+	}
+}
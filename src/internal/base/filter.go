@@ -0,0 +1,92 @@
+package base
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// filterColumns maps the query keys of /music/filter to song columns.
+var filterColumns = map[string]string{
+	"group":        "songs.group_name",
+	"song":         "songs.song",
+	"album":        "songs.album",
+	"release_date": "songs.release_date",
+}
+
+// filtered applies a query string filter such as "group=Muse&tag=rock&tag=80s".
+// Repeated column keys match any of the values. Tags match all of the values,
+// or any of them with tag_mode=any. sort orders by a filter column, descending
+// with a leading "-". An empty filter matches everything.
+func filtered(db *gorm.DB, filter string) (*gorm.DB, error) {
+	values, err := url.ParseQuery(filter)
+	if err != nil {
+		return nil, Validation("Invalid filter: %s", filter)
+	}
+
+	mode := values.Get("tag_mode")
+	delete(values, "tag_mode")
+	if mode != "" && mode != "all" && mode != "any" {
+		return nil, Validation("Invalid tag_mode: %s, expected all or any", mode)
+	}
+
+	order := values.Get("sort")
+	delete(values, "sort")
+	if order != "" {
+		column, ok := filterColumns[strings.TrimPrefix(order, "-")]
+		if !ok {
+			return nil, Validation("Unknown sort field: %s", order)
+		}
+		if strings.HasPrefix(order, "-") {
+			column += " desc"
+		}
+		db = db.Order(column).Order("songs.id")
+	}
+
+	for key, vals := range values {
+		if key == "tag" {
+			db = tagged(db, vals, mode == "any")
+			continue
+		}
+
+		column, ok := filterColumns[key]
+		if !ok {
+			return nil, Validation("Unknown filter field: %s", key)
+		}
+		db = db.Where(column+" in (?)", vals)
+	}
+
+	return db, nil
+}
+
+// sorted reports whether the filter sets the order of the songs.
+func sorted(filter string) bool {
+	values, err := url.ParseQuery(filter)
+	return err == nil && values.Get("sort") != ""
+}
+
+// tagged restricts songs to the ones with all of the tags, or any of them.
+func tagged(db *gorm.DB, names []string, matchAny bool) *gorm.DB {
+	for i := range names {
+		names[i] = strings.ToLower(strings.TrimSpace(names[i]))
+	}
+
+	if matchAny {
+		return db.Where(`songs.id in (select st.song_id from song_tags st
+			join tags t on t.id = st.tag_id where t.name in (?))`, names)
+	}
+
+	return db.Where(`songs.id in (select st.song_id from song_tags st
+		join tags t on t.id = st.tag_id where t.name in (?)
+		group by st.song_id having count(distinct t.name) = ?)`, names, len(unique(names)))
+}
+
+func unique(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,81 @@
+package base
+
+import (
+	"errors"
+	"music/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestFiltered(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{name: "empty", filter: "", want: []string{`SELECT * FROM "songs"`}},
+		{
+			name:   "any of the values",
+			filter: "group=Muse&group=Queen",
+			want:   []string{`(songs.group_name in ($1,$2))`, `[Muse Queen]`},
+		},
+		{
+			name:   "all tags",
+			filter: "tag=Rock&tag=rock&tag=80s",
+			want:   []string{`having count(distinct t.name) = $4`, `[rock rock 80s 2]`},
+		},
+		{
+			name:   "any tag",
+			filter: "tag=Rock&tag_mode=any",
+			want:   []string{`where t.name in ($1))`, `[rock]`},
+		},
+		{
+			name:   "sort descending",
+			filter: "sort=-release_date",
+			want:   []string{`ORDER BY songs.release_date desc,"songs"."id"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, logged := offlineDB(t)
+			filteredDB, err := filtered(db, tt.filter)
+			if err != nil {
+				t.Fatalf("filtered() error = %v", err)
+			}
+			filteredDB.Find(&[]model.Song{})
+
+			if len(*logged) != 1 {
+				t.Fatalf("queries = %q, want one query", *logged)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains((*logged)[0], want) {
+					t.Errorf("query = %q, want it to contain %q", (*logged)[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilteredInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{name: "malformed", filter: "group=%zz", want: "Invalid filter: group=%zz"},
+		{name: "tag mode", filter: "tag=rock&tag_mode=some", want: "Invalid tag_mode: some, expected all or any"},
+		{name: "sort", filter: "sort=-lyrics", want: "Unknown sort field: -lyrics"},
+		{name: "field", filter: "lyrics=love", want: "Unknown filter field: lyrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := offlineDB(t)
+			_, err := filtered(db, tt.filter)
+			if !errors.Is(err, ErrValidation) || err.Error() != tt.want {
+				t.Errorf("filtered() error = %v, want validation error %q", err, tt.want)
+			}
+		})
+	}
+}
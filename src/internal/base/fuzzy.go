@@ -0,0 +1,77 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music/internal/model"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// sameSong matches a song by group and name ignoring case and diacritics, so
+// "the beatles" finds "The Beatles" and "Motorhead" finds "Motörhead".
+const sameSong = "music_fold(group_name) = music_fold(?) and music_fold(song) = music_fold(?)"
+
+const suggestionsInNotFound = 5
+
+// suggestions finds songs whose group, name or both look like the query.
+// Prefixes match too, so it serves autocomplete as well as typos.
+func suggestions(db *gorm.DB, query string, limit int) ([]model.Suggestion, error) {
+	prefix := escapeLike(query) + "%"
+	result := make([]model.Suggestion, 0)
+	err := db.Raw(`select id, group_name as "group", song, greatest(
+			similarity(music_fold(group_name), music_fold(?)),
+			similarity(music_fold(song), music_fold(?)),
+			similarity(music_fold(group_name || ' ' || song), music_fold(?))
+		) as score
+		from songs
+		where music_fold(group_name) % music_fold(?)
+			or music_fold(song) % music_fold(?)
+			or music_fold(group_name || ' ' || song) % music_fold(?)
+			or music_fold(group_name) like music_fold(?)
+			or music_fold(song) like music_fold(?)
+		order by score desc, group_name, song
+		limit ?`,
+		query, query, query, query, query, query, prefix, prefix, limit).Scan(&result).Error
+
+	return result, err
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// songNotFound reports a missing song with the songs the client probably
+// meant.
+func songNotFound(db *gorm.DB, group, song string) error {
+	err := nameNotFound(group, song)
+	similar, suggestErr := suggestions(db, group+" "+song, suggestionsInNotFound)
+	if suggestErr != nil {
+		log.Printf("Failed to suggest songs for group: %s, song: %s. Error: %s", group, song, suggestErr.Error())
+		return err
+	}
+
+	err.Suggestions = similar
+	return err
+}
+
+// nameNotFound reports a missing song without suggestions.
+func nameNotFound(group, song string) *Error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf("Song not found: group %s, song %s", group, song)}
+}
+
+func (r *repository) Suggest(ctx context.Context, query string, limit int) ([]model.Suggestion, error) {
+	var result []model.Suggestion
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var err error
+		result, err = suggestions(db, query, limit)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to suggest songs for: %s. Error: %w", query, err)
+	}
+
+	return result, nil
+}
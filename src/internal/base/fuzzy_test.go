@@ -0,0 +1,52 @@
+package base
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Muse", want: "Muse"},
+		{value: "100%", want: `100\%`},
+		{value: "snake_case", want: `snake\_case`},
+		{value: `AC\DC`, want: `AC\\DC`},
+		{value: `\%_`, want: `\\\%\_`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLike(tt.value); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSongNotFound(t *testing.T) {
+	db, logged := offlineDB(t)
+	err := songNotFound(db, "Muse", "Histeria_")
+
+	if !errors.Is(err, ErrNotFound) || err.Error() != "Song not found: group Muse, song Histeria_" {
+		t.Fatalf("songNotFound() = %v, want a not found error", err)
+	}
+	if len(*logged) != 1 || !strings.Contains((*logged)[0], `[Muse Histeria_ Muse Histeria_ Muse Histeria_ Muse Histeria_ Muse Histeria_ Muse Histeria_ Muse Histeria\_% Muse Histeria\_% 5]`) {
+		t.Errorf("queries = %q, want one suggestion query with an escaped prefix", *logged)
+	}
+
+	// A failed suggestion query still reports the missing song.
+	var domain *Error
+	if errors.As(err, &domain) && len(domain.Suggestions) != 0 {
+		t.Errorf("suggestions = %v, want none", domain.Suggestions)
+	}
+}
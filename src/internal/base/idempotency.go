@@ -0,0 +1,90 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+type IdempotencyRepository interface {
+	ReserveIdempotencyKey(ctx context.Context, key model.IdempotencyKey) (model.IdempotencyKey, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, key model.IdempotencyKey) error
+	ReleaseIdempotencyKey(ctx context.Context, scope, key string) error
+	PurgeIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// ReserveIdempotencyKey stores the key unless the scope already has it. It
+// returns the stored key and whether it was reserved by this call. An expired
+// key is dropped first, so it can be reused.
+func (r *repository) ReserveIdempotencyKey(ctx context.Context, key model.IdempotencyKey) (model.IdempotencyKey, bool, error) {
+	log.Printf("Trying to reserve idempotency key: %s of: %s", key.Key, key.Scope)
+	stored := key
+	reserved := false
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := db.Where("scope = ? and key = ? and expires_at < now()", key.Scope, key.Key).Delete(&model.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+
+		result := db.Exec(`insert into idempotency_keys (scope, key, request_hash, expires_at)
+			values (?, ?, ?, ?) on conflict do nothing`, key.Scope, key.Key, key.RequestHash, key.ExpiresAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			reserved = true
+			return nil
+		}
+
+		return db.Where("scope = ? and key = ?", key.Scope, key.Key).First(&stored).Error
+	})
+	if err != nil {
+		return model.IdempotencyKey{}, false, fmt.Errorf("Failed to reserve idempotency key: %s of: %s. Error: %w", key.Key, key.Scope, err)
+	}
+
+	return stored, reserved, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request of the key.
+func (r *repository) CompleteIdempotencyKey(ctx context.Context, key model.IdempotencyKey) error {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Model(&model.IdempotencyKey{}).
+			Where("scope = ? and key = ?", key.Scope, key.Key).
+			Updates(map[string]interface{}{"status": key.Status, "header": key.Header, "body": key.Body}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to complete idempotency key: %s of: %s. Error: %w", key.Key, key.Scope, err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey drops the key, so a retry runs the request again.
+func (r *repository) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where("scope = ? and key = ?", scope, key).Delete(&model.IdempotencyKey{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to release idempotency key: %s of: %s. Error: %w", key, scope, err)
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys drops the expired keys of every scope and returns how
+// many were dropped.
+func (r *repository) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	var purged int64
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Where("expires_at < now()").Delete(&model.IdempotencyKey{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to purge expired idempotency keys. Error: %w", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,102 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+type LyricsRepository interface {
+	SetSyncedLyrics(ctx context.Context, songID uint, lines []model.LyricLine, text string) error
+	GetSyncedLyrics(ctx context.Context, songID uint) (model.Song, []model.LyricLine, error)
+	DeleteSyncedLyrics(ctx context.Context, songID uint) error
+}
+
+func getSong(db *gorm.DB, songID uint) (model.Song, error) {
+	var song model.Song
+	err := db.Where("id = ?", songID).First(&song).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return model.Song{}, NotFound("Song not found: id %d", songID)
+	}
+
+	return song, err
+}
+
+// SetSyncedLyrics replaces the synced lyrics of the song. The plain text is
+// stored as the song lyrics only when the song has none yet, so edited verses
+// are not overwritten by an upload.
+func (r *repository) SetSyncedLyrics(ctx context.Context, songID uint, lines []model.LyricLine, text string) error {
+	log.Printf("Trying to set %d synced lyrics lines of song: %d", len(lines), songID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		song, err := getSong(db, songID)
+		if err != nil {
+			return err
+		}
+
+		if err := db.Where("song_id = ?", songID).Delete(&model.LyricLine{}).Error; err != nil {
+			return err
+		}
+		for i, line := range lines {
+			line.SongID = songID
+			line.Position = i
+			if err := db.Create(&line).Error; err != nil {
+				return err
+			}
+		}
+
+		if song.Lyrics != "" {
+			return nil
+		}
+		if err := db.Model(&model.Song{}).Where("id = ?", songID).Update("lyrics", text).Error; err != nil {
+			return err
+		}
+		song.Lyrics = text
+		return recordEvent(db, model.SongUpdated, song)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to set synced lyrics of song: %d. Error: %w", songID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetSyncedLyrics(ctx context.Context, songID uint) (model.Song, []model.LyricLine, error) {
+	var song model.Song
+	lines := make([]model.LyricLine, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var err error
+		if song, err = getSong(db, songID); err != nil {
+			return err
+		}
+
+		return db.Where("song_id = ?", songID).Order("position").Find(&lines).Error
+	})
+	if err != nil {
+		return model.Song{}, nil, fmt.Errorf("Failed to get synced lyrics of song: %d. Error: %w", songID, err)
+	}
+
+	return song, lines, nil
+}
+
+func (r *repository) DeleteSyncedLyrics(ctx context.Context, songID uint) error {
+	log.Printf("Trying to delete synced lyrics of song: %d", songID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Where("song_id = ?", songID).Delete(&model.LyricLine{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("Song %d has no synced lyrics", songID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete synced lyrics of song: %d. Error: %w", songID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+type MergeRepository interface {
+	MergeSongs(ctx context.Context, winnerID uint, loserIDs []uint, fields map[string]uint) (model.Song, error)
+}
+
+// mergedField returns the value of the field the survivor keeps: the song
+// chosen for it, otherwise the winner's, otherwise the first non-empty value
+// of the losers.
+func mergedField(songs map[uint]model.Song, winnerID uint, loserIDs []uint, chosen map[string]uint, field string, value func(model.Song) string) string {
+	if id, ok := chosen[field]; ok {
+		return value(songs[id])
+	}
+	if v := value(songs[winnerID]); v != "" {
+		return v
+	}
+	for _, id := range loserIDs {
+		if v := value(songs[id]); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// MergeSongs folds the losers into the winner: playlist entries, favorites,
+// listening lists, tags, synced lyrics and translations of the losers move to
+// it, the losers are deleted and the winner takes the merged metadata.
+func (r *repository) MergeSongs(ctx context.Context, winnerID uint, loserIDs []uint, fields map[string]uint) (model.Song, error) {
+	log.Printf("Trying to merge songs: %v into song: %d", loserIDs, winnerID)
+	var merged model.Song
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		ids := append([]uint{winnerID}, loserIDs...)
+		var locked []model.Song
+		if err := db.Set("gorm:query_option", "FOR UPDATE").Where("id in (?)", ids).Find(&locked).Error; err != nil {
+			return err
+		}
+		songs := make(map[uint]model.Song, len(locked))
+		for _, song := range locked {
+			songs[song.ID] = song
+		}
+		for _, id := range ids {
+			if _, ok := songs[id]; !ok {
+				return NotFound("Song not found: id %d", id)
+			}
+		}
+
+		merged = model.Song{
+			ID:          winnerID,
+			Group_name:  mergedField(songs, winnerID, loserIDs, fields, "group", func(s model.Song) string { return s.Group_name }),
+			Song:        mergedField(songs, winnerID, loserIDs, fields, "song", func(s model.Song) string { return s.Song }),
+			Album:       mergedField(songs, winnerID, loserIDs, fields, "album", func(s model.Song) string { return s.Album }),
+			ReleaseDate: mergedField(songs, winnerID, loserIDs, fields, "release_date", func(s model.Song) string { return s.ReleaseDate }),
+			Lyrics:      mergedField(songs, winnerID, loserIDs, fields, "text", func(s model.Song) string { return s.Lyrics }),
+		}
+		statements := []string{
+			"update playlist_entries set song_id = ? where song_id in (?)",
+			"insert into favorites (user_id, song_id, created_at) select user_id, ?, min(created_at) from favorites where song_id in (?) group by user_id on conflict do nothing",
+			"insert into list_songs (list_id, song_id, created_at) select list_id, ?, min(created_at) from list_songs where song_id in (?) group by list_id on conflict do nothing",
+			"insert into song_tags (song_id, tag_id) select distinct ?::integer, tag_id from song_tags where song_id in (?) on conflict do nothing",
+			"insert into lyrics_variants (song_id, language, original, text) select distinct on (language) ?::integer, language, false, text from lyrics_variants where song_id in (?) order by language, original desc on conflict do nothing",
+		}
+		for _, statement := range statements {
+			if err := db.Exec(statement, winnerID, loserIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		// Synced lyrics are kept whole, from the winner when it has them.
+		var synced int
+		if err := db.Model(&model.LyricLine{}).Where("song_id = ?", winnerID).Count(&synced).Error; err != nil {
+			return err
+		}
+		if synced == 0 {
+			var source []uint
+			if err := db.Model(&model.LyricLine{}).Where("song_id in (?)", loserIDs).Order("song_id").Limit(1).Pluck("song_id", &source).Error; err != nil {
+				return err
+			}
+			if len(source) > 0 {
+				if err := db.Exec("update lyric_lines set song_id = ? where song_id = ?", winnerID, source[0]).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// The losers go before the winner takes their name, song names are
+		// unique.
+		if err := db.Where("id in (?)", loserIDs).Delete(&model.Song{}).Error; err != nil {
+			return err
+		}
+		if err := db.Save(&merged).Error; err != nil {
+			return err
+		}
+		if err := db.Model(&model.LyricsVariant{}).Where("song_id = ? and original", winnerID).Update("text", merged.Lyrics).Error; err != nil {
+			return err
+		}
+
+		if err := recordEvent(db, model.SongUpdated, merged); err != nil {
+			return err
+		}
+		for _, id := range loserIDs {
+			if err := recordEvent(db, model.SongDeleted, songs[id]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.Song{}, fmt.Errorf("Failed to merge songs: %v into song: %d. Error: %w", loserIDs, winnerID, err)
+	}
+
+	log.Printf("Merged songs: %v into song: %d", loserIDs, winnerID)
+	return merged, nil
+}
@@ -0,0 +1,203 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"slices"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrator runs the schema migrations embedded into the binary. Migrations
+// that change the schema hold a postgres advisory lock, so replicas started
+// at once apply them one after another and the later ones find nothing to do.
+type Migrator struct {
+	*goose.Provider
+}
+
+func newMigrator(db *sql.DB) (*Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	// The migrations after the unique song names are applied while it is
+	// postponed, so it may run out of order.
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, fsys,
+		goose.WithSessionLocker(locker), goose.WithAllowOutofOrder(true))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load migrations. Error: %w", err)
+	}
+
+	return &Migrator{provider}, nil
+}
+
+// NewMigrator opens the database at dsn for running the migrations by hand.
+// The caller closes the returned database.
+func NewMigrator(dsn string) (*Migrator, *sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to open database. Error: %w", err)
+	}
+
+	m, err := newMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return m, db, nil
+}
+
+// Redo rolls back the latest applied migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	down, err := m.Down(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	up, err := m.ApplyVersion(ctx, down.Source.Version, true)
+	if err != nil {
+		return []*goose.MigrationResult{down}, err
+	}
+
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// To migrates up or down until version is the latest applied migration.
+func (m *Migrator) To(ctx context.Context, version int64) ([]*goose.MigrationResult, error) {
+	current, err := m.GetDBVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version < current {
+		return m.DownTo(ctx, version)
+	}
+
+	return m.UpTo(ctx, version)
+}
+
+// applyMigrations brings the schema up to date on start. With auto
+// migration disabled the schema is left to `musicctl migrate` and pending
+// migrations are only reported.
+func applyMigrations(db *sql.DB, auto bool) error {
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if !auto {
+		pending, err := m.HasPending(ctx)
+		if err != nil {
+			return err
+		}
+		if pending {
+			log.Println("Database has pending migrations, run musicctl migrate up")
+		}
+		return nil
+	}
+
+	pending, err := pendingVersions(ctx, m)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(pending, uniqueNamesVersion) {
+		results, err := m.UpTo(ctx, uniqueNamesVersion-1)
+		logResults(results)
+		if err != nil {
+			return err
+		}
+
+		duplicates, err := duplicateNames(ctx, db)
+		if err != nil {
+			return err
+		}
+		// New songs are checked under a lock on their name while it is
+		// postponed, so the duplicates only shrink until it applies.
+		if duplicates > 0 {
+			log.Printf("Songs have %d duplicate names, migration %03d is postponed. "+
+				"Merge the songs listed by GET /songs/duplicates with POST /songs/merge, then restart the server or run musicctl migrate up",
+				duplicates, uniqueNamesVersion)
+			return applyAfter(ctx, m, pending, uniqueNamesVersion)
+		}
+	}
+
+	results, err := m.Up(ctx)
+	logResults(results)
+
+	return err
+}
+
+// pendingVersions returns the versions of the migrations not applied yet.
+func pendingVersions(ctx context.Context, m *Migrator) ([]int64, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []int64
+	for _, status := range statuses {
+		if status.State == goose.StatePending {
+			pending = append(pending, status.Source.Version)
+		}
+	}
+
+	return pending, nil
+}
+
+// applyAfter applies the pending migrations after the version one by one,
+// so the schema the code needs is there while the version is postponed.
+func applyAfter(ctx context.Context, m *Migrator, pending []int64, version int64) error {
+	for _, v := range pending {
+		if v <= version {
+			continue
+		}
+		result, err := m.ApplyVersion(ctx, v, true)
+		if err != nil {
+			return err
+		}
+		logResults([]*goose.MigrationResult{result})
+	}
+
+	return nil
+}
+
+// uniqueNamesVersion is the migration that makes song names unique. It fails
+// while songs share a name, so the server starts without it until the
+// duplicates are merged through the API.
+const uniqueNamesVersion = 12
+
+// duplicateNames counts the names stored more than once, folded the way the
+// unique name index folds them.
+func duplicateNames(ctx context.Context, db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `select count(*) from (
+			select 1 from songs
+			group by music_fold(group_name), music_fold(song)
+			having count(*) > 1
+		) duplicates`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count duplicate songs. Error: %w", err)
+	}
+
+	return count, nil
+}
+
+func logResults(results []*goose.MigrationResult) {
+	for _, result := range results {
+		log.Printf("Applied migration %s in %s", result.Source.Path, result.Duration)
+	}
+}
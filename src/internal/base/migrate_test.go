@@ -0,0 +1,44 @@
+package base
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var migrationName = regexp.MustCompile(`^(\d{3})_[a-z0-9_]+\.sql$`)
+
+// TestMigrations checks that the migrations are numbered without gaps and
+// can be rolled back.
+func TestMigrations(t *testing.T) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for i, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			t.Errorf("%s: want NNN_name.sql", entry.Name())
+			continue
+		}
+		if want := fmt.Sprintf("%03d", i+1); match[1] != want {
+			t.Errorf("%s: version %s, want %s", entry.Name(), match[1], want)
+		}
+
+		data, err := fs.ReadFile(migrations, "migrations/"+entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		up := strings.Index(string(data), "-- +goose Up")
+		down := strings.Index(string(data), "-- +goose Down")
+		if up < 0 || down < up {
+			t.Errorf("%s: want a -- +goose Up section followed by a -- +goose Down section", entry.Name())
+		}
+	}
+}
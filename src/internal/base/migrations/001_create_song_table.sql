@@ -5,4 +5,7 @@ create table if not exists songs (
     song varchar(255),
     release_date varchar(255),
     lyrics text
-);
\ No newline at end of file
+);
+
+-- +goose Down
+drop table if exists songs;
@@ -0,0 +1,44 @@
+-- +goose Up
+create table if not exists users (
+    id serial PRIMARY KEY,
+    login varchar(64) NOT NULL UNIQUE,
+    password_hash varchar(255) NOT NULL,
+    created_at timestamptz NOT NULL default now()
+);
+
+create table if not exists sessions (
+    id serial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash char(64) NOT NULL UNIQUE,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL default now()
+);
+
+create table if not exists favorites (
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL default now(),
+    PRIMARY KEY (user_id, song_id)
+);
+
+create table if not exists lists (
+    id serial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    created_at timestamptz NOT NULL default now(),
+    UNIQUE (user_id, name)
+);
+
+create table if not exists list_songs (
+    list_id integer NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL default now(),
+    PRIMARY KEY (list_id, song_id)
+);
+
+-- +goose Down
+drop table if exists list_songs;
+drop table if exists lists;
+drop table if exists favorites;
+drop table if exists sessions;
+drop table if exists users;
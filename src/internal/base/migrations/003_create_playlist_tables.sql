@@ -0,0 +1,31 @@
+-- +goose Up
+create table if not exists playlists (
+    id serial PRIMARY KEY,
+    owner_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    description text NOT NULL default '',
+    public boolean NOT NULL default false,
+    created_at timestamptz NOT NULL default now(),
+    updated_at timestamptz NOT NULL default now()
+);
+
+create table if not exists playlist_entries (
+    id serial PRIMARY KEY,
+    playlist_id integer NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position integer NOT NULL,
+    added_by integer REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamptz NOT NULL default now(),
+    CONSTRAINT playlist_entries_position UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+create table if not exists playlist_collaborators (
+    playlist_id integer NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (playlist_id, user_id)
+);
+
+-- +goose Down
+drop table if exists playlist_collaborators;
+drop table if exists playlist_entries;
+drop table if exists playlists;
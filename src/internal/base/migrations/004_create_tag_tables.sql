@@ -0,0 +1,19 @@
+-- +goose Up
+create table if not exists tags (
+    id serial PRIMARY KEY,
+    name varchar(64) NOT NULL,
+    kind varchar(32) NOT NULL,
+    UNIQUE (kind, name)
+);
+
+create table if not exists song_tags (
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    tag_id integer NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (song_id, tag_id)
+);
+
+create index if not exists song_tags_tag_id on song_tags (tag_id);
+
+-- +goose Down
+drop table if exists song_tags;
+drop table if exists tags;
@@ -0,0 +1,13 @@
+-- +goose Up
+create table if not exists lyric_lines (
+    id serial PRIMARY KEY,
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position integer NOT NULL,
+    time_ms integer NOT NULL,
+    text text NOT NULL,
+    words jsonb NOT NULL DEFAULT '[]',
+    UNIQUE (song_id, position)
+);
+
+-- +goose Down
+drop table if exists lyric_lines;
@@ -0,0 +1,14 @@
+-- +goose Up
+create table if not exists lyrics_variants (
+    id serial PRIMARY KEY,
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    language varchar(35) NOT NULL,
+    original boolean NOT NULL DEFAULT false,
+    text text NOT NULL,
+    UNIQUE (song_id, language)
+);
+
+create unique index if not exists lyrics_variants_original on lyrics_variants (song_id) where original;
+
+-- +goose Down
+drop table if exists lyrics_variants;
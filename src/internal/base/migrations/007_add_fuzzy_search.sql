@@ -0,0 +1,21 @@
+-- +goose Up
+create extension if not exists pg_trgm;
+create extension if not exists unaccent;
+
+-- unaccent is only stable, an immutable wrapper with a fixed dictionary can
+-- be used in indexes.
+-- +goose StatementBegin
+create or replace function music_fold(value text) returns text as $$
+    select lower(public.unaccent('public.unaccent'::regdictionary, value))
+$$ language sql immutable parallel safe strict;
+-- +goose StatementEnd
+
+create index if not exists songs_fold on songs (music_fold(group_name), music_fold(song));
+create index if not exists songs_group_trgm on songs using gin (music_fold(group_name) gin_trgm_ops);
+create index if not exists songs_song_trgm on songs using gin (music_fold(song) gin_trgm_ops);
+
+-- +goose Down
+drop index if exists songs_song_trgm;
+drop index if exists songs_group_trgm;
+drop index if exists songs_fold;
+drop function if exists music_fold(text);
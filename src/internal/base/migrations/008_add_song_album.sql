@@ -0,0 +1,8 @@
+-- +goose Up
+alter table songs add column if not exists album varchar(255) NOT NULL DEFAULT '';
+
+create index if not exists songs_group_album on songs (group_name, album);
+
+-- +goose Down
+drop index if exists songs_group_album;
+alter table songs drop column if exists album;
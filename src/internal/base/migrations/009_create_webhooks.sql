@@ -0,0 +1,29 @@
+-- +goose Up
+create table if not exists webhooks (
+    id serial PRIMARY KEY,
+    url varchar(2048) NOT NULL,
+    secret varchar(255) NOT NULL,
+    events text[] NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL default now()
+);
+
+create table if not exists webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    webhook_id integer NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event varchar(64) NOT NULL,
+    payload jsonb NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL default now(),
+    last_status_code integer NOT NULL DEFAULT 0,
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL default now(),
+    delivered_at timestamptz
+);
+
+create index if not exists webhook_deliveries_due on webhook_deliveries (next_attempt_at) where status = 'pending';
+create index if not exists webhook_deliveries_log on webhook_deliveries (webhook_id, id desc);
+
+-- +goose Down
+drop table if exists webhook_deliveries;
+drop table if exists webhooks;
@@ -0,0 +1,17 @@
+-- +goose Up
+create table if not exists outbox (
+    id bigserial PRIMARY KEY,
+    event varchar(64) NOT NULL,
+    song_id integer NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text NOT NULL DEFAULT '',
+    available_at timestamptz NOT NULL default now(),
+    created_at timestamptz NOT NULL default now(),
+    published_at timestamptz
+);
+
+create index if not exists outbox_unpublished on outbox (id) where published_at is null;
+
+-- +goose Down
+drop table if exists outbox;
@@ -0,0 +1,17 @@
+-- +goose Up
+create table if not exists idempotency_keys (
+    scope varchar(255) NOT NULL,
+    key varchar(255) NOT NULL,
+    request_hash char(64) NOT NULL,
+    status integer NOT NULL DEFAULT 0,
+    header jsonb,
+    body bytea,
+    created_at timestamptz NOT NULL default now(),
+    expires_at timestamptz NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+create index if not exists idempotency_keys_expires_at on idempotency_keys (scope, expires_at);
+
+-- +goose Down
+drop table if exists idempotency_keys;
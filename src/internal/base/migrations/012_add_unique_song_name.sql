@@ -0,0 +1,24 @@
+-- +goose Up
+-- Songs with the same folded name have to be merged before the index can be
+-- built. With AUTO_MIGRATE the server postpones this migration and starts, so
+-- the songs listed by GET /songs/duplicates can be merged with POST /songs/merge.
+-- +goose StatementBegin
+do $$
+begin
+    if exists (
+        select 1 from songs
+        group by music_fold(group_name), music_fold(song)
+        having count(*) > 1
+    ) then
+        raise exception 'songs has duplicate names, merge the songs listed by GET /songs/duplicates with POST /songs/merge before migrating';
+    end if;
+end
+$$;
+-- +goose StatementEnd
+
+create unique index if not exists songs_name_unique on songs (music_fold(group_name), music_fold(song));
+drop index if exists songs_fold;
+
+-- +goose Down
+create index if not exists songs_fold on songs (music_fold(group_name), music_fold(song));
+drop index if exists songs_name_unique;
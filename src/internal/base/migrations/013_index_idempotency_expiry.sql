@@ -0,0 +1,8 @@
+-- +goose Up
+-- Expired keys are purged for every scope at once.
+create index if not exists idempotency_keys_expiry on idempotency_keys (expires_at);
+drop index if exists idempotency_keys_expires_at;
+
+-- +goose Down
+create index if not exists idempotency_keys_expires_at on idempotency_keys (scope, expires_at);
+drop index if exists idempotency_keys_expiry;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- A failed event is only published again to the sinks that did not get it,
+-- and a webhook gets one delivery per event however often it is queued.
+alter table outbox add column if not exists published_to text[] NOT NULL DEFAULT '{}';
+alter table webhook_deliveries add column if not exists event_id bigint;
+create unique index if not exists webhook_deliveries_event on webhook_deliveries (webhook_id, event_id);
+
+-- +goose Down
+drop index if exists webhook_deliveries_event;
+alter table webhook_deliveries drop column if exists event_id;
+alter table outbox drop column if exists published_to;
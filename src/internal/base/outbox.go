@@ -0,0 +1,134 @@
+package base
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"music/internal/model"
+	"slices"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxOutboxDelay caps the wait before an event that failed to publish is
+	// tried again.
+	maxOutboxDelay = 3600
+	// pruneChunk is how many published events a transaction of PruneOutbox
+	// deletes.
+	pruneChunk = 1000
+)
+
+type OutboxRepository interface {
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error)
+	MarkPublished(ctx context.Context, eventID uint64) error
+	DelayEvent(ctx context.Context, eventID uint64, publishedTo []string, cause string) error
+	PruneOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// recordEvent writes a change event of the song into the outbox. It runs in
+// the transaction of the change, so the event exists if and only if the
+// change is committed.
+func recordEvent(db *gorm.DB, event string, song model.Song) error {
+	payload, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(&model.OutboxEvent{Event: event, SongID: song.ID, Payload: payload}).Error
+}
+
+func recordEvents(db *gorm.DB, event string, songs []model.Song) error {
+	for _, song := range songs {
+		if err := recordEvent(db, event, song); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ClaimOutbox takes up to limit unpublished due events in the order they were
+// written. A claimed event becomes due again after its lease, so the sinks
+// run outside the transaction, other replicas skip the claimed events and an
+// event lost with a crashed replica is published again. The events are
+// published one after another, the n-th is leased for n times the lease.
+func (r *repository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	events := make([]model.OutboxEvent, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Raw(`update outbox o
+			set available_at = now() + c.position * ? * interval '1 second'
+			from (
+				select id, row_number() over (order by id) as position from (
+					select id from outbox
+					where published_at is null and available_at <= now()
+					order by id
+					limit ?
+					for update skip locked) due
+			) c
+			where o.id = c.id
+			returning o.*`, lease.Seconds(), limit).Scan(&events).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to claim outbox events. Error: %w", err)
+	}
+
+	// returning does not keep the order of the subquery.
+	slices.SortFunc(events, func(a, b model.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return events, nil
+}
+
+func (r *repository) MarkPublished(ctx context.Context, eventID uint64) error {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Exec("update outbox set published_at = now() where id = ?", eventID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to mark outbox event published: %d. Error: %w", eventID, err)
+	}
+
+	return nil
+}
+
+// DelayEvent records the failure to publish the event and the sinks that
+// received it, and makes it due again after a delay growing with the
+// attempts.
+func (r *repository) DelayEvent(ctx context.Context, eventID uint64, publishedTo []string, cause string) error {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Exec(`update outbox
+			set attempts = attempts + 1, last_error = ?, published_to = ?,
+				available_at = now() + least(power(2, attempts), ?) * interval '1 second'
+			where id = ?`, cause, pq.StringArray(publishedTo), maxOutboxDelay, eventID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delay outbox event: %d. Error: %w", eventID, err)
+	}
+
+	return nil
+}
+
+// PruneOutbox deletes the events published before the time in transactions
+// of pruneChunk events and returns how many were deleted.
+func (r *repository) PruneOutbox(ctx context.Context, before time.Time) (int64, error) {
+	var pruned int64
+	for {
+		var deleted int64
+		err := r.withContext(ctx, func(db *gorm.DB) error {
+			result := db.Exec(`delete from outbox where id in (
+				select id from outbox where published_at < ? limit ?)`, before, pruneChunk)
+			deleted = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return pruned, fmt.Errorf("Failed to prune outbox. Error: %w", err)
+		}
+		pruned += deleted
+		if deleted < pruneChunk {
+			return pruned, nil
+		}
+	}
+}
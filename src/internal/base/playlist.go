@@ -0,0 +1,378 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+type PlaylistRepository interface {
+	CreatePlaylist(ctx context.Context, playlist model.Playlist) (model.Playlist, error)
+	GetPlaylists(ctx context.Context, userID uint) ([]model.Playlist, error)
+	GetPlaylist(ctx context.Context, userID, playlistID uint) (model.Playlist, error)
+	UpdatePlaylist(ctx context.Context, userID, playlistID uint, fields map[string]interface{}) (model.Playlist, error)
+	DeletePlaylist(ctx context.Context, userID, playlistID uint) error
+
+	GetPlaylistEntries(ctx context.Context, userID, playlistID uint) ([]model.PlaylistEntry, error)
+	InsertPlaylistEntry(ctx context.Context, userID, playlistID, songID uint, position *int) (model.PlaylistEntry, error)
+	MovePlaylistEntry(ctx context.Context, userID, playlistID, entryID uint, position int) error
+	RemovePlaylistEntry(ctx context.Context, userID, playlistID, entryID uint) error
+
+	AddCollaborator(ctx context.Context, ownerID, playlistID, userID uint) error
+	RemoveCollaborator(ctx context.Context, ownerID, playlistID, userID uint) error
+}
+
+type access int
+
+const (
+	accessEdit access = iota
+	accessOwner
+)
+
+// visible restricts playlists to public ones and the ones the user owns or
+// collaborates on.
+func visible(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where(`public or owner_id = ? or exists (
+		select 1 from playlist_collaborators c where c.playlist_id = playlists.id and c.user_id = ?)`, userID, userID)
+}
+
+// lockPlaylist loads the playlist with a row lock, so entry edits of the same
+// playlist are serialized until the transaction ends. Playlists the user may
+// not see are reported as missing, the ones it may only see as forbidden.
+func lockPlaylist(db *gorm.DB, userID, playlistID uint, level access) (model.Playlist, error) {
+	var playlist model.Playlist
+	err := db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", playlistID).First(&playlist).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return model.Playlist{}, NotFound("Playlist not found: id %d", playlistID)
+	}
+	if err != nil {
+		return model.Playlist{}, err
+	}
+
+	if playlist.OwnerID == userID {
+		return playlist, nil
+	}
+
+	var collaborator int
+	if err := db.Model(&model.PlaylistCollaborator{}).Where("playlist_id = ? and user_id = ?", playlistID, userID).Count(&collaborator).Error; err != nil {
+		return model.Playlist{}, err
+	}
+
+	switch {
+	case !playlist.Public && collaborator == 0:
+		return model.Playlist{}, NotFound("Playlist not found: id %d", playlistID)
+	case level == accessOwner:
+		return model.Playlist{}, Forbidden("Only the owner may change playlist %d", playlistID)
+	case level == accessEdit && collaborator == 0:
+		return model.Playlist{}, Forbidden("Only the owner and collaborators may edit playlist %d", playlistID)
+	}
+
+	return playlist, nil
+}
+
+// touch marks the playlist as changed.
+func touch(db *gorm.DB, playlistID uint) error {
+	return db.Exec("update playlists set updated_at = now() where id = ?", playlistID).Error
+}
+
+// compactPositions renumbers the entries of the playlists from zero, closing
+// the gaps left by removed songs.
+func compactPositions(db *gorm.DB, playlistIDs []uint) error {
+	if len(playlistIDs) == 0 {
+		return nil
+	}
+
+	return db.Exec(`update playlist_entries e set position = r.position
+		from (select id, row_number() over (partition by playlist_id order by position) - 1 as position
+			from playlist_entries where playlist_id in (?)) r
+		where e.id = r.id and e.position <> r.position`, playlistIDs).Error
+}
+
+// lockPlaylistsOfSongs locks and returns the playlists that contain any of
+// the songs, so compacting their positions is serialized with the entry
+// edits that lock them in lockPlaylist. They are locked in id order, so two
+// deletes can not deadlock.
+func lockPlaylistsOfSongs(db *gorm.DB, songIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`select id from playlists
+		where id in (select playlist_id from playlist_entries where song_id in (?))
+		order by id
+		for update`, songIDs).Pluck("id", &ids).Error
+
+	return ids, err
+}
+
+func (r *repository) CreatePlaylist(ctx context.Context, playlist model.Playlist) (model.Playlist, error) {
+	log.Printf("Trying to create playlist: %s of user: %d", playlist.Name, playlist.OwnerID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Create(&playlist).Error
+	})
+	if err != nil {
+		return model.Playlist{}, fmt.Errorf("Failed to create playlist: %s. Error: %w", playlist.Name, err)
+	}
+
+	return playlist, nil
+}
+
+func (r *repository) GetPlaylists(ctx context.Context, userID uint) ([]model.Playlist, error) {
+	playlists := make([]model.Playlist, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return visible(db, userID).Order("name").Find(&playlists).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get playlists of user: %d. Error: %w", userID, err)
+	}
+
+	return playlists, nil
+}
+
+func (r *repository) GetPlaylist(ctx context.Context, userID, playlistID uint) (model.Playlist, error) {
+	var playlist model.Playlist
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return visible(db, userID).Where("id = ?", playlistID).First(&playlist).Error
+	})
+	if gorm.IsRecordNotFoundError(err) {
+		return model.Playlist{}, NotFound("Playlist not found: id %d", playlistID)
+	}
+	if err != nil {
+		return model.Playlist{}, fmt.Errorf("Failed to get playlist: %d. Error: %w", playlistID, err)
+	}
+
+	return playlist, nil
+}
+
+func (r *repository) UpdatePlaylist(ctx context.Context, userID, playlistID uint, fields map[string]interface{}) (model.Playlist, error) {
+	log.Printf("Trying to update playlist: %d", playlistID)
+	var playlist model.Playlist
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var err error
+		if playlist, err = lockPlaylist(db, userID, playlistID, accessOwner); err != nil {
+			return err
+		}
+
+		return db.Model(&playlist).Updates(fields).Error
+	})
+	if err != nil {
+		return model.Playlist{}, fmt.Errorf("Failed to update playlist: %d. Error: %w", playlistID, err)
+	}
+
+	return playlist, nil
+}
+
+func (r *repository) DeletePlaylist(ctx context.Context, userID, playlistID uint) error {
+	log.Printf("Trying to delete playlist: %d", playlistID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		playlist, err := lockPlaylist(db, userID, playlistID, accessOwner)
+		if err != nil {
+			return err
+		}
+
+		return db.Delete(&playlist).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete playlist: %d. Error: %w", playlistID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetPlaylistEntries(ctx context.Context, userID, playlistID uint) ([]model.PlaylistEntry, error) {
+	entries := make([]model.PlaylistEntry, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var count int
+		if err := visible(db.Model(&model.Playlist{}), userID).Where("id = ?", playlistID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return NotFound("Playlist not found: id %d", playlistID)
+		}
+
+		return db.Preload("Song").Where("playlist_id = ?", playlistID).Order("position").Find(&entries).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get entries of playlist: %d. Error: %w", playlistID, err)
+	}
+
+	return entries, nil
+}
+
+// InsertPlaylistEntry puts the song at position, shifting the following
+// entries down. A nil position appends the song.
+func (r *repository) InsertPlaylistEntry(ctx context.Context, userID, playlistID, songID uint, position *int) (model.PlaylistEntry, error) {
+	log.Printf("Trying to insert song: %d into playlist: %d", songID, playlistID)
+	entry := model.PlaylistEntry{PlaylistID: playlistID, SongID: songID, AddedBy: &userID}
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if _, err := lockPlaylist(db, userID, playlistID, accessEdit); err != nil {
+			return err
+		}
+		if err := songExists(db, songID); err != nil {
+			return err
+		}
+
+		var count int
+		if err := db.Model(&model.PlaylistEntry{}).Where("playlist_id = ?", playlistID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		entry.Position = count
+		if position != nil {
+			if *position < 0 || *position > count {
+				return Validation("Position must be between 0 and %d", count)
+			}
+			entry.Position = *position
+		}
+
+		err := db.Exec("update playlist_entries set position = position + 1 where playlist_id = ? and position >= ?", playlistID, entry.Position).Error
+		if err != nil {
+			return err
+		}
+		if err := db.Set("gorm:save_associations", false).Create(&entry).Error; err != nil {
+			return err
+		}
+		if err := db.Where("id = ?", songID).First(&entry.Song).Error; err != nil {
+			return err
+		}
+
+		return touch(db, playlistID)
+	})
+	if err != nil {
+		return model.PlaylistEntry{}, fmt.Errorf("Failed to insert song: %d into playlist: %d. Error: %w", songID, playlistID, err)
+	}
+
+	return entry, nil
+}
+
+func (r *repository) MovePlaylistEntry(ctx context.Context, userID, playlistID, entryID uint, position int) error {
+	log.Printf("Trying to move entry: %d of playlist: %d to position: %d", entryID, playlistID, position)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if _, err := lockPlaylist(db, userID, playlistID, accessEdit); err != nil {
+			return err
+		}
+
+		var entry model.PlaylistEntry
+		err := db.Where("id = ? and playlist_id = ?", entryID, playlistID).First(&entry).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound("Entry %d not found in playlist %d", entryID, playlistID)
+		}
+		if err != nil {
+			return err
+		}
+
+		var count int
+		if err := db.Model(&model.PlaylistEntry{}).Where("playlist_id = ?", playlistID).Count(&count).Error; err != nil {
+			return err
+		}
+		if position < 0 || position >= count {
+			return Validation("Position must be between 0 and %d", count-1)
+		}
+
+		switch {
+		case position > entry.Position:
+			err = db.Exec("update playlist_entries set position = position - 1 where playlist_id = ? and position > ? and position <= ?",
+				playlistID, entry.Position, position).Error
+		case position < entry.Position:
+			err = db.Exec("update playlist_entries set position = position + 1 where playlist_id = ? and position >= ? and position < ?",
+				playlistID, position, entry.Position).Error
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := db.Model(&entry).Update("position", position).Error; err != nil {
+			return err
+		}
+
+		return touch(db, playlistID)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to move entry: %d of playlist: %d. Error: %w", entryID, playlistID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) RemovePlaylistEntry(ctx context.Context, userID, playlistID, entryID uint) error {
+	log.Printf("Trying to remove entry: %d from playlist: %d", entryID, playlistID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if _, err := lockPlaylist(db, userID, playlistID, accessEdit); err != nil {
+			return err
+		}
+
+		var entry model.PlaylistEntry
+		err := db.Where("id = ? and playlist_id = ?", entryID, playlistID).First(&entry).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound("Entry %d not found in playlist %d", entryID, playlistID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := db.Delete(&entry).Error; err != nil {
+			return err
+		}
+		err = db.Exec("update playlist_entries set position = position - 1 where playlist_id = ? and position > ?", playlistID, entry.Position).Error
+		if err != nil {
+			return err
+		}
+
+		return touch(db, playlistID)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to remove entry: %d from playlist: %d. Error: %w", entryID, playlistID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) AddCollaborator(ctx context.Context, ownerID, playlistID, userID uint) error {
+	log.Printf("Trying to add collaborator: %d to playlist: %d", userID, playlistID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if _, err := lockPlaylist(db, ownerID, playlistID, accessOwner); err != nil {
+			return err
+		}
+
+		var count int
+		if err := db.Model(&model.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return NotFound("User not found: id %d", userID)
+		}
+
+		return db.Exec("insert into playlist_collaborators (playlist_id, user_id) values (?, ?) on conflict do nothing", playlistID, userID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to add collaborator: %d to playlist: %d. Error: %w", userID, playlistID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) RemoveCollaborator(ctx context.Context, ownerID, playlistID, userID uint) error {
+	log.Printf("Trying to remove collaborator: %d from playlist: %d", userID, playlistID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if _, err := lockPlaylist(db, ownerID, playlistID, accessOwner); err != nil {
+			return err
+		}
+
+		result := db.Where("playlist_id = ? and user_id = ?", playlistID, userID).Delete(&model.PlaylistCollaborator{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("User %d does not collaborate on playlist %d", userID, playlistID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to remove collaborator: %d from playlist: %d. Error: %w", userID, playlistID, err)
+	}
+
+	return nil
+}
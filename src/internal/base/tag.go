@@ -0,0 +1,194 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"music/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error)
+	GetTags(ctx context.Context, kind string) ([]model.Tag, error)
+	DeleteTag(ctx context.Context, tagID uint) error
+	TagSong(ctx context.Context, songID uint, tag model.Tag) (model.Tag, error)
+	UntagSong(ctx context.Context, songID, tagID uint) error
+	GetSongTags(ctx context.Context, songID uint) ([]model.Tag, error)
+	GetTagsOfSongs(ctx context.Context, songIDs []uint) (map[uint][]model.Tag, error)
+	TagCloud(ctx context.Context, kind string) ([]model.TagCount, error)
+}
+
+func (r *repository) CreateTag(ctx context.Context, tag model.Tag) (model.Tag, error) {
+	log.Printf("Trying to create tag: %s:%s", tag.Kind, tag.Name)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Create(&tag).Error
+	})
+	if errors.Is(err, ErrConflict) {
+		return model.Tag{}, Conflict("Tag %s:%s already exists", tag.Kind, tag.Name)
+	}
+	if err != nil {
+		return model.Tag{}, fmt.Errorf("Failed to create tag: %s:%s. Error: %w", tag.Kind, tag.Name, err)
+	}
+
+	return tag, nil
+}
+
+func (r *repository) GetTags(ctx context.Context, kind string) ([]model.Tag, error) {
+	tags := make([]model.Tag, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if kind != "" {
+			db = db.Where("kind = ?", kind)
+		}
+
+		return db.Order("kind, name").Find(&tags).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get tags of kind: %s. Error: %w", kind, err)
+	}
+
+	return tags, nil
+}
+
+func (r *repository) DeleteTag(ctx context.Context, tagID uint) error {
+	log.Printf("Trying to delete tag: %d", tagID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Where("id = ?", tagID).Delete(&model.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("Tag not found: id %d", tagID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete tag: %d. Error: %w", tagID, err)
+	}
+
+	return nil
+}
+
+// TagSong attaches the tag with the kind and name to the song, creating the
+// tag when it does not exist yet.
+func (r *repository) TagSong(ctx context.Context, songID uint, tag model.Tag) (model.Tag, error) {
+	log.Printf("Trying to tag song: %d with %s:%s", songID, tag.Kind, tag.Name)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := songExists(db, songID); err != nil {
+			return err
+		}
+
+		err := db.Exec("insert into tags (name, kind) values (?, ?) on conflict (kind, name) do nothing", tag.Name, tag.Kind).Error
+		if err != nil {
+			return err
+		}
+		if err := db.Where("kind = ? and name = ?", tag.Kind, tag.Name).First(&tag).Error; err != nil {
+			return err
+		}
+
+		return db.Exec("insert into song_tags (song_id, tag_id) values (?, ?) on conflict do nothing", songID, tag.ID).Error
+	})
+	if err != nil {
+		return model.Tag{}, fmt.Errorf("Failed to tag song: %d with %s:%s. Error: %w", songID, tag.Kind, tag.Name, err)
+	}
+
+	return tag, nil
+}
+
+func (r *repository) UntagSong(ctx context.Context, songID, tagID uint) error {
+	log.Printf("Trying to untag song: %d from tag: %d", songID, tagID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Where("song_id = ? and tag_id = ?", songID, tagID).Delete(&model.SongTag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("Song %d is not tagged with tag %d", songID, tagID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to untag song: %d from tag: %d. Error: %w", songID, tagID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetSongTags(ctx context.Context, songID uint) ([]model.Tag, error) {
+	tags := make([]model.Tag, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := songExists(db, songID); err != nil {
+			return err
+		}
+
+		return db.Select("tags.*").
+			Joins("JOIN song_tags ON song_tags.tag_id = tags.id").
+			Where("song_tags.song_id = ?", songID).
+			Order("tags.kind, tags.name").
+			Find(&tags).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get tags of song: %d. Error: %w", songID, err)
+	}
+
+	return tags, nil
+}
+
+// GetTagsOfSongs loads the tags of several songs at once.
+func (r *repository) GetTagsOfSongs(ctx context.Context, songIDs []uint) (map[uint][]model.Tag, error) {
+	result := make(map[uint][]model.Tag, len(songIDs))
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		rows, err := db.Table("tags").
+			Select("song_tags.song_id, tags.id, tags.name, tags.kind").
+			Joins("JOIN song_tags ON song_tags.tag_id = tags.id").
+			Where("song_tags.song_id in (?)", songIDs).
+			Order("tags.kind, tags.name").
+			Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var songID uint
+			var tag model.Tag
+			if err := rows.Scan(&songID, &tag.ID, &tag.Name, &tag.Kind); err != nil {
+				return err
+			}
+			result[songID] = append(result[songID], tag)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get tags of %d songs. Error: %w", len(songIDs), err)
+	}
+
+	return result, nil
+}
+
+// TagCloud counts the songs of every tag, most used tags first.
+func (r *repository) TagCloud(ctx context.Context, kind string) ([]model.TagCount, error) {
+	cloud := make([]model.TagCount, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		query := db.Table("tags").
+			Select("tags.id, tags.name, tags.kind, count(song_tags.song_id) as count").
+			Joins("JOIN song_tags ON song_tags.tag_id = tags.id").
+			Group("tags.id").
+			Order("count desc, tags.name")
+		if kind != "" {
+			query = query.Where("tags.kind = ?", kind)
+		}
+
+		return query.Scan(&cloud).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get tag cloud of kind: %s. Error: %w", kind, err)
+	}
+
+	return cloud, nil
+}
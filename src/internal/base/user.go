@@ -0,0 +1,330 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"music/internal/model"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, login, passwordHash string) (model.User, error)
+	GetUserByLogin(ctx context.Context, login string) (model.User, error)
+	CreateSession(ctx context.Context, userID uint, tokenHash string, expiresAt time.Time) error
+	SessionUser(ctx context.Context, tokenHash string) (model.User, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+
+	AddFavorite(ctx context.Context, userID, songID uint) error
+	RemoveFavorite(ctx context.Context, userID, songID uint) error
+	GetFavorites(ctx context.Context, userID uint, filter string) ([]model.Song, error)
+	GetFavoritesWithPagination(ctx context.Context, userID uint, filter string, page, size int) ([]model.Song, error)
+
+	CreateList(ctx context.Context, userID uint, name string) (model.List, error)
+	GetLists(ctx context.Context, userID uint) ([]model.List, error)
+	DeleteList(ctx context.Context, userID, listID uint) error
+	AddToList(ctx context.Context, userID, listID, songID uint) error
+	RemoveFromList(ctx context.Context, userID, listID, songID uint) error
+	GetListSongs(ctx context.Context, userID, listID uint, page, size int) ([]model.Song, error)
+}
+
+// paginated applies 1-based pagination, a zero size returns everything.
+func paginated(db *gorm.DB, page, size int) *gorm.DB {
+	if size == 0 {
+		return db
+	}
+
+	return db.Offset((page - 1) * size).Limit(size)
+}
+
+func songExists(db *gorm.DB, songID uint) error {
+	var count int
+	if err := db.Model(&model.Song{}).Where("id = ?", songID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return NotFound("Song not found: id %d", songID)
+	}
+
+	return nil
+}
+
+func (r *repository) CreateUser(ctx context.Context, login, passwordHash string) (model.User, error) {
+	log.Printf("Trying to create user: %s", login)
+	user := model.User{Login: login, PasswordHash: passwordHash}
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Create(&user).Error
+	})
+	if errors.Is(err, ErrConflict) {
+		return model.User{}, Conflict("Login %s is already taken", login)
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("Failed to create user: %s. Error: %w", login, err)
+	}
+
+	log.Printf("User: %s created with ID:%d", login, user.ID)
+	return user, nil
+}
+
+func (r *repository) GetUserByLogin(ctx context.Context, login string) (model.User, error) {
+	var user model.User
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where("login = ?", login).First(&user).Error
+	})
+	if gorm.IsRecordNotFoundError(err) {
+		return model.User{}, NotFound("User not found: %s", login)
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("Failed to get user: %s. Error: %w", login, err)
+	}
+
+	return user, nil
+}
+
+func (r *repository) CreateSession(ctx context.Context, userID uint, tokenHash string, expiresAt time.Time) error {
+	session := model.Session{UserID: userID, TokenHash: tokenHash, ExpiresAt: expiresAt}
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := db.Where("user_id = ? and expires_at < now()", userID).Delete(&model.Session{}).Error; err != nil {
+			return err
+		}
+
+		return db.Create(&session).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to create session of user: %d. Error: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) SessionUser(ctx context.Context, tokenHash string) (model.User, error) {
+	var user model.User
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Select("users.*").
+			Joins("JOIN sessions ON sessions.user_id = users.id").
+			Where("sessions.token_hash = ? and sessions.expires_at > now()", tokenHash).
+			First(&user).Error
+	})
+	if gorm.IsRecordNotFoundError(err) {
+		return model.User{}, NotFound("Session not found")
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("Failed to get session user. Error: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *repository) DeleteSession(ctx context.Context, tokenHash string) error {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where("token_hash = ?", tokenHash).Delete(&model.Session{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete session. Error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) AddFavorite(ctx context.Context, userID, songID uint) error {
+	log.Printf("Trying to add song: %d to favorites of user: %d", songID, userID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := songExists(db, songID); err != nil {
+			return err
+		}
+
+		return db.Exec("insert into favorites (user_id, song_id) values (?, ?) on conflict do nothing", userID, songID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to add song: %d to favorites of user: %d. Error: %w", songID, userID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) RemoveFavorite(ctx context.Context, userID, songID uint) error {
+	log.Printf("Trying to remove song: %d from favorites of user: %d", songID, userID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Where("user_id = ? and song_id = ?", userID, songID).Delete(&model.Favorite{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("Song %d is not in favorites", songID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to remove song: %d from favorites of user: %d. Error: %w", songID, userID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetFavorites(ctx context.Context, userID uint, filter string) ([]model.Song, error) {
+	return r.GetFavoritesWithPagination(ctx, userID, filter, 1, 0)
+}
+
+func (r *repository) GetFavoritesWithPagination(ctx context.Context, userID uint, filter string, page, size int) ([]model.Song, error) {
+	log.Printf("Trying to get favorites of user: %d with filter: %s, page: %d, size: %d", userID, filter, page, size)
+	songs := make([]model.Song, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		query, err := favorites(db, userID, filter)
+		if err != nil {
+			return err
+		}
+
+		return paginated(query, page, size).Find(&songs).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get favorites of user: %d. Error: %w", userID, err)
+	}
+
+	return songs, nil
+}
+
+// favorites selects the favorite songs of the user matching the filter, the
+// latest favorites first unless the filter sorts them.
+func favorites(db *gorm.DB, userID uint, filter string) (*gorm.DB, error) {
+	query := db.Select("songs.*").
+		Joins("JOIN favorites ON favorites.song_id = songs.id").
+		Where("favorites.user_id = ?", userID)
+	query, err := filtered(query, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !sorted(filter) {
+		query = query.Order("favorites.created_at desc, songs.id desc")
+	}
+
+	return query, nil
+}
+
+func (r *repository) CreateList(ctx context.Context, userID uint, name string) (model.List, error) {
+	log.Printf("Trying to create list: %s of user: %d", name, userID)
+	list := model.List{UserID: userID, Name: name}
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Create(&list).Error
+	})
+	if errors.Is(err, ErrConflict) {
+		return model.List{}, Conflict("List %s already exists", name)
+	}
+	if err != nil {
+		return model.List{}, fmt.Errorf("Failed to create list: %s of user: %d. Error: %w", name, userID, err)
+	}
+
+	return list, nil
+}
+
+func (r *repository) GetLists(ctx context.Context, userID uint) ([]model.List, error) {
+	lists := make([]model.List, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where("user_id = ?", userID).Order("name").Find(&lists).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get lists of user: %d. Error: %w", userID, err)
+	}
+
+	return lists, nil
+}
+
+// ownList checks that the list exists and belongs to the user. Lists of other
+// users are reported as missing.
+func ownList(db *gorm.DB, userID, listID uint) error {
+	var count int
+	if err := db.Model(&model.List{}).Where("id = ? and user_id = ?", listID, userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return NotFound("List not found: id %d", listID)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteList(ctx context.Context, userID, listID uint) error {
+	log.Printf("Trying to delete list: %d of user: %d", listID, userID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Where("id = ? and user_id = ?", listID, userID).Delete(&model.List{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("List not found: id %d", listID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete list: %d of user: %d. Error: %w", listID, userID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) AddToList(ctx context.Context, userID, listID, songID uint) error {
+	log.Printf("Trying to add song: %d to list: %d of user: %d", songID, listID, userID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := ownList(db, userID, listID); err != nil {
+			return err
+		}
+		if err := songExists(db, songID); err != nil {
+			return err
+		}
+
+		return db.Exec("insert into list_songs (list_id, song_id) values (?, ?) on conflict do nothing", listID, songID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to add song: %d to list: %d. Error: %w", songID, listID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) RemoveFromList(ctx context.Context, userID, listID, songID uint) error {
+	log.Printf("Trying to remove song: %d from list: %d of user: %d", songID, listID, userID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := ownList(db, userID, listID); err != nil {
+			return err
+		}
+
+		result := db.Where("list_id = ? and song_id = ?", listID, songID).Delete(&model.ListSong{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("Song %d is not in list %d", songID, listID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to remove song: %d from list: %d. Error: %w", songID, listID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetListSongs(ctx context.Context, userID, listID uint, page, size int) ([]model.Song, error) {
+	songs := make([]model.Song, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if err := ownList(db, userID, listID); err != nil {
+			return err
+		}
+
+		query := db.Select("songs.*").
+			Joins("JOIN list_songs ON list_songs.song_id = songs.id").
+			Where("list_songs.list_id = ?", listID).
+			Order("list_songs.created_at, songs.id")
+		return paginated(query, page, size).Find(&songs).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get songs of list: %d. Error: %w", listID, err)
+	}
+
+	return songs, nil
+}
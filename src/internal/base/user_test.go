@@ -0,0 +1,41 @@
+package base
+
+import (
+	"music/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestFavoritesOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{name: "latest first", filter: "", want: `ORDER BY favorites.created_at desc, songs.id desc`},
+		{name: "filtered", filter: "group=Muse", want: `ORDER BY favorites.created_at desc, songs.id desc`},
+		{name: "sorted", filter: "sort=song", want: `ORDER BY "songs"."song","songs"."id" `},
+		{name: "sorted descending", filter: "group=Muse&sort=-release_date", want: `ORDER BY songs.release_date desc,"songs"."id" `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, logged := offlineDB(t)
+			query, err := favorites(db, 7, tt.filter)
+			if err != nil {
+				t.Fatalf("favorites() error = %v", err)
+			}
+			query.Find(&[]model.Song{})
+
+			if len(*logged) != 1 {
+				t.Fatalf("queries = %q, want one query", *logged)
+			}
+			if !strings.Contains((*logged)[0], tt.want) || strings.Count((*logged)[0], "ORDER BY") != 1 {
+				t.Errorf("query = %q, want it ordered by %q", (*logged)[0], tt.want)
+			}
+			if strings.Contains(tt.filter, "sort=") && strings.Contains((*logged)[0], "favorites.created_at") {
+				t.Errorf("query = %q, want the sort of the filter only", (*logged)[0])
+			}
+		})
+	}
+}
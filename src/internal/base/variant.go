@@ -0,0 +1,145 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+type VariantRepository interface {
+	GetVariants(ctx context.Context, songID uint) (model.Song, []model.LyricsVariant, error)
+	GetVariantsByName(ctx context.Context, group, song string) (model.Song, []model.LyricsVariant, error)
+	GetVariantsOfSongs(ctx context.Context, songIDs []uint) (map[uint][]model.LyricsVariant, error)
+	SetVariant(ctx context.Context, songID uint, variant model.LyricsVariant) error
+	DeleteVariant(ctx context.Context, songID uint, language string) error
+}
+
+func variantsOf(db *gorm.DB, songID uint) ([]model.LyricsVariant, error) {
+	variants := make([]model.LyricsVariant, 0)
+	err := db.Where("song_id = ?", songID).Order("original desc, language").Find(&variants).Error
+	return variants, err
+}
+
+func (r *repository) GetVariants(ctx context.Context, songID uint) (model.Song, []model.LyricsVariant, error) {
+	var song model.Song
+	var variants []model.LyricsVariant
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var err error
+		if song, err = getSong(db, songID); err != nil {
+			return err
+		}
+		variants, err = variantsOf(db, songID)
+		return err
+	})
+	if err != nil {
+		return model.Song{}, nil, fmt.Errorf("Failed to get lyrics variants of song: %d. Error: %w", songID, err)
+	}
+
+	return song, variants, nil
+}
+
+func (r *repository) GetVariantsByName(ctx context.Context, group, song string) (model.Song, []model.LyricsVariant, error) {
+	log.Printf("Trying to get lyrics of group: %s, song: %s", group, song)
+	var target model.Song
+	var variants []model.LyricsVariant
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		err := db.Where(sameSong, group, song).First(&target).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return songNotFound(db, group, song)
+		}
+		if err != nil {
+			return err
+		}
+		variants, err = variantsOf(db, target.ID)
+		return err
+	})
+	if err != nil {
+		return model.Song{}, nil, fmt.Errorf("Failed to get lyrics of group: %s, song: %s. Error: %w", group, song, err)
+	}
+
+	return target, variants, nil
+}
+
+// GetVariantsOfSongs loads the variants of several songs at once, originals
+// first.
+func (r *repository) GetVariantsOfSongs(ctx context.Context, songIDs []uint) (map[uint][]model.LyricsVariant, error) {
+	var variants []model.LyricsVariant
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Where("song_id in (?)", songIDs).Order("song_id, original desc, language").Find(&variants).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get lyrics variants of %d songs. Error: %w", len(songIDs), err)
+	}
+
+	result := make(map[uint][]model.LyricsVariant, len(songIDs))
+	for _, variant := range variants {
+		result[variant.SongID] = append(result[variant.SongID], variant)
+	}
+
+	return result, nil
+}
+
+// SetVariant creates or replaces the variant in its language. Marking it
+// original unmarks the previous original and copies the text to the song
+// lyrics.
+func (r *repository) SetVariant(ctx context.Context, songID uint, variant model.LyricsVariant) error {
+	log.Printf("Trying to set %s lyrics of song: %d", variant.Language, songID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		song, err := getSong(db, songID)
+		if err != nil {
+			return err
+		}
+
+		if variant.Original {
+			err := db.Model(&model.LyricsVariant{}).
+				Where("song_id = ? and original and language <> ?", songID, variant.Language).
+				Update("original", false).Error
+			if err != nil {
+				return err
+			}
+			if err := db.Model(&model.Song{}).Where("id = ?", songID).Update("lyrics", variant.Text).Error; err != nil {
+				return err
+			}
+			song.Lyrics = variant.Text
+			if err := recordEvent(db, model.SongUpdated, song); err != nil {
+				return err
+			}
+		}
+
+		return db.Exec(`insert into lyrics_variants (song_id, language, original, text) values (?, ?, ?, ?)
+			on conflict (song_id, language) do update set original = excluded.original, text = excluded.text`,
+			songID, variant.Language, variant.Original, variant.Text).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to set %s lyrics of song: %d. Error: %w", variant.Language, songID, err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteVariant(ctx context.Context, songID uint, language string) error {
+	log.Printf("Trying to delete %s lyrics of song: %d", language, songID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		var variant model.LyricsVariant
+		err := db.Where("song_id = ? and language = ?", songID, language).First(&variant).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound("Song %d has no %s lyrics", songID, language)
+		}
+		if err != nil {
+			return err
+		}
+		if variant.Original {
+			return Conflict("The original lyrics can not be deleted, mark another variant original first")
+		}
+
+		return db.Delete(&variant).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete %s lyrics of song: %d. Error: %w", language, songID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music/internal/model"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, webhookID uint) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID uint) error
+	QueueEvent(ctx context.Context, eventID uint64, event string, payload []byte) (int64, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.PendingDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	ReleaseDeliveries(ctx context.Context, deliveryIDs []uint64) error
+	GetDeliveries(ctx context.Context, webhookID uint, status string, limit int) ([]model.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, deliveryID uint64) (model.WebhookDelivery, error)
+}
+
+func (r *repository) CreateWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	log.Printf("Trying to create webhook: %s", webhook.URL)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Create(&webhook).Error
+	})
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("Failed to create webhook: %s. Error: %w", webhook.URL, err)
+	}
+
+	return webhook, nil
+}
+
+func (r *repository) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	webhooks := make([]model.Webhook, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Order("id").Find(&webhooks).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get webhooks. Error: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *repository) GetWebhook(ctx context.Context, webhookID uint) (model.Webhook, error) {
+	var webhook model.Webhook
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		err := db.Where("id = ?", webhookID).First(&webhook).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound("Webhook not found: id %d", webhookID)
+		}
+
+		return err
+	})
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("Failed to get webhook: %d. Error: %w", webhookID, err)
+	}
+
+	return webhook, nil
+}
+
+func (r *repository) DeleteWebhook(ctx context.Context, webhookID uint) error {
+	log.Printf("Trying to delete webhook: %d", webhookID)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Where("id = ?", webhookID).Delete(&model.Webhook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("Webhook not found: id %d", webhookID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete webhook: %d. Error: %w", webhookID, err)
+	}
+
+	return nil
+}
+
+// QueueEvent queues a delivery of the payload for every webhook subscribed
+// to the event and returns their number. A webhook that already has a
+// delivery of the outbox event is skipped, so queueing an event again does
+// not deliver it twice.
+func (r *repository) QueueEvent(ctx context.Context, eventID uint64, event string, payload []byte) (int64, error) {
+	var queued int64
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		result := db.Exec(`insert into webhook_deliveries (webhook_id, event_id, event, payload)
+			select id, ?, ?, ?::jsonb from webhooks where cardinality(events) = 0 or ? = any(events)
+			on conflict (webhook_id, event_id) do nothing`,
+			eventID, event, string(payload), event)
+		queued = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to queue event: %s. Error: %w", event, err)
+	}
+
+	return queued, nil
+}
+
+// ClaimDeliveries takes up to limit due deliveries and counts the attempt.
+// A claimed delivery becomes due again after the lease, so a delivery lost
+// with a crashed replica is retried, and replicas never claim the same one.
+func (r *repository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.PendingDelivery, error) {
+	claimed := make([]model.PendingDelivery, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		rows, err := db.Raw(`update webhook_deliveries d
+			set attempts = d.attempts + 1, next_attempt_at = now() + ? * interval '1 second'
+			from webhooks w
+			where w.id = d.webhook_id and d.id in (
+				select id from webhook_deliveries
+				where status = ? and next_attempt_at <= now()
+				order by next_attempt_at
+				limit ?
+				for update skip locked)
+			returning d.id, d.webhook_id, d.event_id, d.event, d.payload, d.status, d.attempts, d.created_at, w.url, w.secret`,
+			lease.Seconds(), model.DeliveryPending, limit).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var p model.PendingDelivery
+			d := &p.Delivery
+			err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.CreatedAt, &p.URL, &p.Secret)
+			if err != nil {
+				return err
+			}
+			claimed = append(claimed, p)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to claim webhook deliveries. Error: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// UpdateDelivery records the outcome of a delivery attempt.
+func (r *repository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Model(&model.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]any{
+			"status":           delivery.Status,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"delivered_at":     delivery.DeliveredAt,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to update webhook delivery: %d. Error: %w", delivery.ID, err)
+	}
+
+	return nil
+}
+
+// ReleaseDeliveries makes claimed deliveries that were not attempted due
+// again at once and takes back the attempt the claim counted.
+func (r *repository) ReleaseDeliveries(ctx context.Context, deliveryIDs []uint64) error {
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		return db.Exec(`update webhook_deliveries
+			set attempts = attempts - 1, next_attempt_at = now()
+			where id in (?) and status = ?`, deliveryIDs, model.DeliveryPending).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to release webhook deliveries. Error: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeliveries returns the latest deliveries, newest first. A zero webhook
+// id returns the deliveries of every webhook, an empty status of any status.
+func (r *repository) GetDeliveries(ctx context.Context, webhookID uint, status string, limit int) ([]model.WebhookDelivery, error) {
+	deliveries := make([]model.WebhookDelivery, 0)
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		if webhookID != 0 {
+			if err := db.Where("id = ?", webhookID).First(&model.Webhook{}).Error; err != nil {
+				if gorm.IsRecordNotFoundError(err) {
+					return NotFound("Webhook not found: id %d", webhookID)
+				}
+				return err
+			}
+			db = db.Where("webhook_id = ?", webhookID)
+		}
+		if status != "" {
+			db = db.Where("status = ?", status)
+		}
+
+		return db.Order("id desc").Limit(limit).Find(&deliveries).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get deliveries of webhook: %d. Error: %w", webhookID, err)
+	}
+
+	return deliveries, nil
+}
+
+// RetryDelivery puts a dead delivery back into the queue with fresh attempts.
+func (r *repository) RetryDelivery(ctx context.Context, deliveryID uint64) (model.WebhookDelivery, error) {
+	log.Printf("Trying to retry webhook delivery: %d", deliveryID)
+	var delivery model.WebhookDelivery
+	err := r.withContext(ctx, func(db *gorm.DB) error {
+		err := db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", deliveryID).First(&delivery).Error
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound("Webhook delivery not found: id %d", deliveryID)
+		}
+		if err != nil {
+			return err
+		}
+		if delivery.Status != model.DeliveryDead {
+			return Conflict("Webhook delivery %d is %s, only dead deliveries can be retried", deliveryID, delivery.Status)
+		}
+
+		delivery.Status = model.DeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now()
+		return db.Model(&delivery).Updates(map[string]any{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+		}).Error
+	})
+	if err != nil {
+		return model.WebhookDelivery{}, fmt.Errorf("Failed to retry webhook delivery: %d. Error: %w", deliveryID, err)
+	}
+
+	return delivery, nil
+}
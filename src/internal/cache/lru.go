@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Backend stores encoded query results. Entries carry tags and invalidating a
+// tag drops every entry with it. A shared backend lets replicas see each
+// other's invalidations, the in-process LRU only sees its own.
+//
+// Generation is read before loading a result and passed to Set, which skips
+// the result when one of its tags was invalidated since: the load may have
+// read the rows before the write.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Generation(ctx context.Context) uint64
+	Set(ctx context.Context, key string, value []byte, tags []string, generation uint64)
+	Invalidate(ctx context.Context, tags ...string)
+}
+
+// maxInvalidated bounds the tags whose last invalidation the LRU remembers.
+// Past it they are forgotten and every load started before is not stored.
+const maxInvalidated = 10000
+
+// LRU is an in-process Backend. Entries expire after the TTL, the least
+// recently used ones are evicted when the entries exceed the size budget.
+type LRU struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	budget  int64
+	size    int64
+	order   *list.List
+	entries map[string]*list.Element
+	tags    map[string]map[string]struct{}
+	now     func() time.Time
+
+	// generation counts the invalidations, invalidated holds the generation
+	// of the last invalidation of a tag. Sets of loads older than floor are
+	// skipped, invalidated no longer knows their tags.
+	generation  uint64
+	invalidated map[string]uint64
+	floor       uint64
+}
+
+type entry struct {
+	key     string
+	value   []byte
+	tags    []string
+	expires time.Time
+}
+
+func (e *entry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+// NewLRU creates a cache of at most budget bytes of keys and values.
+func NewLRU(budget int64, ttl time.Duration) *LRU {
+	return &LRU{
+		ttl:     ttl,
+		budget:  budget,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		tags:    make(map[string]map[string]struct{}),
+		now:     time.Now,
+
+		invalidated: make(map[string]uint64),
+	}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := element.Value.(*entry)
+	if c.now().After(e.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+
+	return e.value, true
+}
+
+func (c *LRU) Generation(ctx context.Context) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+func (c *LRU) Set(ctx context.Context, key string, value []byte, tags []string, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation < c.floor {
+		return
+	}
+	for _, tag := range tags {
+		if c.invalidated[tag] > generation {
+			return
+		}
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	e := &entry{key: key, value: value, tags: tags, expires: c.now().Add(c.ttl)}
+	if e.size() > c.budget {
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(e)
+	c.size += e.size()
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	for c.size > c.budget {
+		c.remove(c.order.Back())
+		evictions.Add(1)
+	}
+}
+
+func (c *LRU) Invalidate(ctx context.Context, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(c.entries[key])
+		}
+		c.invalidated[tag] = c.generation
+	}
+	if len(c.invalidated) > maxInvalidated {
+		clear(c.invalidated)
+		c.floor = c.generation
+	}
+}
+
+func (c *LRU) remove(element *list.Element) {
+	e := element.Value.(*entry)
+	c.order.Remove(element)
+	delete(c.entries, e.key)
+	c.size -= e.size()
+	for _, tag := range e.tags {
+		delete(c.tags[tag], e.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Package cache serves the library, filter and lyrics queries of the
+// repository from a cache.
+//
+// Results are stored JSON encoded, so a backend can be shared by replicas and
+// callers never share slices. Writes through the decorator invalidate exactly
+// the entries they can change: any change of the songs or their tags drops the
+// library and filter results, only a change of a song drops its lyrics. A
+// result loaded while a write invalidated one of its tags is not stored, it
+// may predate the write. The TTL bounds how stale a result changed elsewhere,
+// e.g. by another replica with an in-process backend, can be.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log"
+	"music/internal/base"
+	"music/internal/dedup"
+	"music/internal/model"
+)
+
+const (
+	// tagSongs marks the results that depend on any song or tag.
+	tagSongs = "songs"
+	// tagLyrics marks every lyrics result, tagLyrics + ":" + key the lyrics
+	// of one song.
+	tagLyrics = "lyrics"
+)
+
+var (
+	hits      = expvar.NewMap("cache_hits")
+	misses    = expvar.NewMap("cache_misses")
+	evictions = expvar.NewInt("cache_evictions")
+)
+
+type repository struct {
+	base.Repository
+	backend Backend
+}
+
+// NewRepository wraps repo, caching its query results in backend.
+func NewRepository(repo base.Repository, backend Backend) base.Repository {
+	return &repository{Repository: repo, backend: backend}
+}
+
+// cached returns the result stored under key, or stores the result of load.
+// load returns the tags of the result beyond tags, those that depend on the
+// loaded rows. Errors are not cached.
+func cached[T any](ctx context.Context, backend Backend, kind, key string, tags []string, load func() (T, []string, error)) (T, error) {
+	if data, ok := backend.Get(ctx, key); ok {
+		var result T
+		if err := json.Unmarshal(data, &result); err == nil {
+			hits.Add(kind, 1)
+			return result, nil
+		}
+	}
+	misses.Add(kind, 1)
+
+	generation := backend.Generation(ctx)
+	result, loaded, err := load()
+	if err != nil {
+		return result, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Failed to cache %s. Error: %s", key, err)
+		return result, nil
+	}
+	backend.Set(ctx, key, data, append(tags, loaded...), generation)
+
+	return result, nil
+}
+
+// rows adapts a load without tags of its own to cached.
+func rows[T any](load func() (T, error)) func() (T, []string, error) {
+	return func() (T, []string, error) {
+		result, err := load()
+		return result, nil, err
+	}
+}
+
+func lyricsTag(group, song string) string {
+	return tagLyrics + ":" + dedup.Key(group, song)
+}
+
+func (r *repository) GetLibrary(ctx context.Context) ([]model.Song, error) {
+	return cached(ctx, r.backend, "library", "library", []string{tagSongs}, rows(func() ([]model.Song, error) {
+		return r.Repository.GetLibrary(ctx)
+	}))
+}
+
+func (r *repository) GetLibraryWithPagination(ctx context.Context, page, size int) ([]model.Song, error) {
+	key := fmt.Sprintf("library:%d:%d", page, size)
+	return cached(ctx, r.backend, "library", key, []string{tagSongs}, rows(func() ([]model.Song, error) {
+		return r.Repository.GetLibraryWithPagination(ctx, page, size)
+	}))
+}
+
+func (r *repository) FindWithFilter(ctx context.Context, filter string) (model.Song, error) {
+	return cached(ctx, r.backend, "filter", "filter:"+filter, []string{tagSongs}, rows(func() (model.Song, error) {
+		return r.Repository.FindWithFilter(ctx, filter)
+	}))
+}
+
+func (r *repository) FindWithFilterAndPagination(ctx context.Context, filter string, page, size int) ([]model.Song, error) {
+	key := fmt.Sprintf("filter:%d:%d:%s", page, size, filter)
+	return cached(ctx, r.backend, "filter", key, []string{tagSongs}, rows(func() ([]model.Song, error) {
+		return r.Repository.FindWithFilterAndPagination(ctx, filter, page, size)
+	}))
+}
+
+// GetLyricsWithPagination tags the result with the stored name of the song as
+// well, writes invalidate the stored name whatever spelling the read used.
+func (r *repository) GetLyricsWithPagination(ctx context.Context, group, song string, page, size int) ([]string, error) {
+	key := fmt.Sprintf("lyrics:%d:%d:%q:%q", page, size, group, song)
+	tags := []string{tagLyrics, lyricsTag(group, song)}
+	return cached(ctx, r.backend, "lyrics", key, tags, func() ([]string, []string, error) {
+		stored := r.storedTag(ctx, group, song)
+		lyrics, err := r.Repository.GetLyricsWithPagination(ctx, group, song, page, size)
+		return lyrics, []string{stored}, err
+	})
+}
+
+// variants is the cached result of GetVariantsByName, LyricsVariant keeps its
+// ids out of JSON.
+type variants struct {
+	Song     model.Song
+	Variants []variant
+}
+
+type variant struct {
+	ID       uint
+	SongID   uint
+	Language string
+	Original bool
+	Text     string
+}
+
+func (r *repository) GetVariantsByName(ctx context.Context, group, song string) (model.Song, []model.LyricsVariant, error) {
+	key := fmt.Sprintf("variants:%q:%q", group, song)
+	tags := []string{tagLyrics, lyricsTag(group, song)}
+	result, err := cached(ctx, r.backend, "lyrics", key, tags, func() (variants, []string, error) {
+		target, found, err := r.Repository.GetVariantsByName(ctx, group, song)
+		if err != nil {
+			return variants{}, nil, err
+		}
+		result := variants{Song: target}
+		for _, v := range found {
+			result.Variants = append(result.Variants, variant(v))
+		}
+		return result, []string{lyricsTag(target.Group_name, target.Song)}, nil
+	})
+	if err != nil {
+		return model.Song{}, nil, err
+	}
+
+	found := make([]model.LyricsVariant, 0, len(result.Variants))
+	for _, v := range result.Variants {
+		found = append(found, model.LyricsVariant(v))
+	}
+	return result.Song, found, nil
+}
+
+func (r *repository) AddSong(ctx context.Context, newSong model.Song) (model.Song, error) {
+	song, err := r.Repository.AddSong(ctx, newSong)
+	if err == nil {
+		// An empty result of the new song may be cached.
+		r.backend.Invalidate(ctx, tagSongs, lyricsTag(song.Group_name, song.Song))
+	}
+
+	return song, err
+}
+
+func (r *repository) UpdateSong(ctx context.Context, group, song string, update model.SongUpdate) error {
+	before := r.storedTag(ctx, group, song)
+	err := r.Repository.UpdateSong(ctx, group, song, update)
+	if err == nil {
+		renamed := model.Song{Group_name: group, Song: song}
+		if update.Group_name != nil {
+			renamed.Group_name = *update.Group_name
+		}
+		if update.Song != nil {
+			renamed.Song = *update.Song
+		}
+		r.backend.Invalidate(ctx, tagSongs, lyricsTag(group, song), before, r.storedTag(ctx, renamed.Group_name, renamed.Song))
+	}
+
+	return err
+}
+
+func (r *repository) DeleteSong(ctx context.Context, group, song string) error {
+	stored := r.storedTag(ctx, group, song)
+	err := r.Repository.DeleteSong(ctx, group, song)
+	if err == nil {
+		r.backend.Invalidate(ctx, tagSongs, lyricsTag(group, song), stored)
+	}
+
+	return err
+}
+
+func (r *repository) ImportSongs(ctx context.Context, songs []model.Song) (model.ImportResult, error) {
+	// A failed import keeps the chunks it committed.
+	result, err := r.Repository.ImportSongs(ctx, songs)
+	if err == nil || result.Imported > 0 {
+		tags := []string{tagSongs}
+		for _, song := range songs {
+			tags = append(tags, lyricsTag(song.Group_name, song.Song))
+		}
+		r.backend.Invalidate(ctx, tags...)
+	}
+
+	return result, err
+}
+
+func (r *repository) Purge(ctx context.Context) (int64, error) {
+	purged, err := r.Repository.Purge(ctx)
+	if err == nil || purged > 0 {
+		r.backend.Invalidate(ctx, tagSongs, tagLyrics)
+	}
+
+	return purged, err
+}
+
+func (r *repository) MergeSongs(ctx context.Context, winnerID uint, loserIDs []uint, fields map[string]uint) (model.Song, error) {
+	tags := r.songTags(ctx, append([]uint{winnerID}, loserIDs...)...)
+	merged, err := r.Repository.MergeSongs(ctx, winnerID, loserIDs, fields)
+	if err == nil {
+		r.backend.Invalidate(ctx, append(tags, lyricsTag(merged.Group_name, merged.Song))...)
+	}
+
+	return merged, err
+}
+
+func (r *repository) SetVariant(ctx context.Context, songID uint, variant model.LyricsVariant) error {
+	tags := r.songTags(ctx, songID)
+	err := r.Repository.SetVariant(ctx, songID, variant)
+	if err == nil {
+		r.backend.Invalidate(ctx, tags...)
+	}
+
+	return err
+}
+
+func (r *repository) DeleteVariant(ctx context.Context, songID uint, language string) error {
+	tags := r.songTags(ctx, songID)
+	err := r.Repository.DeleteVariant(ctx, songID, language)
+	if err == nil {
+		r.backend.Invalidate(ctx, tags...)
+	}
+
+	return err
+}
+
+func (r *repository) SetSyncedLyrics(ctx context.Context, songID uint, lines []model.LyricLine, text string) error {
+	tags := r.songTags(ctx, songID)
+	err := r.Repository.SetSyncedLyrics(ctx, songID, lines, text)
+	if err == nil {
+		r.backend.Invalidate(ctx, tags...)
+	}
+
+	return err
+}
+
+func (r *repository) TagSong(ctx context.Context, songID uint, tag model.Tag) (model.Tag, error) {
+	tagged, err := r.Repository.TagSong(ctx, songID, tag)
+	if err == nil {
+		r.backend.Invalidate(ctx, tagSongs)
+	}
+
+	return tagged, err
+}
+
+func (r *repository) UntagSong(ctx context.Context, songID, tagID uint) error {
+	err := r.Repository.UntagSong(ctx, songID, tagID)
+	if err == nil {
+		r.backend.Invalidate(ctx, tagSongs)
+	}
+
+	return err
+}
+
+func (r *repository) DeleteTag(ctx context.Context, tagID uint) error {
+	err := r.Repository.DeleteTag(ctx, tagID)
+	if err == nil {
+		r.backend.Invalidate(ctx, tagSongs)
+	}
+
+	return err
+}
+
+// BatchSongs invalidates the songs the operations addressed by name and the
+// songs they wrote. An update of a song addressed by id may rename it, its
+// old name is unknown then and every lyrics result is invalidated.
+func (r *repository) BatchSongs(ctx context.Context, ops []model.BatchOperation, atomic bool) ([]base.BatchResult, error) {
+	results, err := r.Repository.BatchSongs(ctx, ops, atomic)
+	if err != nil {
+		return results, err
+	}
+
+	tags := []string{tagSongs}
+	for i, op := range ops {
+		if op.Group != "" {
+			tags = append(tags, lyricsTag(op.Group, op.Song))
+		}
+		if op.Op == model.BatchUpdate && op.ID != 0 && (op.Changes.Group_name != nil || op.Changes.Song != nil) {
+			tags = append(tags, tagLyrics)
+		}
+		for _, song := range results[i].Songs {
+			tags = append(tags, lyricsTag(song.Group_name, song.Song))
+		}
+	}
+	r.backend.Invalidate(ctx, tags...)
+
+	return results, nil
+}
+
+// storedTag returns the lyrics tag of the song stored under the name. The
+// database matches names by music_fold, which folds some spellings the key
+// keeps apart, so reads and writes tag the stored row rather than the
+// spelling of the request. When the song cannot be looked up every lyrics
+// result is invalidated.
+func (r *repository) storedTag(ctx context.Context, group, song string) string {
+	stored, _, err := r.Repository.GetVariantsByName(ctx, group, song)
+	if err != nil {
+		return tagLyrics
+	}
+
+	return lyricsTag(stored.Group_name, stored.Song)
+}
+
+// songTags returns the tags a change of the songs invalidates. The songs are
+// looked up before the change, their names may not survive it. When a song
+// cannot be looked up every lyrics result is invalidated.
+func (r *repository) songTags(ctx context.Context, songIDs ...uint) []string {
+	tags := []string{tagSongs}
+	for _, id := range songIDs {
+		song, err := r.Repository.GetSong(ctx, id)
+		if err != nil {
+			return []string{tagSongs, tagLyrics}
+		}
+		tags = append(tags, lyricsTag(song.Group_name, song.Song))
+	}
+
+	return tags
+}
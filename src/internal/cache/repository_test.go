@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"music/internal/base"
+	"music/internal/model"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// fold stands in for music_fold, which folds "ß" to "ss" unlike dedup.Key.
+func fold(value string) string {
+	return strings.ReplaceAll(strings.ToLower(value), "ß", "ss")
+}
+
+// store is a repository of one song that counts the lyrics queries.
+type store struct {
+	base.Repository
+	song    model.Song
+	queries int
+}
+
+func (r *store) matches(group, song string) bool {
+	return fold(group) == fold(r.song.Group_name) && fold(song) == fold(r.song.Song)
+}
+
+func (r *store) GetLyricsWithPagination(ctx context.Context, group, song string, page, size int) ([]string, error) {
+	r.queries++
+	if !r.matches(group, song) {
+		return []string{}, nil
+	}
+
+	return []string{r.song.Lyrics}, nil
+}
+
+func (r *store) GetVariantsByName(ctx context.Context, group, song string) (model.Song, []model.LyricsVariant, error) {
+	if !r.matches(group, song) {
+		return model.Song{}, nil, base.NotFound("Song not found")
+	}
+
+	return r.song, nil, nil
+}
+
+func (r *store) UpdateSong(ctx context.Context, group, song string, update model.SongUpdate) error {
+	if !r.matches(group, song) {
+		return base.NotFound("Song not found")
+	}
+	if update.Lyrics != nil {
+		r.song.Lyrics = *update.Lyrics
+	}
+
+	return nil
+}
+
+func (r *store) DeleteSong(ctx context.Context, group, song string) error {
+	if !r.matches(group, song) {
+		return base.NotFound("Song not found")
+	}
+	r.song = model.Song{}
+
+	return nil
+}
+
+// errChunk is the failure of a purge after its first chunk.
+var errChunk = errors.New("chunk timed out")
+
+// Purge deletes the song and fails afterwards, like a purge whose second
+// chunk times out.
+func (r *store) Purge(ctx context.Context) (int64, error) {
+	r.song = model.Song{}
+	return 1, errChunk
+}
+
+func TestWriteInvalidatesStoredSpelling(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, repo base.Repository) error
+		want  string
+	}{
+		{
+			name: "update",
+			write: func(ctx context.Context, repo base.Repository) error {
+				lyrics := "new"
+				return repo.UpdateSong(ctx, "die straße", "lied", model.SongUpdate{Lyrics: &lyrics})
+			},
+			want: "new",
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, repo base.Repository) error {
+				return repo.DeleteSong(ctx, "DIE STRAßE", "Lied")
+			},
+			want: "",
+		},
+		{
+			name: "failed purge",
+			write: func(ctx context.Context, repo base.Repository) error {
+				_, err := repo.Purge(ctx)
+				return expectChunkError(err)
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend := &store{song: model.Song{ID: 1, Group_name: "Die Straße", Song: "Lied", Lyrics: "old"}}
+			repo := NewRepository(backend, NewLRU(1<<20, time.Hour))
+
+			// The database finds the song by both spellings, the cache keeps
+			// a result per spelling.
+			spellings := []string{"Die Straße", "DIE STRASSE"}
+			for _, group := range spellings {
+				for range 2 {
+					if _, err := repo.GetLyricsWithPagination(ctx, group, "Lied", 1, 10); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if backend.queries != 2 {
+				t.Fatalf("queries = %d, want the second read of each spelling cached", backend.queries)
+			}
+
+			if err := tt.write(ctx, repo); err != nil {
+				t.Fatal(err)
+			}
+			for i, group := range spellings {
+				lyrics, err := repo.GetLyricsWithPagination(ctx, group, "Lied", 1, 10)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := strings.Join(lyrics, ""); got != tt.want || backend.queries != 3+i {
+					t.Errorf("lyrics of %s = %q after %d queries, want %q read again after the write", group, got, backend.queries, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// expectChunkError accepts the failure of a purge, whose committed chunks
+// still have to invalidate the cache.
+func expectChunkError(err error) error {
+	if !errors.Is(err, errChunk) {
+		return fmt.Errorf("error = %v, want %v", err, errChunk)
+	}
+
+	return nil
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := NewLRU(15, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "a", []byte("12345"), []string{"songs"}, c.Generation(ctx))
+	c.Set(ctx, "b", []byte("12345"), []string{"lyrics"}, c.Generation(ctx))
+	if _, ok := c.Get(ctx, "a"); !ok {
+		t.Fatal("a is not cached")
+	}
+
+	// a was used last, b is evicted to fit c.
+	c.Set(ctx, "c", []byte("12345"), []string{"lyrics"}, c.Generation(ctx))
+	if _, ok := c.Get(ctx, "b"); ok {
+		t.Error("b is cached beyond the budget")
+	}
+	if _, ok := c.Get(ctx, "a"); !ok {
+		t.Error("a is evicted, want the least recently used evicted")
+	}
+
+	c.Invalidate(ctx, "lyrics")
+	if _, ok := c.Get(ctx, "c"); ok {
+		t.Error("c is cached after its tag was invalidated")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get(ctx, "a"); ok {
+		t.Error("a is cached after the TTL")
+	}
+	if c.size != 0 || len(c.tags) != 0 {
+		t.Errorf("size = %d, tags = %v, want an empty cache", c.size, c.tags)
+	}
+
+	c.Set(ctx, "large", make([]byte, 20), nil, c.Generation(ctx))
+	if _, ok := c.Get(ctx, "large"); ok {
+		t.Error("an entry over the budget is cached")
+	}
+}
+
+// TestLRUStaleSet guards against a load that read the rows before a write
+// storing its result after the write invalidated it.
+func TestLRUStaleSet(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(1<<10, time.Minute)
+
+	generation := c.Generation(ctx)
+	c.Invalidate(ctx, "lyrics:a")
+	c.Set(ctx, "stale", []byte("old"), []string{"songs", "lyrics:a"}, generation)
+	if _, ok := c.Get(ctx, "stale"); ok {
+		t.Error("a result loaded before the invalidation of its tag is cached")
+	}
+
+	c.Set(ctx, "other", []byte("old"), []string{"lyrics:b"}, generation)
+	if _, ok := c.Get(ctx, "other"); !ok {
+		t.Error("a result without invalidated tags is not cached")
+	}
+	c.Set(ctx, "fresh", []byte("new"), []string{"lyrics:a"}, c.Generation(ctx))
+	if _, ok := c.Get(ctx, "fresh"); !ok {
+		t.Error("a result loaded after the invalidation is not cached")
+	}
+
+	// Once the invalidations are forgotten, older loads are not stored.
+	generation = c.Generation(ctx)
+	for i := range maxInvalidated + 1 {
+		c.Invalidate(ctx, fmt.Sprintf("lyrics:%d", i))
+	}
+	c.Set(ctx, "forgotten", []byte("old"), []string{"songs"}, generation)
+	if _, ok := c.Get(ctx, "forgotten"); ok {
+		t.Error("a result loaded before forgotten invalidations is cached")
+	}
+}
@@ -1,46 +1,287 @@
 package config
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultQueryTimeout = 5 * time.Second
+	defaultSessionTTL   = 30 * 24 * time.Hour
+	defaultCacheTTL     = 30 * time.Second
+	defaultCacheSize    = 64 << 20
+
+	defaultIdempotencyTTL  = 24 * time.Hour
+	defaultOutboxRetention = 7 * 24 * time.Hour
+
+	// placeholderKeyPrefix marks the API keys of examples, a server does not
+	// start with them.
+	placeholderKeyPrefix = "change-me"
 )
 
 type Config interface {
 	GetConfigSQL() string
 	GetPort() string
+	GetGRPCPort() string
+	GetQueryTimeout() time.Duration
+	GetAPIKeys() map[string]string
+	GetJWKSFile() string
+	GetPublicReads() bool
+	GetRateLimit() (float64, int)
+	GetExpensiveRateLimit() (float64, int)
+	GetSessionTTL() time.Duration
+	GetAutoMigrate() bool
+	GetCache() (time.Duration, int64)
+	GetIdempotencyTTL() time.Duration
+	GetEventBusPrefix() string
+	GetOutboxRetention() time.Duration
 }
 
 type config struct {
-	port        string
-	host        string
-	db_port     string
-	db_user     string
-	db_name     string
-	db_password string
-	db_sslmode  string
+	port          string
+	grpc_port     string
+	host          string
+	db_port       string
+	db_user       string
+	db_name       string
+	db_password   string
+	db_sslmode    string
+	query_timeout time.Duration
+	api_keys      map[string]string
+	jwks_file     string
+	public_reads  bool
+
+	rate_limit_rps             float64
+	rate_limit_burst           int
+	rate_limit_expensive_rps   float64
+	rate_limit_expensive_burst int
+
+	session_ttl time.Duration
+
+	auto_migrate bool
+
+	cache_ttl  time.Duration
+	cache_size int64
+
+	idempotency_ttl time.Duration
+
+	event_bus_prefix string
+	outbox_retention time.Duration
 }
 
 func NewConfig() (Config, error) {
-	cfg := config{}
 	file, err := os.Open("../config/config.env")
 	if err != nil {
 		return nil, fmt.Errorf("Failed to read configuration. Error:%s", err.Error())
 	}
 	defer file.Close()
 
-	fmt.Fscanf(file, "HOST=%s\nPORT=%s\nDB_PORT=%s\nDB_USER=%s\nDB_NAME=%s\nDB_PASSWORD=%s\nDB_SSLMODE=%s", &cfg.host, &cfg.port, &cfg.db_port, &cfg.db_user, &cfg.db_name, &cfg.db_password, &cfg.db_sslmode)
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("Failed to parse configuration line: %s", line)
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read configuration. Error:%s", err.Error())
+	}
+
+	queryTimeout, err := positiveDuration(values, "QUERY_TIMEOUT", defaultQueryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	sessionTTL, err := positiveDuration(values, "SESSION_TTL", defaultSessionTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := parseAPIKeys(values["API_KEYS"])
+	if err != nil {
+		return nil, err
+	}
+
+	publicReads := true
+	if raw, ok := values["PUBLIC_READS"]; ok {
+		publicReads, err = strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid PUBLIC_READS: %s. Error:%s", raw, err.Error())
+		}
+	}
+
+	autoMigrate := true
+	if raw, ok := values["AUTO_MIGRATE"]; ok {
+		autoMigrate, err = strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid AUTO_MIGRATE: %s. Error:%s", raw, err.Error())
+		}
+	}
+
+	cacheTTL, err := nonNegativeDuration(values, "CACHE_TTL", defaultCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	cacheSize := int64(defaultCacheSize)
+	if raw, ok := values["CACHE_SIZE"]; ok {
+		cacheSize, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || cacheSize < 0 {
+			return nil, fmt.Errorf("Invalid CACHE_SIZE: %s", raw)
+		}
+	}
+
+	idempotencyTTL, err := nonNegativeDuration(values, "IDEMPOTENCY_TTL", defaultIdempotencyTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxRetention, err := nonNegativeDuration(values, "OUTBOX_RETENTION", defaultOutboxRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit, err := rate(values, "RATE_LIMIT", 20, 40)
+	if err != nil {
+		return nil, err
+	}
+	expensiveRateLimit, err := rate(values, "RATE_LIMIT_EXPENSIVE", 1, 5)
+	if err != nil {
+		return nil, err
+	}
 
 	return config{
-		port:        cfg.port,
-		host:        cfg.host,
-		db_port:     cfg.db_port,
-		db_user:     cfg.db_user,
-		db_name:     cfg.db_name,
-		db_password: cfg.db_password,
-		db_sslmode:  cfg.db_sslmode,
+		port:          values["PORT"],
+		grpc_port:     values["GRPC_PORT"],
+		host:          values["HOST"],
+		db_port:       values["DB_PORT"],
+		db_user:       values["DB_USER"],
+		db_name:       values["DB_NAME"],
+		db_password:   values["DB_PASSWORD"],
+		db_sslmode:    values["DB_SSLMODE"],
+		query_timeout: queryTimeout,
+		api_keys:      apiKeys,
+		jwks_file:     values["JWKS_FILE"],
+		public_reads:  publicReads,
+
+		rate_limit_rps:             rateLimit.rps,
+		rate_limit_burst:           rateLimit.burst,
+		rate_limit_expensive_rps:   expensiveRateLimit.rps,
+		rate_limit_expensive_burst: expensiveRateLimit.burst,
+
+		session_ttl: sessionTTL,
+
+		auto_migrate: autoMigrate,
+
+		cache_ttl:  cacheTTL,
+		cache_size: cacheSize,
+
+		idempotency_ttl: idempotencyTTL,
+
+		event_bus_prefix: values["EVENT_BUS_PREFIX"],
+		outbox_retention: outboxRetention,
 	}, nil
 }
 
+func duration(values map[string]string, key string, fallback time.Duration) (time.Duration, error) {
+	raw, ok := values[key]
+	if !ok {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s: %s. Error:%s", key, raw, err.Error())
+	}
+
+	return d, nil
+}
+
+// parseAPIKeys parses the comma separated key:role pairs of API_KEYS.
+func parseAPIKeys(raw string) (map[string]string, error) {
+	apiKeys := make(map[string]string)
+	if raw == "" {
+		return apiKeys, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		key, role, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("Invalid API_KEYS entry: %s", pair)
+		}
+		if strings.HasPrefix(key, placeholderKeyPrefix) {
+			return nil, fmt.Errorf("Invalid API_KEYS entry: %s, placeholder keys must be replaced", pair)
+		}
+		apiKeys[key] = role
+	}
+
+	return apiKeys, nil
+}
+
+// positiveDuration reads a duration that has no disabled value, zero or
+// negative ones are rejected.
+func positiveDuration(values map[string]string, key string, fallback time.Duration) (time.Duration, error) {
+	d, err := duration(values, key, fallback)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("Invalid %s: %s, must be positive", key, values[key])
+	}
+
+	return d, nil
+}
+
+// nonNegativeDuration reads a duration whose zero value disables a feature,
+// negative ones are rejected.
+func nonNegativeDuration(values map[string]string, key string, fallback time.Duration) (time.Duration, error) {
+	d, err := duration(values, key, fallback)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("Invalid %s: %s, must not be negative", key, values[key])
+	}
+
+	return d, nil
+}
+
+type rateLimit struct {
+	rps   float64
+	burst int
+}
+
+// rate reads the <prefix>_RPS and <prefix>_BURST pair of a rate limit.
+func rate(values map[string]string, prefix string, rps float64, burst int) (rateLimit, error) {
+	limit := rateLimit{rps: rps, burst: burst}
+
+	if raw, ok := values[prefix+"_RPS"]; ok {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return rateLimit{}, fmt.Errorf("Invalid %s_RPS: %s", prefix, raw)
+		}
+		limit.rps = v
+	}
+
+	if raw, ok := values[prefix+"_BURST"]; ok {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return rateLimit{}, fmt.Errorf("Invalid %s_BURST: %s", prefix, raw)
+		}
+		limit.burst = v
+	}
+
+	return limit, nil
+}
+
 func (c config) GetConfigSQL() string {
 	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s", c.host, c.db_port, c.db_user, c.db_name, c.db_password, c.db_sslmode)
 }
@@ -48,3 +289,71 @@ func (c config) GetConfigSQL() string {
 func (c config) GetPort() string {
 	return fmt.Sprintf(":%s", c.port)
 }
+
+// GetGRPCPort returns the address of the gRPC server, empty when it is
+// disabled.
+func (c config) GetGRPCPort() string {
+	if c.grpc_port == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(":%s", c.grpc_port)
+}
+
+func (c config) GetQueryTimeout() time.Duration {
+	return c.query_timeout
+}
+
+func (c config) GetAPIKeys() map[string]string {
+	return c.api_keys
+}
+
+func (c config) GetJWKSFile() string {
+	return c.jwks_file
+}
+
+func (c config) GetPublicReads() bool {
+	return c.public_reads
+}
+
+func (c config) GetRateLimit() (float64, int) {
+	return c.rate_limit_rps, c.rate_limit_burst
+}
+
+func (c config) GetExpensiveRateLimit() (float64, int) {
+	return c.rate_limit_expensive_rps, c.rate_limit_expensive_burst
+}
+
+func (c config) GetSessionTTL() time.Duration {
+	return c.session_ttl
+}
+
+// GetAutoMigrate reports whether the server applies pending migrations on
+// start. Multi-replica deploys may disable it and run musicctl migrate up.
+func (c config) GetAutoMigrate() bool {
+	return c.auto_migrate
+}
+
+// GetCache returns how long query results are cached and the budget of the
+// cache in bytes. A zero TTL or size disables the cache.
+func (c config) GetCache() (time.Duration, int64) {
+	return c.cache_ttl, c.cache_size
+}
+
+// GetIdempotencyTTL returns how long the responses of requests with an
+// Idempotency-Key are kept for retries. Zero disables idempotency keys.
+func (c config) GetIdempotencyTTL() time.Duration {
+	return c.idempotency_ttl
+}
+
+// GetEventBusPrefix returns the subject prefix the change events are
+// published to the event bus under, empty when the bus is disabled.
+func (c config) GetEventBusPrefix() string {
+	return c.event_bus_prefix
+}
+
+// GetOutboxRetention returns how long published change events are kept in
+// the outbox. Zero keeps them forever.
+func (c config) GetOutboxRetention() time.Duration {
+	return c.outbox_retention
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPositiveDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "missing", values: map[string]string{}, want: 5 * time.Second},
+		{name: "set", values: map[string]string{"QUERY_TIMEOUT": "250ms"}, want: 250 * time.Millisecond},
+		{name: "zero", values: map[string]string{"QUERY_TIMEOUT": "0s"}, wantErr: true},
+		{name: "negative", values: map[string]string{"QUERY_TIMEOUT": "-1s"}, wantErr: true},
+		{name: "malformed", values: map[string]string{"QUERY_TIMEOUT": "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := positiveDuration(tt.values, "QUERY_TIMEOUT", 5*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("positiveDuration() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("positiveDuration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNonNegativeDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "missing", values: map[string]string{}, want: time.Minute},
+		{name: "set", values: map[string]string{"CACHE_TTL": "30s"}, want: 30 * time.Second},
+		{name: "disabled", values: map[string]string{"CACHE_TTL": "0s"}, want: 0},
+		{name: "negative", values: map[string]string{"CACHE_TTL": "-1s"}, wantErr: true},
+		{name: "malformed", values: map[string]string{"CACHE_TTL": "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nonNegativeDuration(tt.values, "CACHE_TTL", time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nonNegativeDuration() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("nonNegativeDuration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    map[string]string
+		wantRPS   float64
+		wantBurst int
+		wantErr   bool
+	}{
+		{name: "defaults", values: map[string]string{}, wantRPS: 20, wantBurst: 40},
+		{name: "set", values: map[string]string{"RATE_LIMIT_RPS": "2.5", "RATE_LIMIT_BURST": "3"}, wantRPS: 2.5, wantBurst: 3},
+		{name: "zero rps", values: map[string]string{"RATE_LIMIT_RPS": "0"}, wantErr: true},
+		{name: "zero burst", values: map[string]string{"RATE_LIMIT_BURST": "0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rate(tt.values, "RATE_LIMIT", 20, 40)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got.rps != tt.wantRPS || got.burst != tt.wantBurst {
+				t.Errorf("rate() = %v/%d, want %v/%d", got.rps, got.burst, tt.wantRPS, tt.wantBurst)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: map[string]string{}},
+		{name: "pairs", raw: "a1:admin, b2:editor", want: map[string]string{"a1": "admin", "b2": "editor"}},
+		{name: "missing role", raw: "a1", wantErr: true},
+		{name: "missing key", raw: ":admin", wantErr: true},
+		{name: "placeholder", raw: "a1:reader,change-me-admin:admin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAPIKeys(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAPIKeys() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseAPIKeys() = %v, want %v", got, tt.want)
+			}
+			for key, role := range tt.want {
+				if got[key] != role {
+					t.Errorf("parseAPIKeys()[%s] = %s, want %s", key, got[key], role)
+				}
+			}
+		})
+	}
+}
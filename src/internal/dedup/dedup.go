@@ -0,0 +1,139 @@
+// Package dedup finds songs that were added to the library more than once
+// under slightly different names.
+package dedup
+
+import (
+	"music/internal/lyrics"
+	"music/internal/model"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	ReasonName   = "name"
+	ReasonLyrics = "lyrics"
+)
+
+// Cluster is a set of songs that are probably the same song.
+type Cluster struct {
+	Key     string       `json:"key"`
+	Reasons []string     `json:"reasons"`
+	Songs   []model.Song `json:"songs"`
+}
+
+// featuring matches a credit in brackets, "Song (feat. X) [Live]", or at the
+// end of the title after a dash, "Song - ft X", or after an abbreviation,
+// "Song feat. X". A bare word as in "Feat of Strength" is part of the title.
+var featuring = regexp.MustCompile(`(?i)\s*[\(\[]\s*(feat|ft|featuring)\b\.?\s[^\)\]]*[\)\]]?` +
+	`|\s+-\s+(feat|ft|featuring)\b\.?\s.*$` +
+	`|\s+(feat\.|ft\.|featuring\s)\s*\S.*$`)
+
+// Normalize folds case and diacritics, drops "feat." credits and
+// punctuation, so "Song (feat. X)" and " song" have the same key.
+func Normalize(value string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), value)
+	if err != nil {
+		folded = value
+	}
+	folded = featuring.ReplaceAllString(strings.ToLower(folded), "")
+
+	return strings.Join(strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Key identifies a song by its normalized group and name.
+func Key(group, song string) string {
+	return Normalize(group) + " / " + Normalize(song)
+}
+
+// Find clusters songs with the same key, and songs of the same group whose
+// lyrics are at least threshold similar.
+func Find(songs []model.Song, threshold float64) []Cluster {
+	parent := make([]int, len(songs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make(map[int]map[string]bool)
+	union := func(i, j int, reason string) {
+		a, b := root(i), root(j)
+		if a != b {
+			parent[b] = a
+			for r := range reasons[b] {
+				mark(reasons, a, r)
+			}
+			delete(reasons, b)
+		}
+		mark(reasons, a, reason)
+	}
+
+	byKey := make(map[string]int)
+	byGroup := make(map[string][]int)
+	for i, song := range songs {
+		key := Key(song.Group_name, song.Song)
+		if first, ok := byKey[key]; ok {
+			union(first, i, ReasonName)
+		} else {
+			byKey[key] = i
+		}
+		group := Normalize(song.Group_name)
+		byGroup[group] = append(byGroup[group], i)
+	}
+
+	// Lyrics are only compared within a group to keep the work quadratic in
+	// the size of a discography rather than of the library.
+	for _, members := range byGroup {
+		for x, i := range members {
+			for _, j := range members[x+1:] {
+				if root(i) == root(j) || songs[i].Lyrics == "" || songs[j].Lyrics == "" {
+					continue
+				}
+				if lyrics.Similarity(songs[i].Lyrics, songs[j].Lyrics) >= threshold {
+					union(i, j, ReasonLyrics)
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]model.Song)
+	for i, song := range songs {
+		members[root(i)] = append(members[root(i)], song)
+	}
+	clusters := make([]Cluster, 0)
+	for r, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		cluster := Cluster{Key: Key(group[0].Group_name, group[0].Song), Songs: group}
+		for reason := range reasons[r] {
+			cluster.Reasons = append(cluster.Reasons, reason)
+		}
+		sort.Strings(cluster.Reasons)
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Songs[0].ID < clusters[j].Songs[0].ID
+	})
+
+	return clusters
+}
+
+func mark(reasons map[int]map[string]bool, i int, reason string) {
+	if reasons[i] == nil {
+		reasons[i] = make(map[string]bool)
+	}
+	reasons[i][reason] = true
+}
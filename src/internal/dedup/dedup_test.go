@@ -0,0 +1,99 @@
+package dedup
+
+import (
+	"music/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: " Song ", want: "song"},
+		{value: "Sóng Ñame", want: "song name"},
+		{value: "Song (feat. Artist)", want: "song"},
+		{value: "Song [ft. Artist]", want: "song"},
+		{value: "Song (Featuring Artist) [Live]", want: "song live"},
+		{value: "Song - feat Artist", want: "song"},
+		{value: "Song feat. Artist & Other", want: "song"},
+		{value: "Song ft. Artist", want: "song"},
+		{value: "Song featuring Artist", want: "song"},
+		{value: "Feat of Strength", want: "feat of strength"},
+		{value: "Left Feat Right", want: "left feat right"},
+		{value: "Aftermath", want: "aftermath"},
+		{value: "Don't Stop (Live)", want: "don t stop live"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := Normalize(tt.value); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got, want := Key("Muse ", "Hysteria (feat. Nobody)"), "muse / hysteria"; got != want {
+		t.Errorf("Key() = %q, want %q", got, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	lyrics := "Its bugging me grating me and twisting me around yeah Im endlessly caving in"
+	tests := []struct {
+		name  string
+		songs []model.Song
+		want  [][]uint
+		why   [][]string
+	}{
+		{
+			name: "same name",
+			songs: []model.Song{
+				{ID: 1, Group_name: "Muse", Song: "Hysteria"},
+				{ID: 2, Group_name: "muse", Song: "Hysteria (feat. Someone)"},
+				{ID: 3, Group_name: "Muse", Song: "Starlight"},
+			},
+			want: [][]uint{{1, 2}},
+			why:  [][]string{{ReasonName}},
+		},
+		{
+			name: "same lyrics in a group",
+			songs: []model.Song{
+				{ID: 1, Group_name: "Muse", Song: "Hysteria", Lyrics: lyrics},
+				{ID: 2, Group_name: "Muse", Song: "Hysteria Live", Lyrics: lyrics + " yeah"},
+				{ID: 3, Group_name: "Other", Song: "Cover", Lyrics: lyrics},
+			},
+			want: [][]uint{{1, 2}},
+			why:  [][]string{{ReasonLyrics}},
+		},
+		{
+			name: "titles with a bare feat are different songs",
+			songs: []model.Song{
+				{ID: 1, Group_name: "Band", Song: "Feat of Strength"},
+				{ID: 2, Group_name: "Band", Song: "Feat of Clay"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters := Find(tt.songs, 0.8)
+			var got [][]uint
+			var why [][]string
+			for _, cluster := range clusters {
+				var ids []uint
+				for _, song := range cluster.Songs {
+					ids = append(ids, song.ID)
+				}
+				got = append(got, ids)
+				why = append(why, cluster.Reasons)
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(why, tt.why) {
+				t.Errorf("Find() = %v %v, want %v %v", got, why, tt.want, tt.why)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package dto
+
+import (
+	"fmt"
+	"music/internal/base"
+	"music/internal/model"
+)
+
+// Modes of a batch.
+const (
+	BatchAtomic  = "atomic"
+	BatchPerItem = "per_item"
+)
+
+// BatchRequest is a list of song writes. An atomic batch, the default, is
+// applied all or nothing, a per_item batch applies every operation on its
+// own.
+type BatchRequest struct {
+	Mode       string                  `json:"mode" validate:"omitempty,oneof=atomic per_item"`
+	Operations []BatchOperationRequest `json:"operations" validate:"required,min=1,max=500"`
+}
+
+// BatchOperationRequest is a create, update or delete of a batch. Updates and
+// deletes address songs by id or by group and song, values holds the new
+// song of a create and the changed fields of an update.
+type BatchOperationRequest struct {
+	Op     string             `json:"op" validate:"required,oneof=create update delete"`
+	ID     uint               `json:"id"`
+	Group  string             `json:"group" validate:"max=255"`
+	Song   string             `json:"song" validate:"max=255"`
+	Values *SongUpdateRequest `json:"values" validate:"-"`
+}
+
+func (b *BatchRequest) Normalize() {
+	for i := range b.Operations {
+		b.Operations[i].Normalize()
+	}
+}
+
+func (b *BatchRequest) Validate() error {
+	if err := validate(b); err != nil {
+		return err
+	}
+
+	var violations []base.Violation
+	for i := range b.Operations {
+		prefixed, err := prefixViolations(b.Operations[i].Validate(), fmt.Sprintf("operations[%d].", i))
+		if err != nil {
+			return err
+		}
+		violations = append(violations, prefixed...)
+	}
+	if len(violations) > 0 {
+		return base.Invalid(violations)
+	}
+
+	return nil
+}
+
+func (b BatchRequest) Atomic() bool {
+	return b.Mode != BatchPerItem
+}
+
+func (b BatchRequest) Model() []model.BatchOperation {
+	ops := make([]model.BatchOperation, 0, len(b.Operations))
+	for _, op := range b.Operations {
+		ops = append(ops, op.Model())
+	}
+
+	return ops
+}
+
+func (o *BatchOperationRequest) Normalize() {
+	o.Group = normalize(o.Group)
+	o.Song = normalize(o.Song)
+	if o.Values != nil {
+		o.Values.Normalize()
+	}
+}
+
+func (o *BatchOperationRequest) Validate() error {
+	if err := validate(o); err != nil {
+		return err
+	}
+
+	var violations []base.Violation
+	violate := func(field, message string) {
+		violations = append(violations, base.Violation{Field: field, Message: message})
+	}
+	byName := o.Group != "" || o.Song != ""
+	switch {
+	case o.Op == model.BatchCreate && (o.ID != 0 || byName):
+		violate("id", "must be empty, a new song is described by values")
+	case o.Op != model.BatchCreate && o.ID != 0 && byName:
+		violate("id", "must not be combined with group and song")
+	case o.Op != model.BatchCreate && o.ID == 0 && (o.Group == "" || o.Song == ""):
+		violate("id", "or group and song are required")
+	}
+
+	var values error
+	switch {
+	case o.Op == model.BatchDelete && o.Values != nil:
+		violate("values", "must be empty")
+	case o.Values == nil || o.Values.Empty():
+		if o.Op != model.BatchDelete {
+			violate("values", "is required")
+		}
+	case o.Op == model.BatchCreate:
+		song := o.Values.SongRequest()
+		values = song.Validate()
+	default:
+		values = o.Values.Validate()
+	}
+	prefixed, err := prefixViolations(values, "values.")
+	if err != nil {
+		return err
+	}
+	violations = append(violations, prefixed...)
+
+	if len(violations) > 0 {
+		return base.Invalid(violations)
+	}
+
+	return nil
+}
+
+func (o BatchOperationRequest) Model() model.BatchOperation {
+	op := model.BatchOperation{Op: o.Op, ID: o.ID, Group: o.Group, Song: o.Song}
+	switch {
+	case o.Values == nil:
+	case o.Op == model.BatchCreate:
+		op.Values = o.Values.SongRequest().Model()
+	default:
+		op.Changes = o.Values.Model()
+	}
+
+	return op
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"music/internal/base"
+	"reflect"
+	"testing"
+)
+
+func TestBatchOperationRequestValidate(t *testing.T) {
+	values := &SongUpdateRequest{Group: ptr("Muse"), Song: ptr("Hysteria")}
+	tests := []struct {
+		name string
+		op   BatchOperationRequest
+		want []base.Violation
+	}{
+		{name: "create", op: BatchOperationRequest{Op: "create", Values: values}},
+		{name: "update by id", op: BatchOperationRequest{Op: "update", ID: 1, Values: &SongUpdateRequest{Album: ptr("Absolution")}}},
+		{name: "delete by name", op: BatchOperationRequest{Op: "delete", Group: "Muse", Song: "Hysteria"}},
+		{
+			name: "unknown op",
+			op:   BatchOperationRequest{Op: "upsert"},
+			want: []base.Violation{{Field: "op", Message: "must be one of: create update delete"}},
+		},
+		{
+			name: "create with id",
+			op:   BatchOperationRequest{Op: "create", ID: 1, Values: values},
+			want: []base.Violation{{Field: "id", Message: "must be empty, a new song is described by values"}},
+		},
+		{
+			name: "create without required values",
+			op:   BatchOperationRequest{Op: "create", Values: &SongUpdateRequest{Song: ptr("Hysteria")}},
+			want: []base.Violation{{Field: "values.group", Message: "is required"}},
+		},
+		{
+			name: "update by id and name",
+			op:   BatchOperationRequest{Op: "update", ID: 1, Group: "Muse", Song: "Hysteria", Values: values},
+			want: []base.Violation{{Field: "id", Message: "must not be combined with group and song"}},
+		},
+		{
+			name: "update without values",
+			op:   BatchOperationRequest{Op: "update", Group: "Muse", Song: "Hysteria", Values: &SongUpdateRequest{}},
+			want: []base.Violation{{Field: "values", Message: "is required"}},
+		},
+		{
+			name: "delete by group only",
+			op:   BatchOperationRequest{Op: "delete", Group: "Muse", Values: values},
+			want: []base.Violation{
+				{Field: "id", Message: "or group and song are required"},
+				{Field: "values", Message: "must be empty"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violations(t, tt.op.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchRequestValidate(t *testing.T) {
+	batch := BatchRequest{Operations: []BatchOperationRequest{
+		{Op: "delete", ID: 1},
+		{Op: "update", ID: 2, Values: &SongUpdateRequest{ReleaseDate: ptr("yesterday")}},
+	}}
+	want := []base.Violation{{Field: "operations[1].values.release_date", Message: "must be a date in YYYY-MM-DD format"}}
+	if got := violations(t, batch.Validate()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() violations = %v, want %v", got, want)
+	}
+	if !batch.Atomic() {
+		t.Error("Atomic() of the default mode = false, want true")
+	}
+
+	empty := BatchRequest{Mode: BatchPerItem}
+	want = []base.Violation{{Field: "operations", Message: "is required"}}
+	if got := violations(t, empty.Validate()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() of no operations violations = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "net/url"
+
+// SongFilter is the typed form of the /music/filter query for the GraphQL
+// and gRPC APIs. Every list matches any of its values.
+type SongFilter struct {
+	Group       []string
+	Song        []string
+	Album       []string
+	ReleaseDate []string
+	Tags        []string
+	TagMode     string
+	Sort        string
+}
+
+// Query encodes the filter as the query string the repository accepts.
+func (f SongFilter) Query() string {
+	values := url.Values{}
+	for key, list := range map[string][]string{
+		"group":        f.Group,
+		"song":         f.Song,
+		"album":        f.Album,
+		"release_date": f.ReleaseDate,
+		"tag":          f.Tags,
+	} {
+		for _, value := range list {
+			values.Add(key, value)
+		}
+	}
+	if f.TagMode != "" {
+		values.Set("tag_mode", f.TagMode)
+	}
+	if f.Sort != "" {
+		values.Set("sort", f.Sort)
+	}
+
+	return values.Encode()
+}
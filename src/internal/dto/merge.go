@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"music/internal/base"
+	"slices"
+)
+
+// MergeRequest merges the losers into the winner. Fields picks, by field
+// name, the song whose value the survivor keeps. Other fields keep the value
+// of the winner, or of the first loser when the winner has none.
+type MergeRequest struct {
+	Winner uint            `json:"winner" validate:"required"`
+	Losers []uint          `json:"losers" validate:"required,min=1,max=100,unique,dive,required"`
+	Fields map[string]uint `json:"fields" validate:"omitempty,dive,keys,oneof=group song album release_date text,endkeys,required"`
+}
+
+func (m *MergeRequest) Normalize() {}
+
+func (m *MergeRequest) Validate() error {
+	if err := validate(m); err != nil {
+		return err
+	}
+
+	var violations []base.Violation
+	if slices.Contains(m.Losers, m.Winner) {
+		violations = append(violations, base.Violation{Field: "losers", Message: "must not contain the winner"})
+	}
+	for field, id := range m.Fields {
+		if id != m.Winner && !slices.Contains(m.Losers, id) {
+			violations = append(violations, base.Violation{Field: "fields[" + field + "]", Message: "must be the winner or one of the losers"})
+		}
+	}
+	if len(violations) > 0 {
+		return base.Invalid(violations)
+	}
+
+	return nil
+}
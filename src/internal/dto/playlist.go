@@ -0,0 +1,83 @@
+package dto
+
+import "music/internal/model"
+
+type PlaylistRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=4096"`
+	Public      bool   `json:"public"`
+}
+
+func (p *PlaylistRequest) Normalize() {
+	p.Name = normalize(p.Name)
+	p.Description = normalizeText(p.Description)
+}
+
+func (p *PlaylistRequest) Validate() error {
+	return validate(p)
+}
+
+func (p PlaylistRequest) Model(ownerID uint) model.Playlist {
+	return model.Playlist{
+		OwnerID:     ownerID,
+		Name:        p.Name,
+		Description: p.Description,
+		Public:      p.Public,
+	}
+}
+
+// PlaylistUpdateRequest changes only the fields present in the payload.
+type PlaylistUpdateRequest struct {
+	Name        *string `json:"name" validate:"omitnil,min=1,max=255"`
+	Description *string `json:"description" validate:"omitnil,max=4096"`
+	Public      *bool   `json:"public"`
+}
+
+func (p *PlaylistUpdateRequest) Normalize() {
+	normalizePtr(p.Name, normalize)
+	normalizePtr(p.Description, normalizeText)
+}
+
+func (p *PlaylistUpdateRequest) Validate() error {
+	return validate(p)
+}
+
+// Fields returns the columns to update. Unlike struct updates it keeps
+// false and empty values.
+func (p PlaylistUpdateRequest) Fields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	if p.Name != nil {
+		fields["name"] = *p.Name
+	}
+	if p.Description != nil {
+		fields["description"] = *p.Description
+	}
+	if p.Public != nil {
+		fields["public"] = *p.Public
+	}
+
+	return fields
+}
+
+// EntryRequest adds a song to a playlist. Without a position the song is
+// appended.
+type EntryRequest struct {
+	SongID   uint `json:"song_id" validate:"required"`
+	Position *int `json:"position" validate:"omitnil,min=0"`
+}
+
+func (e *EntryRequest) Normalize() {}
+
+func (e *EntryRequest) Validate() error {
+	return validate(e)
+}
+
+type MoveRequest struct {
+	Position *int `json:"position" validate:"required,min=0"`
+}
+
+func (m *MoveRequest) Normalize() {}
+
+func (m *MoveRequest) Validate() error {
+	return validate(m)
+}
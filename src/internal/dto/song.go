@@ -0,0 +1,135 @@
+package dto
+
+import (
+	"fmt"
+	"music/internal/base"
+	"music/internal/model"
+)
+
+// SongRequest is the payload of a new song. Name lengths follow the columns
+// of the songs table.
+type SongRequest struct {
+	Group       string `json:"group" validate:"required,max=255"`
+	Song        string `json:"song" validate:"required,max=255"`
+	Album       string `json:"album" validate:"max=255"`
+	ReleaseDate string `json:"release_date" validate:"omitempty,release_date"`
+	Text        string `json:"text" validate:"max=65536"`
+}
+
+// SongUpdateRequest is the payload of a song update. Omitted fields keep
+// their current values, present fields must be valid.
+type SongUpdateRequest struct {
+	Group       *string `json:"group" validate:"omitnil,min=1,max=255"`
+	Song        *string `json:"song" validate:"omitnil,min=1,max=255"`
+	Album       *string `json:"album" validate:"omitnil,max=255"`
+	ReleaseDate *string `json:"release_date" validate:"omitnil,release_date"`
+	Text        *string `json:"text" validate:"omitnil,max=65536"`
+}
+
+func (s *SongRequest) Normalize() {
+	s.Group = normalize(s.Group)
+	s.Song = normalize(s.Song)
+	s.Album = normalize(s.Album)
+	s.ReleaseDate = normalize(s.ReleaseDate)
+	s.Text = normalizeText(s.Text)
+}
+
+func (s *SongRequest) Validate() error {
+	return validate(s)
+}
+
+func (s SongRequest) Model() model.Song {
+	return model.Song{
+		Group_name:  s.Group,
+		Song:        s.Song,
+		Album:       s.Album,
+		ReleaseDate: s.ReleaseDate,
+		Lyrics:      s.Text,
+	}
+}
+
+func (s *SongUpdateRequest) Normalize() {
+	normalizePtr(s.Group, normalize)
+	normalizePtr(s.Song, normalize)
+	normalizePtr(s.Album, normalize)
+	normalizePtr(s.ReleaseDate, normalize)
+	normalizePtr(s.Text, normalizeText)
+}
+
+// Validate rejects an update without fields, it would change nothing.
+func (s *SongUpdateRequest) Validate() error {
+	if s.Empty() {
+		return base.Validation("Update has no fields, expected at least one of group, song, album, release_date and text")
+	}
+
+	return validate(s)
+}
+
+// Empty reports whether the update changes nothing.
+func (s SongUpdateRequest) Empty() bool {
+	return s.Group == nil && s.Song == nil && s.Album == nil && s.ReleaseDate == nil && s.Text == nil
+}
+
+// SongRequest returns the fields present in the payload as a new song.
+func (s SongUpdateRequest) SongRequest() SongRequest {
+	value := func(field *string) string {
+		if field == nil {
+			return ""
+		}
+		return *field
+	}
+
+	return SongRequest{
+		Group:       value(s.Group),
+		Song:        value(s.Song),
+		Album:       value(s.Album),
+		ReleaseDate: value(s.ReleaseDate),
+		Text:        value(s.Text),
+	}
+}
+
+func (s SongUpdateRequest) Model() model.SongUpdate {
+	return model.SongUpdate{
+		Group_name:  s.Group,
+		Song:        s.Song,
+		Album:       s.Album,
+		ReleaseDate: s.ReleaseDate,
+		Lyrics:      s.Text,
+	}
+}
+
+// SongImport is a list of new songs. Violations are reported with the index
+// of the offending song.
+type SongImport []SongRequest
+
+func (s SongImport) Normalize() {
+	for i := range s {
+		s[i].Normalize()
+	}
+}
+
+func (s SongImport) Validate() error {
+	var violations []base.Violation
+	for i := range s {
+		prefixed, err := prefixViolations(s[i].Validate(), fmt.Sprintf("[%d].", i))
+		if err != nil {
+			return err
+		}
+		violations = append(violations, prefixed...)
+	}
+
+	if len(violations) > 0 {
+		return base.Invalid(violations)
+	}
+
+	return nil
+}
+
+func (s SongImport) Model() []model.Song {
+	songs := make([]model.Song, 0, len(s))
+	for _, song := range s {
+		songs = append(songs, song.Model())
+	}
+
+	return songs
+}
@@ -0,0 +1,162 @@
+package dto
+
+import (
+	"errors"
+	"music/internal/base"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// violations returns the violations of a validation error, nil for no error.
+func violations(t *testing.T, err error) []base.Violation {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var invalid *base.Error
+	if !errors.As(err, &invalid) || invalid.Kind != base.KindValidation {
+		t.Fatalf("error = %v, want a validation error", err)
+	}
+
+	return invalid.Violations
+}
+
+func ptr(value string) *string {
+	return &value
+}
+
+func TestSongRequestNormalize(t *testing.T) {
+	song := SongRequest{
+		Group:       "  Beyoncé ",
+		Song:        "Halo\t",
+		Album:       " I Am... ",
+		ReleaseDate: " 2008-11-12 ",
+		Text:        "\r\nFirst line\r\n\r\n  indented\r\n\n",
+	}
+	song.Normalize()
+
+	want := SongRequest{
+		Group:       "Beyoncé",
+		Song:        "Halo",
+		Album:       "I Am...",
+		ReleaseDate: "2008-11-12",
+		Text:        "First line\n\n  indented",
+	}
+	if song != want {
+		t.Errorf("Normalize() = %+v, want %+v", song, want)
+	}
+}
+
+func TestSongRequestValidate(t *testing.T) {
+	valid := SongRequest{Group: "Muse", Song: "Hysteria", ReleaseDate: "2003-12-1"}
+	tests := []struct {
+		name   string
+		modify func(s *SongRequest)
+		want   []base.Violation
+	}{
+		{name: "valid", modify: func(s *SongRequest) {}},
+		{name: "no release date", modify: func(s *SongRequest) { s.ReleaseDate = "" }},
+		{
+			name:   "required",
+			modify: func(s *SongRequest) { s.Group, s.Song = "", "" },
+			want:   []base.Violation{{Field: "group", Message: "is required"}, {Field: "song", Message: "is required"}},
+		},
+		{
+			name:   "too long",
+			modify: func(s *SongRequest) { s.Album = strings.Repeat("a", 256) },
+			want:   []base.Violation{{Field: "album", Message: "must be at most 255 characters long"}},
+		},
+		{
+			name:   "release date",
+			modify: func(s *SongRequest) { s.ReleaseDate = "03.12.2003" },
+			want:   []base.Violation{{Field: "release_date", Message: "must be a date in YYYY-MM-DD format"}},
+		},
+		{
+			name:   "impossible date",
+			modify: func(s *SongRequest) { s.ReleaseDate = "2003-02-30" },
+			want:   []base.Violation{{Field: "release_date", Message: "must be a date in YYYY-MM-DD format"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			song := valid
+			tt.modify(&song)
+			if got := violations(t, song.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSongUpdateRequestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update SongUpdateRequest
+		want   []base.Violation
+	}{
+		{name: "album cleared", update: SongUpdateRequest{Album: ptr("")}},
+		{
+			name:   "name cleared",
+			update: SongUpdateRequest{Group: ptr(""), Song: ptr("Hysteria")},
+			want:   []base.Violation{{Field: "group", Message: "must be at least 1 characters long"}},
+		},
+		{
+			name:   "release date",
+			update: SongUpdateRequest{ReleaseDate: ptr("")},
+			want:   []base.Violation{{Field: "release_date", Message: "must be a date in YYYY-MM-DD format"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violations(t, tt.update.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSongUpdateRequestEmpty guards against an update that changes nothing
+// but still records an event.
+func TestSongUpdateRequestEmpty(t *testing.T) {
+	var update SongUpdateRequest
+	err := update.Validate()
+	var invalid *base.Error
+	if !errors.As(err, &invalid) || invalid.Kind != base.KindValidation {
+		t.Errorf("Validate() = %v, want a validation error", err)
+	}
+}
+
+func TestSongUpdateRequestModel(t *testing.T) {
+	update := SongUpdateRequest{Song: ptr("Hysteria"), Text: ptr("It's bugging me")}
+	if update.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+
+	song := update.Model()
+	if song.Group_name != nil || song.Album != nil || *song.Song != "Hysteria" || *song.Lyrics != "It's bugging me" {
+		t.Errorf("Model() = %+v, want only the song and lyrics", song)
+	}
+	if !(SongUpdateRequest{}).Empty() {
+		t.Error("Empty() of no fields = false, want true")
+	}
+}
+
+func TestSongImportValidate(t *testing.T) {
+	songs := SongImport{
+		{Group: "Muse", Song: "Hysteria"},
+		{Group: "Muse"},
+		{Song: "Yesterday", ReleaseDate: "1965"},
+	}
+
+	want := []base.Violation{
+		{Field: "[1].song", Message: "is required"},
+		{Field: "[2].group", Message: "is required"},
+		{Field: "[2].release_date", Message: "must be a date in YYYY-MM-DD format"},
+	}
+	if got := violations(t, songs.Validate()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() violations = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"music/internal/model"
+	"strings"
+)
+
+type TagRequest struct {
+	Name string `json:"name" validate:"required,max=64"`
+	Kind string `json:"kind" validate:"required,oneof=genre mood language other"`
+}
+
+// Normalize lowercases the tag, so "Rock" and "rock" are the same tag.
+func (t *TagRequest) Normalize() {
+	t.Name = strings.ToLower(normalize(t.Name))
+	t.Kind = strings.ToLower(normalize(t.Kind))
+}
+
+func (t *TagRequest) Validate() error {
+	return validate(t)
+}
+
+func (t TagRequest) Model() model.Tag {
+	return model.Tag{Name: t.Name, Kind: t.Kind}
+}
@@ -0,0 +1,27 @@
+package dto
+
+// Credentials are the login and password of a user.
+type Credentials struct {
+	Login    string `json:"login" validate:"required,min=3,max=64"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+func (c *Credentials) Normalize() {
+	c.Login = normalize(c.Login)
+}
+
+func (c *Credentials) Validate() error {
+	return validate(c)
+}
+
+type ListRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+func (l *ListRequest) Normalize() {
+	l.Name = normalize(l.Name)
+}
+
+func (l *ListRequest) Validate() error {
+	return validate(l)
+}
@@ -0,0 +1,121 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"music/internal/base"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ReleaseDateLayout accepts both "2022-03-03" and "2022-3-3".
+const ReleaseDateLayout = "2006-1-2"
+
+var validate = newValidator()
+
+func newValidator() func(any) error {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+	v.RegisterValidation("release_date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(ReleaseDateLayout, fl.Field().String())
+		return err == nil
+	})
+
+	return func(s any) error {
+		err := v.Struct(s)
+		if err == nil {
+			return nil
+		}
+
+		var fieldErrors validator.ValidationErrors
+		if !errors.As(err, &fieldErrors) {
+			return err
+		}
+
+		violations := make([]base.Violation, 0, len(fieldErrors))
+		for _, fe := range fieldErrors {
+			violations = append(violations, base.Violation{
+				Field:   fe.Field(),
+				Message: message(fe),
+			})
+		}
+
+		return base.Invalid(violations)
+	}
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "unique":
+		return "must not contain duplicates"
+	case "release_date":
+		return "must be a date in YYYY-MM-DD format"
+	case "http_url":
+		return "must be an http or https URL"
+	}
+
+	return fmt.Sprintf("failed the %s check", fe.Tag())
+}
+
+// prefixViolations returns the violations of a validation error with the
+// prefix added to their fields. Other errors are returned as is.
+func prefixViolations(err error, prefix string) ([]base.Violation, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var invalid *base.Error
+	if !errors.As(err, &invalid) {
+		return nil, err
+	}
+	violations := make([]base.Violation, 0, len(invalid.Violations))
+	for _, v := range invalid.Violations {
+		violations = append(violations, base.Violation{Field: prefix + v.Field, Message: v.Message})
+	}
+
+	return violations, nil
+}
+
+// normalize trims the value and brings it to the NFC form, so the same name
+// typed on different keyboards is stored identically.
+func normalize(value string) string {
+	return strings.TrimSpace(norm.NFC.String(value))
+}
+
+// normalizeText keeps the inner line structure of lyrics and only unifies
+// line endings.
+func normalizeText(value string) string {
+	value = strings.ReplaceAll(norm.NFC.String(value), "\r\n", "\n")
+	return strings.Trim(value, "\n\t ")
+}
+
+func normalizePtr(value *string, fn func(string) string) {
+	if value != nil {
+		*value = fn(*value)
+	}
+}
@@ -0,0 +1,14 @@
+package dto
+
+type VariantRequest struct {
+	Text     string `json:"text" validate:"required,max=65536"`
+	Original bool   `json:"original"`
+}
+
+func (v *VariantRequest) Normalize() {
+	v.Text = normalizeText(v.Text)
+}
+
+func (v *VariantRequest) Validate() error {
+	return validate(v)
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"music/internal/model"
+	"strings"
+)
+
+// WebhookRequest subscribes a URL to events, an empty list subscribes it to
+// every event. A secret is generated when none is given.
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2048"`
+	Secret string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	Events []string `json:"events" validate:"max=10,unique,dive,oneof=song.created song.updated song.deleted"`
+}
+
+func (w *WebhookRequest) Normalize() {
+	w.URL = strings.TrimSpace(w.URL)
+	for i, event := range w.Events {
+		w.Events[i] = strings.ToLower(strings.TrimSpace(event))
+	}
+}
+
+func (w *WebhookRequest) Validate() error {
+	return validate(w)
+}
+
+func (w WebhookRequest) Model() model.Webhook {
+	events := w.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	return model.Webhook{URL: w.URL, Secret: w.Secret, Events: events}
+}
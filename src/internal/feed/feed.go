@@ -0,0 +1,175 @@
+// Package feed fans the published change events out to live subscribers,
+// keeping the latest events so a reconnecting subscriber can resume.
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"music/internal/model"
+	"music/internal/outbox"
+	"slices"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how far a subscriber may fall behind. A subscriber that
+// falls further is dropped and resumes from the buffered events on reconnect.
+const subscriberBuffer = 64
+
+// Event is a change event as sent to subscribers. Seq is its position in the
+// stream of the hub and the ID a subscriber resumes from, ID is the outbox
+// event and Data its envelope.
+type Event struct {
+	Seq   uint64
+	ID    uint64
+	Type  string
+	Group string
+	Data  []byte
+}
+
+// Reset is the type of the event that tells a resuming subscriber the
+// buffer no longer reaches back to its last event, so it has to reload.
+const Reset = "reset"
+
+// Hub is an outbox sink. It sees the events published by the dispatcher of
+// this instance only: the replicas claim the outbox events one at a time, so
+// with several replicas a subscriber misses the events the others publish.
+//
+// The outbox IDs are assigned when the events are inserted, not when they
+// commit, and an event is published again after a failure or a crash, so
+// they are not a resume position. The hub numbers the events in the order it
+// broadcasts them and does not send an event it still buffers again. The
+// numbering starts at the creation time of the hub in microseconds, so the
+// position of a subscriber of a previous run is behind the buffer.
+type Hub struct {
+	mu     sync.Mutex
+	size   int
+	seq    uint64
+	buffer []Event
+	held   map[uint64]struct{}
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+type Subscription struct {
+	events chan Event
+	hub    *Hub
+}
+
+// NewHub keeps the latest size events for resuming.
+func NewHub(size int) *Hub {
+	return &Hub{
+		size:   size,
+		seq:    uint64(time.Now().UnixMicro()),
+		buffer: make([]Event, 0, size),
+		held:   make(map[uint64]struct{}, size),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+func (h *Hub) Name() string {
+	return "feed"
+}
+
+func (h *Hub) Publish(ctx context.Context, event model.OutboxEvent) error {
+	data, err := json.Marshal(outbox.NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+	var song struct {
+		Group string `json:"group"`
+	}
+	json.Unmarshal(event.Payload, &song)
+
+	h.broadcast(Event{ID: event.ID, Type: event.Event, Group: song.Group, Data: data})
+	return nil
+}
+
+func (h *Hub) broadcast(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if _, ok := h.held[event.ID]; ok {
+		return
+	}
+
+	h.seq++
+	event.Seq = h.seq
+	if len(h.buffer) == h.size {
+		delete(h.held, h.buffer[0].ID)
+		h.buffer = slices.Delete(h.buffer, 0, 1)
+	}
+	h.buffer = append(h.buffer, event)
+	h.held[event.ID] = struct{}{}
+
+	for sub := range h.subs {
+		select {
+		case sub.events <- event:
+		default:
+			log.Println("Dropping event subscriber that fell behind")
+			delete(h.subs, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe returns the buffered events after lastSeq and a subscription to
+// the following ones. missed reports that the buffer does not reach back to
+// lastSeq, events between it and the backlog are lost. The subscription of a
+// closed hub has no events.
+func (h *Hub) Subscribe(lastSeq uint64) (backlog []Event, missed bool, sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &Subscription{events: make(chan Event, subscriberBuffer), hub: h}
+	if h.closed {
+		close(sub.events)
+		return nil, false, sub
+	}
+	h.subs[sub] = struct{}{}
+
+	if lastSeq == 0 {
+		return nil, false, sub
+	}
+	// A position ahead of the hub is of another run.
+	oldest := h.seq + 1 - uint64(len(h.buffer))
+	if lastSeq > h.seq || lastSeq+1 < oldest {
+		return slices.Clone(h.buffer), true, sub
+	}
+	backlog = slices.Clone(h.buffer[lastSeq+1-oldest:])
+
+	return backlog, false, sub
+}
+
+// Events is closed when the hub is closed or the subscriber fell behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s]; ok {
+		delete(s.hub.subs, s)
+		close(s.events)
+	}
+}
+
+// Close ends every subscription, so the streams return before the server
+// shuts down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,161 @@
+package feed
+
+import (
+	"context"
+	"io"
+	"log"
+	"music/internal/model"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// publish broadcasts events with the IDs.
+func publish(h *Hub, ids ...uint64) {
+	for _, id := range ids {
+		h.broadcast(Event{ID: id, Type: model.SongCreated})
+	}
+}
+
+func ids(events []Event) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	return ids
+}
+
+// TestSubscribeBacklog numbers the events of the hub from 1, published holds
+// their outbox IDs.
+func TestSubscribeBacklog(t *testing.T) {
+	tests := []struct {
+		name       string
+		published  []uint64
+		lastSeq    uint64
+		wantIDs    []uint64
+		wantMissed bool
+	}{
+		{name: "new subscriber", published: []uint64{1, 2, 3}, lastSeq: 0, wantIDs: []uint64{}},
+		{name: "resume", published: []uint64{1, 2, 3}, lastSeq: 1, wantIDs: []uint64{2, 3}},
+		{name: "up to date", published: []uint64{1, 2, 3}, lastSeq: 3, wantIDs: []uint64{}},
+		{name: "ahead of the hub", published: []uint64{1, 2, 3}, lastSeq: 10, wantIDs: []uint64{1, 2, 3}, wantMissed: true},
+		{name: "gaps in ids", published: []uint64{2, 5, 9}, lastSeq: 1, wantIDs: []uint64{5, 9}},
+		{name: "at the oldest buffered", published: []uint64{1, 2, 3, 4, 5, 6}, lastSeq: 3, wantIDs: []uint64{4, 5, 6}},
+		{name: "just before the oldest buffered", published: []uint64{1, 2, 3, 4, 5, 6}, lastSeq: 2, wantIDs: []uint64{3, 4, 5, 6}},
+		{name: "behind the ring", published: []uint64{1, 2, 3, 4, 5, 6}, lastSeq: 1, wantIDs: []uint64{3, 4, 5, 6}, wantMissed: true},
+		{name: "empty ring", lastSeq: 7, wantIDs: []uint64{}, wantMissed: true},
+		{name: "committed out of order", published: []uint64{1, 3, 2, 4}, lastSeq: 2, wantIDs: []uint64{2, 4}},
+		{name: "published again", published: []uint64{1, 2, 1, 3, 2}, lastSeq: 1, wantIDs: []uint64{2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(4)
+			h.seq = 0
+			publish(h, tt.published...)
+
+			backlog, missed, sub := h.Subscribe(tt.lastSeq)
+			defer sub.Close()
+			if got := ids(backlog); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("backlog = %v, want %v", got, tt.wantIDs)
+			}
+			if missed != tt.wantMissed {
+				t.Errorf("missed = %v, want %v", missed, tt.wantMissed)
+			}
+		})
+	}
+}
+
+// TestSubscribeRestarted checks that a subscriber of the previous run of the
+// server is told it missed events.
+func TestSubscribeRestarted(t *testing.T) {
+	previous := NewHub(4)
+	publish(previous, 1, 2)
+	_, _, sub := previous.Subscribe(0)
+	defer sub.Close()
+	publish(previous, 3)
+	last := (<-sub.Events()).Seq
+
+	time.Sleep(time.Millisecond)
+	h := NewHub(4)
+	publish(h, 4)
+	backlog, missed, resumed := h.Subscribe(last)
+	defer resumed.Close()
+	if got := ids(backlog); !missed || !slices.Equal(got, []uint64{4}) {
+		t.Errorf("backlog = %v, missed = %v, want [4] missed", got, missed)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	h := NewHub(4)
+	_, _, sub := h.Subscribe(0)
+	defer sub.Close()
+
+	event := model.OutboxEvent{ID: 3, Event: model.SongUpdated, Payload: model.RawJSON(`{"ID":1,"group":"Muse","song":"Hysteria"}`)}
+	if err := h.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	got := <-sub.Events()
+	if got.ID != 3 || got.Type != model.SongUpdated || got.Group != "Muse" {
+		t.Errorf("event = %+v, want song.updated 3 of Muse", got)
+	}
+}
+
+// TestPublishedAgain checks that live subscribers get an event the outbox
+// publishes again only once.
+func TestPublishedAgain(t *testing.T) {
+	h := NewHub(4)
+	_, _, sub := h.Subscribe(0)
+	publish(h, 1, 2, 1)
+	h.Close()
+
+	var got []uint64
+	for event := range sub.Events() {
+		got = append(got, event.ID)
+	}
+	if want := []uint64{1, 2}; !slices.Equal(got, want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	h := NewHub(4)
+	_, _, slow := h.Subscribe(0)
+	for id := range uint64(subscriberBuffer + 1) {
+		publish(h, id+1)
+	}
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("received %d events before the drop, want %d", received, subscriberBuffer)
+	}
+	slow.Close()
+}
+
+func TestClose(t *testing.T) {
+	h := NewHub(4)
+	_, _, sub := h.Subscribe(0)
+
+	h.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Error("subscription is open after Close")
+	}
+	sub.Close()
+
+	publish(h, 1)
+	backlog, _, late := h.Subscribe(0)
+	if _, ok := <-late.Events(); ok || backlog != nil {
+		t.Error("subscription of a closed hub is open")
+	}
+}
@@ -0,0 +1,113 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"music/internal/base"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+)
+
+const maxQuerySize = 1 << 20
+
+// Request is a GraphQL request as sent by GraphiQL and most clients.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Handler struct {
+	schema graphql.Schema
+	repo   base.Repository
+}
+
+func NewHandler(repo base.Repository) (*Handler, error) {
+	schema, err := newSchema(repo)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to build GraphQL schema. Error: %w", err)
+	}
+
+	return &Handler{schema: schema, repo: repo}, nil
+}
+
+// ServeHTTP executes a query from a GET query string, a JSON body or an
+// application/graphql body. The schema only has queries, so GET is safe.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := readRequest(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeErrors(w, status, err)
+		return
+	}
+	if request.Query == "" {
+		writeErrors(w, http.StatusBadRequest, errors.New("Query is required"))
+		return
+	}
+	if err := checkLimits(h.schema, request); err != nil {
+		writeErrors(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders(r.Context(), h.repo))
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  request.Query,
+		OperationName:  request.OperationName,
+		VariableValues: request.Variables,
+		Context:        ctx,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func readRequest(w http.ResponseWriter, r *http.Request) (Request, error) {
+	var request Request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return Request{}, fmt.Errorf("Invalid variables: %s", err.Error())
+			}
+		}
+		return request, nil
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxQuerySize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/graphql" {
+		body, err := io.ReadAll(r.Body)
+		request.Query = string(body)
+		return request, err
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return Request{}, err
+		}
+		return Request{}, fmt.Errorf("Invalid JSON body: %s", err.Error())
+	}
+
+	return request, nil
+}
+
+func writeErrors(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"message": err.Error()}},
+	})
+}
@@ -0,0 +1,173 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// maxDepth bounds the nesting of fields, e.g. artists → songs → artist
+	// → albums → songs is 5 levels deep.
+	maxDepth = 8
+	// maxComplexity bounds the estimated number of resolved fields. A paged
+	// list counts as many items as its size, a list without a size such as
+	// the tags of a song as unsizedItems.
+	maxComplexity = 20000
+	unsizedItems  = 10
+)
+
+// limiter estimates the cost of a query before it runs. Introspection
+// fields are free, they never reach the repository.
+type limiter struct {
+	schema    graphql.Schema
+	variables map[string]any
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// checkLimits rejects queries that nest deeper than maxDepth or fan out to
+// more than maxComplexity fields. A query that does not parse is left to
+// graphql.Do, which reports the syntax error.
+func checkLimits(schema graphql.Schema, request Request) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return nil
+	}
+
+	l := limiter{schema: schema, variables: request.Variables, fragments: make(map[string]*ast.FragmentDefinition)}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			l.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok || operation.Operation != ast.OperationTypeQuery {
+			continue
+		}
+		if request.OperationName != "" && (operation.Name == nil || operation.Name.Value != request.OperationName) {
+			continue
+		}
+
+		depth, complexity := l.selections(schema.QueryType(), operation.SelectionSet, 1, map[string]bool{})
+		if depth > maxDepth {
+			return fmt.Errorf("Query is too deep: %d levels, at most %d", depth, maxDepth)
+		}
+		if complexity > maxComplexity {
+			return fmt.Errorf("Query is too complex: about %d fields, at most %d", complexity, maxComplexity)
+		}
+	}
+
+	return nil
+}
+
+// selections returns the depth and the complexity of a selection set of the
+// object at the level. Fragments are followed once per path, so cyclic
+// fragments cannot recurse forever.
+func (l limiter) selections(object *graphql.Object, set *ast.SelectionSet, level int, visited map[string]bool) (int, int) {
+	if set == nil || object == nil {
+		return 0, 0
+	}
+	if level > maxDepth {
+		return level, 0
+	}
+
+	depth, complexity := 0, 0
+	add := func(d, c int) {
+		depth = max(depth, d)
+		complexity += c
+	}
+	for _, selection := range set.Selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			add(l.field(object, s, level, visited))
+		case *ast.InlineFragment:
+			target := object
+			if s.TypeCondition != nil {
+				target, _ = l.schema.Type(s.TypeCondition.Name.Value).(*graphql.Object)
+			}
+			add(l.selections(target, s.SelectionSet, level, visited))
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			fragment, ok := l.fragments[name]
+			if !ok || visited[name] {
+				continue
+			}
+			visited[name] = true
+			target, _ := l.schema.Type(fragment.TypeCondition.Name.Value).(*graphql.Object)
+			add(l.selections(target, fragment.SelectionSet, level, visited))
+			delete(visited, name)
+		}
+	}
+
+	return depth, complexity
+}
+
+func (l limiter) field(object *graphql.Object, field *ast.Field, level int, visited map[string]bool) (int, int) {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return 0, 0
+	}
+	definition, ok := object.Fields()[name]
+	if !ok {
+		// Unknown fields fail validation in graphql.Do.
+		return 0, 0
+	}
+
+	items := 1
+	var child *graphql.Object
+	for t := graphql.Type(definition.Type); t != nil; {
+		switch typed := t.(type) {
+		case *graphql.NonNull:
+			t = typed.OfType
+		case *graphql.List:
+			items = l.size(definition, field)
+			t = typed.OfType
+		case *graphql.Object:
+			child = typed
+			t = nil
+		default:
+			t = nil
+		}
+	}
+	if child == nil {
+		return level, items
+	}
+
+	depth, complexity := l.selections(child, field.SelectionSet, level+1, visited)
+	return max(level, depth), items * (1 + complexity)
+}
+
+// size is the number of items a list field is expected to return.
+func (l limiter) size(definition *graphql.FieldDefinition, field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "size" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if size, err := strconv.Atoi(value.Value); err == nil && size > 0 {
+				return size
+			}
+		case *ast.Variable:
+			switch size := l.variables[value.Name.Value].(type) {
+			case float64:
+				return max(int(size), 1)
+			case int:
+				return max(size, 1)
+			}
+		}
+	}
+	for _, argument := range definition.Args {
+		if size, ok := argument.DefaultValue.(int); ok && argument.PrivateName == "size" {
+			return size
+		}
+	}
+
+	return unsizedItems
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheckLimits(t *testing.T) {
+	schema, err := newSchema(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		request   Request
+		wantError string
+	}{
+		{name: "simple", request: Request{Query: `{ songs { id group song tags { name } } }`}},
+		{name: "syntax error is left to execution", request: Request{Query: `{ songs { id `}},
+		{
+			name:    "introspection",
+			request: Request{Query: `{ __schema { types { fields { type { ofType { ofType { ofType { ofType { ofType { name } } } } } } } } } }`},
+		},
+		{
+			name:    "default sizes",
+			request: Request{Query: `{ artists { songs { id tags { name } artist { name } } } }`},
+		},
+		{
+			name:      "too deep",
+			request:   Request{Query: `{ artist(name: "Muse") { songs { artist { songs { artist { songs { artist { songs { artist { name } } } } } } } } } }`},
+			wantError: "Query is too deep: 9 levels, at most 8",
+		},
+		{
+			name:      "too complex",
+			request:   Request{Query: `{ artists(size: 100) { songs(size: 100) { id group } } }`},
+			wantError: "Query is too complex: about 30100 fields, at most 20000",
+		},
+		{
+			name:      "size from variables",
+			request:   Request{Query: `query Q($n: Int) { artists(size: $n) { songs(size: $n) { id } } }`, Variables: map[string]any{"n": float64(100)}},
+			wantError: "Query is too complex",
+		},
+		{
+			name:    "small size from variables",
+			request: Request{Query: `query Q($n: Int) { artists(size: $n) { songs(size: $n) { id } } }`, Variables: map[string]any{"n": float64(5)}},
+		},
+		{
+			name:      "fragments",
+			request:   Request{Query: `fragment S on Artist { songs(size: 100) { id } } { artists(size: 100) { ...S } }`},
+			wantError: "Query is too complex",
+		},
+		{
+			name:      "inline fragments",
+			request:   Request{Query: `{ artists(size: 100) { ... on Artist { songs(size: 100) { id } } } }`},
+			wantError: "Query is too complex",
+		},
+		{
+			name:    "cyclic fragments",
+			request: Request{Query: `fragment A on Song { id ...B } fragment B on Song { song ...A } { songs { ...A } }`},
+		},
+		{
+			name: "other operation",
+			request: Request{
+				Query:         `query Big { artists(size: 100) { songs(size: 100) { id } } } query Small { songs { id } }`,
+				OperationName: "Small",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLimits(schema, tt.request)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("checkLimits() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("checkLimits() error = %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestHandlerRejectsExpensiveQueries(t *testing.T) {
+	handler, err := NewHandler(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := url.Values{"query": {`{ artists(size: 100) { songs(size: 100) { id } } }`}}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil))
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Query is too complex") {
+		t.Errorf("response = %d %s, want 400 with the complexity error", w.Code, w.Body.String())
+	}
+}
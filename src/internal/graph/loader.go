@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"context"
+	"music/internal/base"
+	"music/internal/model"
+	"sync"
+)
+
+// loader batches the loads of one level of a query into a single repository
+// call. Resolvers register their key with load and return the thunk.
+// graphql-go runs thunks after the resolvers of the level, so the first
+// thunk fetches every key registered so far. A failed fetch fails the thunks
+// of every key of its batch.
+type loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	loaded  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, queued: make(map[K]bool), loaded: make(map[K]V), errs: make(map[K]error)}
+}
+
+func (l *loader[K, V]) load(key K) func() (V, error) {
+	l.mu.Lock()
+	if _, ok := l.loaded[key]; !ok && !l.queued[key] && l.errs[key] == nil {
+		l.pending = append(l.pending, key)
+		l.queued[key] = true
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if value, ok := l.loaded[key]; ok {
+			return value, nil
+		}
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			clear(l.queued)
+			values, err := l.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+				} else {
+					l.loaded[k] = values[k]
+				}
+			}
+		}
+		if err := l.errs[key]; err != nil {
+			var zero V
+			return zero, err
+		}
+
+		return l.loaded[key], nil
+	}
+}
+
+// prime stores a value that is already known, such as the songs of an artist
+// fetched by the artist query.
+func (l *loader[K, V]) prime(key K, value V) {
+	l.mu.Lock()
+	l.loaded[key] = value
+	l.mu.Unlock()
+}
+
+// loaders are created per request, so batches never mix users or outlive
+// the request.
+type loaders struct {
+	tags     *loader[uint, []model.Tag]
+	variants *loader[uint, []model.LyricsVariant]
+	songs    *loader[string, []model.Song]
+}
+
+type loadersKey struct{}
+
+func newLoaders(ctx context.Context, repo base.Repository) *loaders {
+	return &loaders{
+		tags: newLoader(func(ids []uint) (map[uint][]model.Tag, error) {
+			return repo.GetTagsOfSongs(ctx, ids)
+		}),
+		variants: newLoader(func(ids []uint) (map[uint][]model.LyricsVariant, error) {
+			return repo.GetVariantsOfSongs(ctx, ids)
+		}),
+		songs: newLoader(func(groups []string) (map[string][]model.Song, error) {
+			return repo.GetSongsOfArtists(ctx, groups)
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
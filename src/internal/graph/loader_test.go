@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLoaderBatches(t *testing.T) {
+	var batches [][]int
+	l := newLoader(func(keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		values := make(map[int]string)
+		for _, key := range keys {
+			if key != 0 {
+				values[key] = string(rune('a' + key - 1))
+			}
+		}
+		return values, nil
+	})
+
+	l.prime(3, "primed")
+	thunks := []func() (string, error){l.load(1), l.load(2), l.load(1), l.load(3), l.load(0)}
+	var got []string
+	for _, thunk := range thunks {
+		value, err := thunk()
+		if err != nil {
+			t.Fatalf("thunk error = %v", err)
+		}
+		got = append(got, value)
+	}
+
+	if want := []string{"a", "b", "a", "primed", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values = %q, want %q", got, want)
+	}
+	if want := [][]int{{1, 2, 0}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+
+	// Loaded keys are not fetched again, new keys make a new batch.
+	if value, _ := l.load(2)(); value != "b" {
+		t.Errorf("cached value = %q, want b", value)
+	}
+	if value, _ := l.load(4)(); value != "d" {
+		t.Errorf("value = %q, want d", value)
+	}
+	if want := [][]int{{1, 2, 0}, {4}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	failure := errors.New("database is unavailable")
+	l := newLoader(func(keys []int) (map[int]string, error) {
+		return nil, failure
+	})
+
+	if _, err := l.load(1)(); !errors.Is(err, failure) {
+		t.Errorf("thunk error = %v, want %v", err, failure)
+	}
+}
+
+// TestLoaderBatchError guards against the other keys of a failed batch
+// resolving to empty values.
+func TestLoaderBatchError(t *testing.T) {
+	failure := errors.New("database is unavailable")
+	fetches := 0
+	l := newLoader(func(keys []int) (map[int]string, error) {
+		fetches++
+		return nil, failure
+	})
+
+	thunks := []func() (string, error){l.load(1), l.load(2), l.load(3)}
+	for i, thunk := range thunks {
+		if value, err := thunk(); !errors.Is(err, failure) || value != "" {
+			t.Errorf("thunk %d = %q, %v, want the batch error", i, value, err)
+		}
+	}
+	if _, err := l.load(2)(); !errors.Is(err, failure) {
+		t.Errorf("reloaded thunk error = %v, want %v", err, failure)
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+}
@@ -0,0 +1,488 @@
+// Package graph serves the catalog over GraphQL, backed by the same
+// repository as the REST handlers.
+package graph
+
+import (
+	"errors"
+	"log"
+	"music/internal/base"
+	"music/internal/dto"
+	"music/internal/lyrics"
+	"music/internal/model"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultSize = 20
+	maxSize     = 100
+)
+
+// album is a derived entity: the songs of a group sharing the album name.
+type album struct {
+	Name   string
+	Artist string
+}
+
+type resolver struct {
+	repo base.Repository
+}
+
+func newSchema(repo base.Repository) (graphql.Schema, error) {
+	r := resolver{repo: repo}
+
+	tagType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tag",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"kind": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	var songType, artistType, albumType *graphql.Object
+	pageArgs := graphql.FieldConfigArgument{
+		"page": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"size": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultSize},
+	}
+	sortArgs := graphql.FieldConfigArgument{
+		"sort": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "group, song, album or release_date, descending with a leading -",
+		},
+		"page": pageArgs["page"],
+		"size": pageArgs["size"],
+	}
+
+	songType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Song",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: songField(func(s model.Song) any { return s.ID })},
+				"group":       &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: songField(func(s model.Song) any { return s.Group_name })},
+				"song":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: songField(func(s model.Song) any { return s.Song })},
+				"releaseDate": &graphql.Field{Type: graphql.String, Resolve: songField(func(s model.Song) any { return s.ReleaseDate })},
+				"text": &graphql.Field{
+					Type:        graphql.String,
+					Description: "Original lyrics",
+					Resolve:     songField(func(s model.Song) any { return s.Lyrics }),
+				},
+				"lyrics": &graphql.Field{
+					Type:        graphql.String,
+					Description: "Lyrics in the language, falling back to the original",
+					Args: graphql.FieldConfigArgument{
+						"lang": &graphql.ArgumentConfig{Type: graphql.String, Description: "BCP-47 tag or Accept-Language value"},
+					},
+					Resolve: r.songLyrics,
+				},
+				"tags":   &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))), Resolve: r.songTags},
+				"artist": &graphql.Field{Type: graphql.NewNonNull(artistType), Resolve: r.songArtist},
+				"album":  &graphql.Field{Type: albumType, Resolve: r.songAlbum},
+			}
+		}),
+	})
+
+	albumType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Album",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: albumField(func(a album) any { return a.Name })},
+				"artist": &graphql.Field{Type: graphql.NewNonNull(artistType), Resolve: r.albumArtist},
+				"songs":  &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(songType))), Resolve: r.albumSongs},
+			}
+		}),
+	})
+
+	artistType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Artist",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name":       &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: artistField(func(a model.Artist) any { return a.Name })},
+				"songCount":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: artistField(func(a model.Artist) any { return a.Songs })},
+				"albumCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: artistField(func(a model.Artist) any { return a.Albums })},
+				"albums":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(albumType))), Resolve: r.artistAlbums},
+				"songs": &graphql.Field{
+					Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(songType))),
+					Args:    sortArgs,
+					Resolve: r.artistSongs,
+				},
+			}
+		}),
+	})
+
+	tagMode := graphql.NewEnum(graphql.EnumConfig{
+		Name: "TagMode",
+		Values: graphql.EnumValueConfigMap{
+			"ALL": &graphql.EnumValueConfig{Value: "all", Description: "Songs with all of the tags"},
+			"ANY": &graphql.EnumValueConfig{Value: "any", Description: "Songs with any of the tags"},
+		},
+	})
+	names := graphql.NewList(graphql.NewNonNull(graphql.String))
+	songFilter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "SongFilter",
+		Description: "Mirrors the query of /music/filter, every list matches any of its values",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"group":       &graphql.InputObjectFieldConfig{Type: names},
+			"song":        &graphql.InputObjectFieldConfig{Type: names},
+			"album":       &graphql.InputObjectFieldConfig{Type: names},
+			"releaseDate": &graphql.InputObjectFieldConfig{Type: names},
+			"tags":        &graphql.InputObjectFieldConfig{Type: names},
+			"tagMode":     &graphql.InputObjectFieldConfig{Type: tagMode},
+		},
+	})
+
+	songsArgs := graphql.FieldConfigArgument{
+		"filter": &graphql.ArgumentConfig{Type: songFilter},
+		"sort":   sortArgs["sort"],
+		"page":   pageArgs["page"],
+		"size":   pageArgs["size"],
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"song": &graphql.Field{
+				Type:    songType,
+				Args:    graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: r.song,
+			},
+			"songs": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(songType))),
+				Args:    songsArgs,
+				Resolve: r.songs,
+			},
+			"artist": &graphql.Field{
+				Type:    artistType,
+				Args:    graphql.FieldConfigArgument{"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: r.artist,
+			},
+			"artists": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(artistType))),
+				Args:    pageArgs,
+				Resolve: r.artists,
+			},
+			"lyrics": &graphql.Field{
+				Type: graphql.String,
+				Args: graphql.FieldConfigArgument{
+					"group": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"song":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"lang":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.lyrics,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func songField(get func(model.Song) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(model.Song)), nil
+	}
+}
+
+func albumField(get func(album) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(album)), nil
+	}
+}
+
+func artistField(get func(model.Artist) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(model.Artist)), nil
+	}
+}
+
+// clientError keeps the message of domain errors and hides anything else,
+// like the problem responses of the REST API.
+func clientError(err error) error {
+	var domain *base.Error
+	if errors.As(err, &domain) {
+		return errors.New(domain.Message)
+	}
+
+	log.Println(err)
+	return errors.New("Internal server error")
+}
+
+func page(p graphql.ResolveParams) (int, int, error) {
+	number, _ := p.Args["page"].(int)
+	size, _ := p.Args["size"].(int)
+	if number < 1 {
+		return 0, 0, errors.New("page must be at least 1")
+	}
+	if size < 1 || size > maxSize {
+		return 0, 0, errors.New("size must be from 1 to 100")
+	}
+
+	return number, size, nil
+}
+
+func (r resolver) song(p graphql.ResolveParams) (any, error) {
+	id, _ := p.Args["id"].(int)
+	if id < 1 {
+		return nil, nil
+	}
+
+	song, err := r.repo.GetSong(p.Context, uint(id))
+	if errors.Is(err, base.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	return song, nil
+}
+
+// songs builds the same filter string as /music/filter, so both APIs accept
+// and reject the same filters.
+func (r resolver) songs(p graphql.ResolveParams) (any, error) {
+	number, size, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter dto.SongFilter
+	if args, ok := p.Args["filter"].(map[string]any); ok {
+		filter = dto.SongFilter{
+			Group:       list(args["group"]),
+			Song:        list(args["song"]),
+			Album:       list(args["album"]),
+			ReleaseDate: list(args["releaseDate"]),
+			Tags:        list(args["tags"]),
+		}
+		filter.TagMode, _ = args["tagMode"].(string)
+	}
+	filter.Sort, _ = p.Args["sort"].(string)
+
+	songs, err := r.repo.FindWithFilterAndPagination(p.Context, filter.Query(), number, size)
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	return songs, nil
+}
+
+func list(value any) []string {
+	values, _ := value.([]any)
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.(string))
+	}
+
+	return result
+}
+
+func (r resolver) artist(p graphql.ResolveParams) (any, error) {
+	songs, err := r.repo.GetArtistSongs(p.Context, p.Args["name"].(string))
+	if errors.Is(err, base.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	artist := artistOf(songs[0].Group_name, songs)
+	loadersFrom(p.Context).songs.prime(artist.Name, songs)
+	return artist, nil
+}
+
+func (r resolver) artists(p graphql.ResolveParams) (any, error) {
+	number, size, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+
+	artists, err := r.repo.GetArtists(p.Context, number, size)
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	return artists, nil
+}
+
+func (r resolver) lyrics(p graphql.ResolveParams) (any, error) {
+	song, variants, err := r.repo.GetVariantsByName(p.Context, p.Args["group"].(string), p.Args["song"].(string))
+	if errors.Is(err, base.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	lang, _ := p.Args["lang"].(string)
+	return pickLyrics(song, variants, lang), nil
+}
+
+func (r resolver) songLyrics(p graphql.ResolveParams) (any, error) {
+	song := p.Source.(model.Song)
+	lang, _ := p.Args["lang"].(string)
+	if lang == "" {
+		return song.Lyrics, nil
+	}
+
+	thunk := loadersFrom(p.Context).variants.load(song.ID)
+	return func() (any, error) {
+		variants, err := thunk()
+		if err != nil {
+			return nil, clientError(err)
+		}
+		return pickLyrics(song, variants, lang), nil
+	}, nil
+}
+
+// pickLyrics returns the variant in the language, or the original lyrics.
+func pickLyrics(song model.Song, variants []model.LyricsVariant, lang string) string {
+	languages := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		languages = append(languages, variant.Language)
+	}
+	if i, ok := lyrics.Match(languages, lang); ok {
+		return variants[i].Text
+	}
+
+	return song.Lyrics
+}
+
+func (r resolver) songTags(p graphql.ResolveParams) (any, error) {
+	thunk := loadersFrom(p.Context).tags.load(p.Source.(model.Song).ID)
+	return func() (any, error) {
+		tags, err := thunk()
+		if err != nil {
+			return nil, clientError(err)
+		}
+		if tags == nil {
+			tags = []model.Tag{}
+		}
+		return tags, nil
+	}, nil
+}
+
+func (r resolver) songArtist(p graphql.ResolveParams) (any, error) {
+	return r.artistByName(p, p.Source.(model.Song).Group_name), nil
+}
+
+func (r resolver) albumArtist(p graphql.ResolveParams) (any, error) {
+	return r.artistByName(p, p.Source.(album).Artist), nil
+}
+
+func (r resolver) artistByName(p graphql.ResolveParams, name string) func() (any, error) {
+	thunk := loadersFrom(p.Context).songs.load(name)
+	return func() (any, error) {
+		songs, err := thunk()
+		if err != nil {
+			return nil, clientError(err)
+		}
+		return artistOf(name, songs), nil
+	}
+}
+
+func (r resolver) songAlbum(p graphql.ResolveParams) (any, error) {
+	song := p.Source.(model.Song)
+	if song.Album == "" {
+		return nil, nil
+	}
+
+	return album{Name: song.Album, Artist: song.Group_name}, nil
+}
+
+func (r resolver) albumSongs(p graphql.ResolveParams) (any, error) {
+	a := p.Source.(album)
+	thunk := loadersFrom(p.Context).songs.load(a.Artist)
+	return func() (any, error) {
+		songs, err := thunk()
+		if err != nil {
+			return nil, clientError(err)
+		}
+		result := make([]model.Song, 0)
+		for _, song := range songs {
+			if song.Album == a.Name {
+				result = append(result, song)
+			}
+		}
+		return result, nil
+	}, nil
+}
+
+func (r resolver) artistAlbums(p graphql.ResolveParams) (any, error) {
+	name := p.Source.(model.Artist).Name
+	thunk := loadersFrom(p.Context).songs.load(name)
+	return func() (any, error) {
+		songs, err := thunk()
+		if err != nil {
+			return nil, clientError(err)
+		}
+		return albumsOf(name, songs), nil
+	}, nil
+}
+
+func (r resolver) artistSongs(p graphql.ResolveParams) (any, error) {
+	number, size, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+	order, _ := p.Args["sort"].(string)
+	less, err := sortBy(order)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loadersFrom(p.Context).songs.load(p.Source.(model.Artist).Name)
+	return func() (any, error) {
+		songs, err := thunk()
+		if err != nil {
+			return nil, clientError(err)
+		}
+
+		sorted := append([]model.Song(nil), songs...)
+		if less != nil {
+			sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+		}
+		start := min((number-1)*size, len(sorted))
+		return sorted[start:min(start+size, len(sorted))], nil
+	}, nil
+}
+
+// sortBy mirrors the sort of the filter for songs that are already loaded.
+func sortBy(order string) (func(a, b model.Song) bool, error) {
+	if order == "" {
+		return nil, nil
+	}
+
+	fields := map[string]func(model.Song) string{
+		"group":        func(s model.Song) string { return s.Group_name },
+		"song":         func(s model.Song) string { return s.Song },
+		"album":        func(s model.Song) string { return s.Album },
+		"release_date": func(s model.Song) string { return s.ReleaseDate },
+	}
+	field, ok := fields[strings.TrimPrefix(order, "-")]
+	if !ok {
+		return nil, errors.New("Unknown sort field: " + order)
+	}
+	if strings.HasPrefix(order, "-") {
+		return func(a, b model.Song) bool { return field(a) > field(b) }, nil
+	}
+
+	return func(a, b model.Song) bool { return field(a) < field(b) }, nil
+}
+
+func artistOf(name string, songs []model.Song) model.Artist {
+	return model.Artist{Name: name, Songs: len(songs), Albums: len(albumsOf(name, songs))}
+}
+
+func albumsOf(artist string, songs []model.Song) []album {
+	seen := make(map[string]bool)
+	albums := make([]album, 0)
+	for _, song := range songs {
+		if song.Album != "" && !seen[song.Album] {
+			seen[song.Album] = true
+			albums = append(albums, album{Name: song.Album, Artist: artist})
+		}
+	}
+
+	return albums
+}
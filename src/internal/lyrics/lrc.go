@@ -0,0 +1,333 @@
+// Package lyrics parses and writes time-synced lyrics in the LRC format,
+// including the enhanced LRC word timestamps used for karaoke.
+package lyrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Word is a word of an enhanced LRC line with its own start time.
+type Word struct {
+	Time time.Duration
+	Text string
+}
+
+// Line is a lyrics line that becomes active at Time.
+type Line struct {
+	Time  time.Duration
+	Text  string
+	Words []Word
+}
+
+// Lyrics is a parsed LRC file. Lines are sorted by time and the offset tag is
+// already applied to them.
+type Lyrics struct {
+	Tags  map[string]string
+	Lines []Line
+}
+
+// SyntaxError describes a malformed line of an LRC file.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Errors lists every malformed line of an LRC file.
+type Errors []SyntaxError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Parse reads an LRC or enhanced LRC file. It reports all malformed lines at
+// once as Errors, so an upload can be fixed in a single pass.
+func Parse(r io.Reader) (Lyrics, error) {
+	lyrics := Lyrics{Tags: make(map[string]string)}
+	var errs Errors
+	var offset time.Duration
+
+	scanner := bufio.NewScanner(r)
+	number := 0
+	for scanner.Scan() {
+		number++
+		raw := strings.TrimSpace(scanner.Text())
+		if number == 1 {
+			raw = strings.TrimPrefix(raw, "\uFEFF")
+		}
+		if raw == "" {
+			continue
+		}
+
+		times, rest, err := parseTags(raw, lyrics.Tags)
+		if err != nil {
+			errs = append(errs, SyntaxError{Line: number, Message: err.Error()})
+			continue
+		}
+		if times == nil {
+			continue
+		}
+
+		text, words, err := parseWords(rest)
+		if err != nil {
+			errs = append(errs, SyntaxError{Line: number, Message: err.Error()})
+			continue
+		}
+		for _, at := range times {
+			// Word stamps are written for the first time of a repeated
+			// text, the other copies get their own words shifted to their
+			// time.
+			shifted := slices.Clone(words)
+			for i := range shifted {
+				shifted[i].Time += at - times[0]
+			}
+			lyrics.Lines = append(lyrics.Lines, Line{Time: at, Text: text, Words: shifted})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Lyrics{}, err
+	}
+
+	if value, ok := lyrics.Tags["offset"]; ok {
+		ms, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, SyntaxError{Line: 0, Message: fmt.Sprintf("invalid offset: %s", value)})
+		}
+		offset = time.Duration(ms) * time.Millisecond
+	}
+	if len(errs) > 0 {
+		return Lyrics{}, errs
+	}
+	if len(lyrics.Lines) == 0 {
+		return Lyrics{}, Errors{{Line: 0, Message: "no timestamped lines"}}
+	}
+
+	// A positive offset makes the lyrics appear sooner.
+	for i := range lyrics.Lines {
+		line := &lyrics.Lines[i]
+		line.Time = clamp(line.Time - offset)
+		for j := range line.Words {
+			line.Words[j].Time = clamp(line.Words[j].Time - offset)
+		}
+	}
+	delete(lyrics.Tags, "offset")
+	sort.SliceStable(lyrics.Lines, func(i, j int) bool {
+		return lyrics.Lines[i].Time < lyrics.Lines[j].Time
+	})
+
+	return lyrics, nil
+}
+
+// parseTags consumes the leading [..] tags of a line. Time tags are returned,
+// metadata tags such as [ar:Artist] are stored in tags. A line of metadata
+// returns no times.
+func parseTags(raw string, tags map[string]string) ([]time.Duration, string, error) {
+	if !strings.HasPrefix(raw, "[") {
+		return nil, "", fmt.Errorf("line does not start with a timestamp")
+	}
+
+	var times []time.Duration
+	rest := raw
+	for strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated tag")
+		}
+		tag := rest[1:end]
+		rest = rest[end+1:]
+
+		if tag != "" && tag[0] >= '0' && tag[0] <= '9' {
+			at, err := ParseTimestamp(tag)
+			if err != nil {
+				return nil, "", err
+			}
+			times = append(times, at)
+			continue
+		}
+
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok || key == "" || times != nil {
+			return nil, "", fmt.Errorf("invalid tag [%s]", tag)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, "", fmt.Errorf("unexpected text after tag [%s]", tag)
+		}
+		tags[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	return times, strings.TrimSpace(rest), nil
+}
+
+// parseWords splits enhanced LRC text like "<00:01.00>Hello <00:01.50>world"
+// into timed words. Plain text has no words.
+func parseWords(text string) (string, []Word, error) {
+	if !strings.Contains(text, "<") {
+		return text, nil, nil
+	}
+
+	var words []Word
+	var plain []string
+	rest := text
+	for rest != "" {
+		start := strings.IndexByte(rest, '<')
+		if start < 0 {
+			return "", nil, fmt.Errorf("word without timestamp: %s", rest)
+		}
+		if prefix := strings.TrimSpace(rest[:start]); prefix != "" {
+			return "", nil, fmt.Errorf("word without timestamp: %s", prefix)
+		}
+
+		end := strings.IndexByte(rest[start:], '>')
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated word timestamp")
+		}
+		at, err := ParseTimestamp(rest[start+1 : start+end])
+		if err != nil {
+			return "", nil, err
+		}
+		rest = rest[start+end+1:]
+
+		next := strings.IndexByte(rest, '<')
+		if next < 0 {
+			next = len(rest)
+		}
+		word := strings.TrimSpace(rest[:next])
+		rest = rest[next:]
+		// A trailing stamp only marks the end of the last word.
+		if word == "" {
+			continue
+		}
+		words = append(words, Word{Time: at, Text: word})
+		plain = append(plain, word)
+	}
+
+	return strings.Join(plain, " "), words, nil
+}
+
+// ParseTimestamp parses mm:ss, mm:ss.xx or mm:ss.xxx.
+func ParseTimestamp(value string) (time.Duration, error) {
+	minutes, rest, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid timestamp %q, expected mm:ss.xx", value)
+	}
+	seconds, fraction, hasFraction := strings.Cut(rest, ".")
+	if !hasFraction {
+		// Some players write the hundredths after a second colon.
+		seconds, fraction, hasFraction = strings.Cut(rest, ":")
+	}
+
+	m, err := digits(minutes, 1, 3)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q, expected mm:ss.xx", value)
+	}
+	s, err := digits(seconds, 2, 2)
+	if err != nil || s >= 60 {
+		return 0, fmt.Errorf("invalid timestamp %q, expected mm:ss.xx", value)
+	}
+
+	at := time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if hasFraction {
+		f, err := digits(fraction, 1, 3)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q, expected mm:ss.xx", value)
+		}
+		for i := len(fraction); i < 3; i++ {
+			f *= 10
+		}
+		at += time.Duration(f) * time.Millisecond
+	}
+
+	return at, nil
+}
+
+func digits(value string, min, max int) (int, error) {
+	if len(value) < min || len(value) > max {
+		return 0, strconv.ErrSyntax
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return 0, strconv.ErrSyntax
+		}
+	}
+
+	return strconv.Atoi(value)
+}
+
+// FormatTimestamp formats a time as mm:ss.xx.
+func FormatTimestamp(at time.Duration) string {
+	hundredths := int64(at / (10 * time.Millisecond))
+	return fmt.Sprintf("%02d:%02d.%02d", hundredths/6000, hundredths/100%60, hundredths%100)
+}
+
+// Active returns the index of the line shown at the time, that is the last
+// line that started at or before it, or -1 before the first line.
+func Active(lines []Line, at time.Duration) int {
+	return sort.Search(len(lines), func(i int) bool {
+		return lines[i].Time > at
+	}) - 1
+}
+
+// Text joins the lines into plain text.
+func Text(lines []Line) string {
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.Text)
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// Write writes the lyrics as LRC, using enhanced word timestamps for lines
+// that have them.
+func Write(w io.Writer, lyrics Lyrics) error {
+	var b strings.Builder
+	for _, key := range []string{"ar", "ti", "al", "by", "length"} {
+		if value := lyrics.Tags[key]; value != "" {
+			fmt.Fprintf(&b, "[%s:%s]\n", key, oneLine(value))
+		}
+	}
+
+	for _, line := range lyrics.Lines {
+		fmt.Fprintf(&b, "[%s]", FormatTimestamp(line.Time))
+		if len(line.Words) == 0 {
+			b.WriteString(oneLine(line.Text))
+		}
+		for i, word := range line.Words {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "<%s>%s", FormatTimestamp(word.Time), oneLine(word.Text))
+		}
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func clamp(at time.Duration) time.Duration {
+	if at < 0 {
+		return 0
+	}
+
+	return at
+}
+
+func oneLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
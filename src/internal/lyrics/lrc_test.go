@@ -0,0 +1,240 @@
+package lyrics
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "00:00", want: 0},
+		{value: "01:02", want: ms(62000)},
+		{value: "01:02.3", want: ms(62300)},
+		{value: "01:02.34", want: ms(62340)},
+		{value: "01:02.345", want: ms(62345)},
+		{value: "01:02:34", want: ms(62340)},
+		{value: "1:02.50", want: ms(62500)},
+		{value: "120:00.00", want: 2 * time.Hour},
+		{value: "01:60.00", wantErr: true},
+		{value: "01:2.00", wantErr: true},
+		{value: "01:02.3456", wantErr: true},
+		{value: "1234:00", wantErr: true},
+		{value: "01:02.", wantErr: true},
+		{value: "aa:02.00", wantErr: true},
+		{value: "01", wantErr: true},
+		{value: "-1:02", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseTimestamp(%q) = %s, %v, want %s, error %v", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantTags map[string]string
+		want     []Line
+	}{
+		{
+			name:     "plain",
+			input:    "[ar: Muse ]\n[ti:Hysteria]\n\n[00:01.00]It's bugging me\n[00:03.50]Grating me\n",
+			wantTags: map[string]string{"ar": "Muse", "ti": "Hysteria"},
+			want: []Line{
+				{Time: ms(1000), Text: "It's bugging me"},
+				{Time: ms(3500), Text: "Grating me"},
+			},
+		},
+		{
+			name:     "byte order mark and CRLF",
+			input:    "\uFEFF[00:01.00]One\r\n[00:02.00]Two\r\n",
+			wantTags: map[string]string{},
+			want:     []Line{{Time: ms(1000), Text: "One"}, {Time: ms(2000), Text: "Two"}},
+		},
+		{
+			name:     "repeated line sorted",
+			input:    "[00:05.00]Verse\n[00:02.00][00:08.00]Chorus\n",
+			wantTags: map[string]string{},
+			want: []Line{
+				{Time: ms(2000), Text: "Chorus"},
+				{Time: ms(5000), Text: "Verse"},
+				{Time: ms(8000), Text: "Chorus"},
+			},
+		},
+		{
+			name:     "empty line keeps its time",
+			input:    "[00:01.00]One\n[00:02.00]\n",
+			wantTags: map[string]string{},
+			want:     []Line{{Time: ms(1000), Text: "One"}, {Time: ms(2000), Text: ""}},
+		},
+		{
+			name:     "offset",
+			input:    "[offset:+500]\n[00:00.20]Early\n[00:01.00]<00:01.00>Hello <00:01.50>world\n",
+			wantTags: map[string]string{},
+			want: []Line{
+				{Time: 0, Text: "Early"},
+				{Time: ms(500), Text: "Hello world", Words: []Word{{ms(500), "Hello"}, {ms(1000), "world"}}},
+			},
+		},
+		{
+			name:     "offset of repeated words",
+			input:    "[offset:-1000]\n[00:01.00][00:09.00]<00:01.00>Hey <00:01.50>\n",
+			wantTags: map[string]string{},
+			want: []Line{
+				{Time: ms(2000), Text: "Hey", Words: []Word{{ms(2000), "Hey"}}},
+				{Time: ms(10000), Text: "Hey", Words: []Word{{ms(10000), "Hey"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", got.Tags, tt.wantTags)
+			}
+			if !reflect.DeepEqual(got.Lines, tt.want) {
+				t.Errorf("lines = %+v, want %+v", got.Lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Errors
+	}{
+		{
+			name:  "every malformed line",
+			input: "[00:01.00]One\nno timestamp\n[00:61.00]Two\n[00:03.00\n[ar:Muse] text\n",
+			want: Errors{
+				{Line: 2, Message: "line does not start with a timestamp"},
+				{Line: 3, Message: `invalid timestamp "00:61.00", expected mm:ss.xx`},
+				{Line: 4, Message: "unterminated tag"},
+				{Line: 5, Message: "unexpected text after tag [ar:Muse]"},
+			},
+		},
+		{
+			name:  "words",
+			input: "[00:01.00]Hello <00:01.50>world\n[00:02.00]<00:02.00>Hello <00:02.50\n",
+			want: Errors{
+				{Line: 1, Message: "word without timestamp: Hello"},
+				{Line: 2, Message: "unterminated word timestamp"},
+			},
+		},
+		{
+			name:  "metadata after a time",
+			input: "[00:01.00][ar:Muse]\n",
+			want:  Errors{{Line: 1, Message: "invalid tag [ar:Muse]"}},
+		},
+		{
+			name:  "invalid offset",
+			input: "[offset:soon]\n[00:01.00]One\n",
+			want:  Errors{{Line: 0, Message: "invalid offset: soon"}},
+		},
+		{
+			name:  "no lines",
+			input: "[ar:Muse]\n\n",
+			want:  Errors{{Line: 0, Message: "no timestamped lines"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			var got Errors
+			if !errors.As(err, &got) {
+				t.Fatalf("Parse() error = %v, want Errors", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		at   time.Duration
+		want string
+	}{
+		{at: 0, want: "00:00.00"},
+		{at: ms(62345), want: "01:02.34"},
+		{at: 2 * time.Hour, want: "120:00.00"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTimestamp(tt.at); got != tt.want {
+			t.Errorf("FormatTimestamp(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	lines := []Line{{Time: ms(1000)}, {Time: ms(2000)}, {Time: ms(2000)}, {Time: ms(5000)}}
+	tests := []struct {
+		at   time.Duration
+		want int
+	}{
+		{at: 0, want: -1},
+		{at: ms(1000), want: 0},
+		{at: ms(1999), want: 0},
+		{at: ms(2000), want: 2},
+		{at: time.Hour, want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := Active(lines, tt.at); got != tt.want {
+			t.Errorf("Active(%s) = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	lyrics := Lyrics{
+		Tags: map[string]string{"ar": "Muse", "ti": "Hysteria\nLive", "re": "ignored"},
+		Lines: []Line{
+			{Time: ms(1000), Text: "It's  bugging me"},
+			{Time: ms(62340), Text: "Hello world", Words: []Word{{ms(62340), "Hello"}, {ms(62800), "world"}}},
+		},
+	}
+
+	var b strings.Builder
+	if err := Write(&b, lyrics); err != nil {
+		t.Fatal(err)
+	}
+	want := "[ar:Muse]\n[ti:Hysteria Live]\n[00:01.00]It's bugging me\n[01:02.34]<01:02.34>Hello <01:02.80>world\n"
+	if b.String() != want {
+		t.Fatalf("Write() = %q, want %q", b.String(), want)
+	}
+
+	parsed, err := Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Lines) != 2 || parsed.Lines[0].Text != "It's bugging me" || !reflect.DeepEqual(parsed.Lines[1], lyrics.Lines[1]) {
+		t.Errorf("parsed lines = %+v, want the written ones", parsed.Lines)
+	}
+}
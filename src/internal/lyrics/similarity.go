@@ -0,0 +1,29 @@
+package lyrics
+
+// Similarity compares the vocabularies of two texts with the Jaccard index,
+// from 0 for no common words to 1 for the same words. It tolerates small
+// edits and reordered verses.
+func Similarity(a, b string) float64 {
+	left, right := vocabulary(a), vocabulary(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	common := 0
+	for word := range left {
+		if right[word] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(left)+len(right)-common)
+}
+
+func vocabulary(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range tokenize(text) {
+		words[word] = true
+	}
+
+	return words
+}
@@ -0,0 +1,25 @@
+package lyrics
+
+import "testing"
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{name: "empty", a: "", b: "hello", want: 0},
+		{name: "same", a: "Hello world", b: "hello, WORLD!", want: 1},
+		{name: "reordered", a: "one two\n\nthree four", b: "three four\n\none two", want: 1},
+		{name: "half", a: "one two three", b: "one two four", want: 0.5},
+		{name: "different", a: "one two", b: "three four", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); got != tt.want {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package lyrics
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	// wordsPerMinute is an average silent reading speed.
+	wordsPerMinute = 200
+	// MaxTop limits the number of most frequent words.
+	MaxTop = 100
+)
+
+// WordCount is a word with the number of its occurrences.
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Stats describes the text of lyrics. Stop words are counted as words but
+// left out of TopWords.
+type Stats struct {
+	Language           string      `json:"language"`
+	Verses             int         `json:"verses"`
+	Lines              int         `json:"lines"`
+	Words              int         `json:"words"`
+	UniqueWords        int         `json:"unique_words"`
+	UniqueRatio        float64     `json:"unique_ratio"`
+	TopWords           []WordCount `json:"top_words"`
+	ReadingTimeSeconds int         `json:"reading_time_seconds"`
+
+	counts map[string]int
+}
+
+// Analyze computes the stats of the text with the stop words of the
+// language. An empty language is detected from the script of the text.
+func Analyze(text, lang string, top int) Stats {
+	stats := Stats{Language: baseLanguage(lang, text), counts: make(map[string]int)}
+	for _, verse := range Verses(text) {
+		stats.Verses++
+		for _, line := range strings.Split(verse, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			stats.Lines++
+			for _, word := range tokenize(line) {
+				stats.counts[word]++
+				stats.Words++
+			}
+		}
+	}
+
+	stats.summarize(top)
+	return stats
+}
+
+// Merge adds up the stats of several texts in the language.
+func Merge(lang string, all []Stats, top int) Stats {
+	merged := Stats{Language: baseLanguage(lang, ""), counts: make(map[string]int)}
+	for _, stats := range all {
+		merged.Verses += stats.Verses
+		merged.Lines += stats.Lines
+		merged.Words += stats.Words
+		for word, count := range stats.counts {
+			merged.counts[word] += count
+		}
+	}
+
+	merged.summarize(top)
+	return merged
+}
+
+func (s *Stats) summarize(top int) {
+	s.UniqueWords = len(s.counts)
+	if s.Words > 0 {
+		s.UniqueRatio = math.Round(float64(s.UniqueWords)/float64(s.Words)*1000) / 1000
+	}
+	s.ReadingTimeSeconds = int(math.Ceil(float64(s.Words) * 60 / wordsPerMinute))
+
+	stop := stopWords[s.Language]
+	s.TopWords = make([]WordCount, 0, top)
+	for word, count := range s.counts {
+		if !stop[word] {
+			s.TopWords = append(s.TopWords, WordCount{Word: word, Count: count})
+		}
+	}
+	sort.Slice(s.TopWords, func(i, j int) bool {
+		if s.TopWords[i].Count != s.TopWords[j].Count {
+			return s.TopWords[i].Count > s.TopWords[j].Count
+		}
+		return s.TopWords[i].Word < s.TopWords[j].Word
+	})
+	if len(s.TopWords) > top {
+		s.TopWords = s.TopWords[:top]
+	}
+}
+
+// tokenize splits a line into lowercase words, keeping inner apostrophes and
+// hyphens as in "don't" or "rock-n-roll".
+func tokenize(line string) []string {
+	var words []string
+	for _, field := range strings.FieldsFunc(line, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’' && r != '-'
+	}) {
+		word := strings.Trim(strings.ToLower(field), "'’-")
+		if word != "" {
+			words = append(words, strings.ReplaceAll(word, "’", "'"))
+		}
+	}
+
+	return words
+}
+
+// baseLanguage reduces a BCP-47 tag to the language of the stop words. Without
+// a tag Cyrillic text is taken as Russian and anything else as English.
+func baseLanguage(lang, text string) string {
+	if lang != "" && lang != "und" {
+		if tag, err := language.Parse(lang); err == nil {
+			base, _ := tag.Base()
+			return base.String()
+		}
+	}
+
+	cyrillic, latin := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	if cyrillic > latin {
+		return "ru"
+	}
+
+	return "en"
+}
+
+// Cache keeps computed stats until the text they were computed from changes.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cached
+	size    int
+}
+
+type cached struct {
+	fingerprint uint64
+	stats       Stats
+}
+
+// NewCache creates a cache of at most size entries. It is emptied when full,
+// which is cheap because stats are recomputed from the text.
+func NewCache(size int) *Cache {
+	return &Cache{entries: make(map[string]cached), size: size}
+}
+
+// Analyze returns the cached stats of the key while the text and language
+// are unchanged, analyzing the text otherwise.
+func (c *Cache) Analyze(key, text, lang string, top int) Stats {
+	h := fnv.New64a()
+	h.Write([]byte(lang))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	fingerprint := h.Sum64()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok || entry.fingerprint != fingerprint {
+		entry = cached{fingerprint: fingerprint, stats: Analyze(text, lang, MaxTop)}
+		c.mu.Lock()
+		if len(c.entries) >= c.size {
+			clear(c.entries)
+		}
+		c.entries[key] = entry
+		c.mu.Unlock()
+	}
+
+	stats := entry.stats
+	stats.TopWords = stats.TopWords[:min(top, len(stats.TopWords))]
+	return stats
+}
+
+var stopWords = map[string]map[string]bool{
+	"en": set("a", "an", "and", "are", "as", "at", "be", "but", "by", "do", "don't", "for", "from",
+		"he", "her", "his", "i", "i'm", "if", "in", "is", "it", "it's", "me", "my", "no", "not", "of",
+		"oh", "on", "or", "our", "she", "so", "that", "the", "their", "them", "they", "this", "to",
+		"up", "was", "we", "what", "when", "with", "you", "you're", "your"),
+	"ru": set("а", "без", "бы", "в", "во", "вот", "все", "всё", "вы", "да", "для", "до", "же", "за",
+		"и", "из", "или", "их", "к", "как", "ко", "ли", "мне", "мы", "на", "не", "нет", "ни", "но",
+		"о", "об", "он", "она", "они", "от", "по", "с", "со", "так", "там", "те", "то", "ты", "у",
+		"уже", "что", "это", "я"),
+	"de": set("aber", "auch", "auf", "aus", "bin", "bist", "da", "das", "dass", "dein", "dem", "den",
+		"der", "die", "dich", "dir", "du", "ein", "eine", "er", "es", "für", "ich", "ihr", "im", "in",
+		"ist", "ja", "mein", "mich", "mir", "mit", "nicht", "noch", "nur", "sie", "so", "und", "von",
+		"wie", "wir", "zu"),
+	"es": set("a", "al", "como", "con", "de", "del", "el", "en", "es", "la", "las", "lo", "los", "me",
+		"mi", "no", "para", "pero", "por", "que", "se", "si", "sin", "su", "te", "tu", "un", "una",
+		"y", "ya", "yo"),
+	"fr": set("à", "au", "avec", "ce", "de", "des", "du", "elle", "en", "est", "et", "il", "je", "la",
+		"le", "les", "ma", "mais", "me", "mon", "ne", "nous", "on", "pas", "pour", "que", "qui", "sa",
+		"se", "son", "sur", "te", "toi", "ton", "tu", "un", "une", "vous"),
+}
+
+func set(words ...string) map[string]bool {
+	result := make(map[string]bool, len(words))
+	for _, word := range words {
+		result[word] = true
+	}
+
+	return result
+}
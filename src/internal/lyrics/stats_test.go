@@ -0,0 +1,109 @@
+package lyrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "", want: nil},
+		{line: "Hello, World!", want: []string{"hello", "world"}},
+		{line: "Don’t stop rock-n-roll", want: []string{"don't", "stop", "rock-n-roll"}},
+		{line: "'quoted' -dash- 1999", want: []string{"quoted", "dash", "1999"}},
+		{line: "Всё будет хорошо", want: []string{"всё", "будет", "хорошо"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := tokenize(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		text string
+		want string
+	}{
+		{name: "tag", lang: "pt-BR", want: "pt"},
+		{name: "undetermined", lang: "und", text: "Привет, мир", want: "ru"},
+		{name: "cyrillic", text: "Привет, world", want: "ru"},
+		{name: "latin", text: "Hello, мир", want: "en"},
+		{name: "invalid tag", lang: "not a tag", text: "", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := baseLanguage(tt.lang, tt.text); got != tt.want {
+				t.Errorf("baseLanguage(%q, %q) = %q, want %q", tt.lang, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	stats := Analyze("Hello, hello world\nThe world\n\n  \nDon't stop\n", "", MaxTop)
+	stats.counts = nil
+
+	want := Stats{
+		Language:           "en",
+		Verses:             2,
+		Lines:              3,
+		Words:              7,
+		UniqueWords:        5,
+		UniqueRatio:        0.714,
+		TopWords:           []WordCount{{Word: "hello", Count: 2}, {Word: "world", Count: 2}, {Word: "stop", Count: 1}},
+		ReadingTimeSeconds: 3,
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Analyze() = %+v, want %+v", stats, want)
+	}
+
+	if empty := Analyze("", "ru", 10); empty.Words != 0 || empty.UniqueRatio != 0 || len(empty.TopWords) != 0 {
+		t.Errorf("Analyze() of no text = %+v, want zero stats", empty)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	all := []Stats{
+		Analyze("one two two", "en", MaxTop),
+		Analyze("two three\n\nthree", "en", MaxTop),
+	}
+	merged := Merge("en", all, 2)
+
+	if merged.Verses != 3 || merged.Lines != 3 || merged.Words != 6 || merged.UniqueWords != 3 {
+		t.Errorf("Merge() = %+v, want 3 verses, 3 lines, 6 words and 3 unique words", merged)
+	}
+	want := []WordCount{{Word: "two", Count: 3}, {Word: "three", Count: 2}}
+	if !reflect.DeepEqual(merged.TopWords, want) {
+		t.Errorf("Merge() top words = %v, want %v", merged.TopWords, want)
+	}
+}
+
+func TestCacheAnalyze(t *testing.T) {
+	cache := NewCache(1)
+
+	first := cache.Analyze("1", "a b c", "en", 1)
+	if first.Words != 3 || len(first.TopWords) != 1 {
+		t.Fatalf("Analyze() = %+v, want 3 words and 1 top word", first)
+	}
+	if again := cache.Analyze("1", "a b c", "en", MaxTop); len(again.TopWords) != 2 {
+		t.Errorf("Analyze() of the cached text top words = %v, want the top limited by the call", again.TopWords)
+	}
+	if changed := cache.Analyze("1", "a b c d", "en", MaxTop); changed.Words != 4 {
+		t.Errorf("Analyze() of a changed text words = %d, want 4", changed.Words)
+	}
+
+	cache.Analyze("2", "x", "en", MaxTop)
+	if len(cache.entries) != 1 {
+		t.Errorf("cache entries = %d, want the full cache emptied", len(cache.entries))
+	}
+}
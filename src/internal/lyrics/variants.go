@@ -0,0 +1,72 @@
+package lyrics
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Match picks the variant for the preferred languages, given as an
+// Accept-Language value or a single tag. It reports false when none of the
+// available languages is acceptable.
+func Match(available []string, preferred string) (int, bool) {
+	if len(available) == 0 || preferred == "" {
+		return 0, false
+	}
+	desired, _, err := language.ParseAcceptLanguage(preferred)
+	if err != nil || len(desired) == 0 {
+		return 0, false
+	}
+
+	tags := make([]language.Tag, 0, len(available))
+	for _, value := range available {
+		tags = append(tags, language.Make(value))
+	}
+	_, index, confidence := language.NewMatcher(tags).Match(desired...)
+	if confidence == language.No {
+		return 0, false
+	}
+
+	return index, true
+}
+
+var verseBreak = regexp.MustCompile(`\n\s*\n`)
+
+// Verses splits lyrics into verses separated by blank lines.
+func Verses(text string) []string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil
+	}
+
+	verses := verseBreak.Split(text, -1)
+	for i := range verses {
+		verses[i] = strings.TrimSpace(verses[i])
+	}
+
+	return verses
+}
+
+// Pair is a verse of the original next to its translation. A side is empty
+// when the other text has more verses.
+type Pair struct {
+	Original    string `json:"original"`
+	Translation string `json:"translation"`
+}
+
+// Align pairs the verses of the original and the translation by position.
+func Align(original, translation string) []Pair {
+	left, right := Verses(original), Verses(translation)
+	pairs := make([]Pair, max(len(left), len(right)))
+	for i := range pairs {
+		if i < len(left) {
+			pairs[i].Original = left[i]
+		}
+		if i < len(right) {
+			pairs[i].Translation = right[i]
+		}
+	}
+
+	return pairs
+}
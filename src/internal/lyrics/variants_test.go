@@ -0,0 +1,89 @@
+package lyrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	available := []string{"en", "ru", "pt-BR"}
+	tests := []struct {
+		name      string
+		preferred string
+		want      int
+		ok        bool
+	}{
+		{name: "exact", preferred: "ru", want: 1, ok: true},
+		{name: "region", preferred: "en-GB", want: 0, ok: true},
+		{name: "quality", preferred: "de;q=0.9, pt-BR;q=0.8, ru;q=0.5", want: 2, ok: true},
+		{name: "none acceptable", preferred: "ja", ok: false},
+		{name: "empty", preferred: "", ok: false},
+		{name: "malformed", preferred: ";;;q=x", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Match(available, tt.preferred)
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("Match(%q) = %d, %v, want %d, %v", tt.preferred, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVerses(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: " \n ", want: nil},
+		{name: "single", text: "one\ntwo", want: []string{"one\ntwo"}},
+		{name: "blank lines", text: "\none\n\n  \ntwo\r\n\r\nthree\n", want: []string{"one", "two", "three"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verses(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verses() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		name        string
+		original    string
+		translation string
+		want        []Pair
+	}{
+		{name: "empty", want: []Pair{}},
+		{
+			name:        "same length",
+			original:    "a\n\nb",
+			translation: "x\n\ny",
+			want:        []Pair{{Original: "a", Translation: "x"}, {Original: "b", Translation: "y"}},
+		},
+		{
+			name:        "longer original",
+			original:    "a\n\nb",
+			translation: "x",
+			want:        []Pair{{Original: "a", Translation: "x"}, {Original: "b"}},
+		},
+		{
+			name:        "longer translation",
+			original:    "a",
+			translation: "x\n\ny",
+			want:        []Pair{{Original: "a", Translation: "x"}, {Translation: "y"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Align(tt.original, tt.translation); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Align() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package model
+
+// Artist is a group of the library with the number of its songs and albums.
+type Artist struct {
+	Name   string `json:"name"`
+	Songs  int    `json:"songs"`
+	Albums int    `json:"albums"`
+}
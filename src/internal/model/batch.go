@@ -0,0 +1,21 @@
+package model
+
+// Operations of a batch.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one write of a batch. Updates and deletes address the
+// song by ID, or by group and song name which may match several songs.
+// Values is the new song of a create, Changes the changed fields of an
+// update.
+type BatchOperation struct {
+	Op      string
+	ID      uint
+	Group   string
+	Song    string
+	Values  Song
+	Changes SongUpdate
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// IdempotencyKey is a write request of a caller, identified by the
+// Idempotency-Key header, and the response replayed to its retries. A key
+// without a status is still being processed.
+type IdempotencyKey struct {
+	Scope       string `gorm:"primary_key"`
+	Key         string `gorm:"primary_key"`
+	RequestHash string
+	Status      int
+	Header      RawJSON `gorm:"type:jsonb"`
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// LyricLine is a time-synced lyrics line of a song, TimeMs is its start in
+// milliseconds from the beginning of the song.
+type LyricLine struct {
+	ID       uint       `gorm:"primary_key" json:"-"`
+	SongID   uint       `json:"-"`
+	Position int        `json:"position"`
+	TimeMs   int64      `json:"time_ms"`
+	Text     string     `json:"text"`
+	Words    LyricWords `gorm:"type:jsonb" json:"words,omitempty"`
+}
+
+// LyricWord is a word of an enhanced LRC line.
+type LyricWord struct {
+	TimeMs int64  `json:"time_ms"`
+	Text   string `json:"text"`
+}
+
+// LyricWords is stored as a JSON array.
+type LyricWords []LyricWord
+
+func (w LyricWords) Value() (driver.Value, error) {
+	if w == nil {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal(w)
+	return string(data), err
+}
+
+func (w *LyricWords) Scan(src any) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, w)
+	case string:
+		return json.Unmarshal([]byte(data), w)
+	case nil:
+		*w = nil
+		return nil
+	default:
+		return fmt.Errorf("Unsupported type for lyric words: %T", src)
+	}
+}
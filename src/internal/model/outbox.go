@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Change events of the catalog.
+const (
+	SongCreated = "song.created"
+	SongUpdated = "song.updated"
+	SongDeleted = "song.deleted"
+)
+
+// OutboxEvent is a change event written in the transaction of the change and
+// published after it commits. Payload is the song as of the change,
+// PublishedTo names the sinks that already received it.
+type OutboxEvent struct {
+	ID          uint64         `gorm:"primary_key" json:"id"`
+	Event       string         `json:"event"`
+	SongID      uint           `json:"song_id"`
+	Payload     RawJSON        `gorm:"type:jsonb" json:"payload" swaggertype:"object"`
+	Attempts    int            `json:"attempts"`
+	LastError   string         `json:"last_error,omitempty"`
+	AvailableAt time.Time      `json:"available_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	PublishedAt *time.Time     `json:"published_at,omitempty"`
+	PublishedTo pq.StringArray `gorm:"type:text[];default:'{}'" json:"published_to,omitempty" swaggertype:"array,string"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type Playlist struct {
+	ID          uint      `gorm:"primary_key" json:"id"`
+	OwnerID     uint      `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PlaylistEntry is a song at a 0-based position of a playlist. Positions of
+// a playlist are always contiguous.
+type PlaylistEntry struct {
+	ID         uint      `gorm:"primary_key" json:"id"`
+	PlaylistID uint      `json:"playlist_id"`
+	SongID     uint      `json:"song_id"`
+	Position   int       `json:"position"`
+	AddedBy    *uint     `json:"added_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	Song       Song      `gorm:"foreignkey:SongID" json:"song"`
+}
+
+// PlaylistCollaborator may edit the entries of a playlist it does not own.
+type PlaylistCollaborator struct {
+	PlaylistID uint `gorm:"primary_key"`
+	UserID     uint `gorm:"primary_key"`
+}
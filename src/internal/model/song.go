@@ -4,6 +4,22 @@ type Song struct {
 	ID          uint   `gorm:"primary_key"`
 	Group_name  string `json:"group"`
 	Song        string `json:"song"`
+	Album       string `json:"album"`
 	ReleaseDate string `json:"release_date"`
 	Lyrics      string `json:"text"`
 }
+
+// SongUpdate holds the changed fields of a song, nil fields keep their
+// values and empty strings clear them.
+type SongUpdate struct {
+	Group_name  *string
+	Song        *string
+	Album       *string
+	ReleaseDate *string
+	Lyrics      *string
+}
+
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
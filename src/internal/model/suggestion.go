@@ -0,0 +1,10 @@
+package model
+
+// Suggestion is a song similar to a searched name, Score is the trigram
+// similarity from 0 to 1.
+type Suggestion struct {
+	ID    uint    `json:"id"`
+	Group string  `json:"group"`
+	Song  string  `json:"song"`
+	Score float64 `json:"score"`
+}
@@ -0,0 +1,22 @@
+package model
+
+// Tag classifies songs. Kind is one of genre, mood, language or other, names
+// are unique within a kind.
+type Tag struct {
+	ID   uint   `gorm:"primary_key" json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+type SongTag struct {
+	SongID uint `gorm:"primary_key"`
+	TagID  uint `gorm:"primary_key"`
+}
+
+// TagCount is a tag cloud entry.
+type TagCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
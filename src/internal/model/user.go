@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+type User struct {
+	ID           uint      `gorm:"primary_key" json:"id"`
+	Login        string    `json:"login"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Session struct {
+	ID        uint `gorm:"primary_key"`
+	UserID    uint
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type Favorite struct {
+	UserID    uint `gorm:"primary_key"`
+	SongID    uint `gorm:"primary_key"`
+	CreatedAt time.Time
+}
+
+// List is a personal listening list of a user.
+type List struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	UserID    uint      `json:"-"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListSong struct {
+	ListID    uint `gorm:"primary_key"`
+	SongID    uint `gorm:"primary_key"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,11 @@
+package model
+
+// LyricsVariant is the lyrics of a song in one language, keyed by a BCP-47
+// tag. The original variant mirrors the song lyrics.
+type LyricsVariant struct {
+	ID       uint   `gorm:"primary_key" json:"-"`
+	SongID   uint   `json:"-"`
+	Language string `json:"language"`
+	Original bool   `json:"original"`
+	Text     string `json:"text"`
+}
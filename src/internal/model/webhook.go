@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Webhook subscribes a URL to catalog events. An empty Events list receives
+// every event. Secret signs the payloads and is only shown on creation.
+type Webhook struct {
+	ID        uint           `gorm:"primary_key" json:"id"`
+	URL       string         `json:"url"`
+	Secret    string         `json:"secret,omitempty"`
+	Events    pq.StringArray `gorm:"type:text[]" json:"events" swaggertype:"array,string"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Delivery statuses. A delivery that failed every attempt is dead and stays
+// in the dead-letter list until it is retried by hand.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookDelivery is an event queued for a webhook, it doubles as the
+// delivery log entry. EventID is the outbox event, a webhook gets one
+// delivery per event.
+type WebhookDelivery struct {
+	ID             uint64     `gorm:"primary_key" json:"id"`
+	WebhookID      uint       `json:"webhook_id"`
+	EventID        *uint64    `json:"event_id,omitempty"`
+	Event          string     `json:"event"`
+	Payload        RawJSON    `gorm:"type:jsonb" json:"payload" swaggertype:"object"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// PendingDelivery is a claimed delivery with the target of its webhook.
+type PendingDelivery struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// RawJSON is a JSON document stored as jsonb.
+type RawJSON json.RawMessage
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if j == nil {
+		return []byte("null"), nil
+	}
+
+	return j, nil
+}
+
+func (j *RawJSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[:0], data...)
+	return nil
+}
+
+func (j RawJSON) Value() (driver.Value, error) {
+	if j == nil {
+		return "null", nil
+	}
+
+	return string(j), nil
+}
+
+func (j *RawJSON) Scan(src any) error {
+	switch data := src.(type) {
+	case []byte:
+		*j = append((*j)[:0], data...)
+		return nil
+	case string:
+		*j = RawJSON(data)
+		return nil
+	case nil:
+		*j = nil
+		return nil
+	default:
+		return fmt.Errorf("Unsupported type for JSON: %T", src)
+	}
+}
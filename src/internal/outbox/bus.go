@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"music/internal/model"
+	"strings"
+	"sync"
+)
+
+// Publisher is the publishing side of a NATS connection, *nats.Conn
+// satisfies it.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// BusSink publishes the envelope of every event to the subject of its type
+// under a prefix, e.g. "music.song.created".
+type BusSink struct {
+	conn   Publisher
+	prefix string
+}
+
+func NewBusSink(conn Publisher, prefix string) *BusSink {
+	return &BusSink{conn: conn, prefix: strings.TrimSuffix(prefix, ".")}
+}
+
+func (b *BusSink) Name() string {
+	return "bus"
+}
+
+func (b *BusSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	data, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	return b.conn.Publish(b.Subject(event.Event), data)
+}
+
+func (b *BusSink) Subject(event string) string {
+	if b.prefix == "" {
+		return event
+	}
+
+	return b.prefix + "." + event
+}
+
+// MemoryBus is an in-process Publisher for running without a NATS server.
+// Subscriptions use NATS subject wildcards: "*" matches a token, a trailing
+// ">" matches the rest of the subject.
+type MemoryBus struct {
+	mu   sync.RWMutex
+	next int
+	subs map[int]subscription
+}
+
+type subscription struct {
+	pattern []string
+	handler func(subject string, data []byte)
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subs: make(map[int]subscription)}
+}
+
+// Subscribe calls handler synchronously for every message matching the
+// subject pattern until the returned function is called.
+func (m *MemoryBus) Subscribe(pattern string, handler func(subject string, data []byte)) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.next
+	m.next++
+	m.subs[id] = subscription{pattern: strings.Split(pattern, "."), handler: handler}
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subs, id)
+	}
+}
+
+func (m *MemoryBus) Publish(subject string, data []byte) error {
+	tokens := strings.Split(subject, ".")
+
+	m.mu.RLock()
+	var handlers []func(string, []byte)
+	for _, sub := range m.subs {
+		if matches(sub.pattern, tokens) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(subject, data)
+	}
+
+	return nil
+}
+
+func matches(pattern, tokens []string) bool {
+	for i, token := range pattern {
+		if token == ">" {
+			return i < len(tokens)
+		}
+		if i >= len(tokens) || (token != "*" && token != tokens[i]) {
+			return false
+		}
+	}
+
+	return len(pattern) == len(tokens)
+}
@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"music/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBusSinkPublish(t *testing.T) {
+	bus := NewMemoryBus()
+	received := make(map[string][]string)
+	for _, pattern := range []string{"music.song.*", "music.>", "music.song.deleted", "music.*", "other.>"} {
+		bus.Subscribe(pattern, func(subject string, data []byte) {
+			received[pattern] = append(received[pattern], subject)
+		})
+	}
+
+	sink := NewBusSink(bus, "music.")
+	event := model.OutboxEvent{
+		ID:        7,
+		Event:     model.SongCreated,
+		SongID:    1,
+		Payload:   model.RawJSON(`{"ID":1,"group":"Muse","song":"Hysteria"}`),
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	var envelope Envelope
+	bus.Subscribe("music.song.created", func(subject string, data []byte) {
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+		}
+	})
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	want := map[string]int{"music.song.*": 1, "music.>": 1}
+	for pattern, subjects := range received {
+		if len(subjects) != want[pattern] {
+			t.Errorf("%s received %v, want %d messages", pattern, subjects, want[pattern])
+		}
+	}
+	for pattern, count := range want {
+		if len(received[pattern]) != count {
+			t.Errorf("%s received %v, want %d messages", pattern, received[pattern], count)
+		}
+	}
+	if envelope.ID != "evt_7" || envelope.Type != model.SongCreated || string(envelope.Data) != string(event.Payload) {
+		t.Errorf("envelope = %+v, want the envelope of event 7", envelope)
+	}
+}
+
+func TestMemoryBusUnsubscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	count := 0
+	unsubscribe := bus.Subscribe(">", func(string, []byte) { count++ })
+
+	bus.Publish("music.song.updated", nil)
+	unsubscribe()
+	bus.Publish("music.song.updated", nil)
+
+	if count != 1 {
+		t.Errorf("messages = %d, want 1 before unsubscribing", count)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{pattern: "music.song.created", subject: "music.song.created", want: true},
+		{pattern: "music.song.created", subject: "music.song.deleted", want: false},
+		{pattern: "music.*.created", subject: "music.song.created", want: true},
+		{pattern: "music.*", subject: "music.song.created", want: false},
+		{pattern: "music.>", subject: "music.song.created", want: true},
+		{pattern: "music.>", subject: "music", want: false},
+		{pattern: ">", subject: "music", want: true},
+		{pattern: "music.song.created.*", subject: "music.song.created", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.subject, func(t *testing.T) {
+			if got := matches(strings.Split(tt.pattern, "."), strings.Split(tt.subject, ".")); got != tt.want {
+				t.Errorf("matches(%s, %s) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package outbox publishes the change events the repository writes into the
+// outbox table together with the changes.
+//
+// A change and its event commit or roll back together, so an event is never
+// lost when the process stops right after the change. Events are published
+// at least once: a failed event is published again only to the sinks that
+// failed, but a sink can see an event again when the process stopped before
+// recording the outcome, consumers dedupe by the envelope id. Published
+// events are deleted after the retention of the dispatcher.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"music/internal/base"
+	"music/internal/model"
+	"slices"
+	"time"
+)
+
+const (
+	poll      = time.Second
+	batchSize = 20
+	// timeout bounds the publishing of an event to every sink.
+	timeout = 10 * time.Second
+	// prunePeriod is how often the events published before the retention
+	// are deleted.
+	prunePeriod = 10 * time.Minute
+
+	// lease outlasts the publishing of an event and recording its outcome.
+	// The events of a batch are published in turn, so the repository leases
+	// the n-th for n leases: no other replica claims an event that is still
+	// waiting for its turn, and the events of a crashed replica are due
+	// again as soon as they could have been published.
+	lease = timeout + 5*time.Second
+)
+
+// Envelope is the published form of an event.
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func NewEnvelope(event model.OutboxEvent) Envelope {
+	return Envelope{
+		ID:        fmt.Sprintf("evt_%d", event.ID),
+		Type:      event.Event,
+		CreatedAt: event.CreatedAt.UTC(),
+		Data:      json.RawMessage(event.Payload),
+	}
+}
+
+// Sink receives the events. An error makes the dispatcher publish the event
+// again later to the sinks that failed.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event model.OutboxEvent) error
+}
+
+type Dispatcher struct {
+	repo      base.OutboxRepository
+	retention time.Duration
+	sinks     []Sink
+}
+
+// NewDispatcher publishes the events to the sinks and keeps the published
+// ones for retention, zero keeps them forever.
+func NewDispatcher(repo base.OutboxRepository, retention time.Duration, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{repo: repo, retention: retention, sinks: sinks}
+}
+
+// Run publishes the events and prunes the published ones until ctx is
+// canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	prune := time.NewTicker(prunePeriod)
+	defer prune.Stop()
+
+	for {
+		for d.publishBatch(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-prune.C:
+			d.prune(ctx)
+		}
+	}
+}
+
+// publishBatch reports whether a full batch was claimed and more may be
+// waiting. Every event is marked in a transaction of its own once its sinks
+// ran, a failed one is delayed and published again later to the sinks that
+// failed.
+func (d *Dispatcher) publishBatch(ctx context.Context) bool {
+	events, err := d.repo.ClaimOutbox(ctx, batchSize, lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Println(err)
+		}
+		return false
+	}
+
+	for _, event := range events {
+		publishedTo, publishErr := d.publish(ctx, event)
+		// The outcome is recorded even when ctx is canceled meanwhile.
+		ctx := context.WithoutCancel(ctx)
+		if publishErr != nil {
+			log.Printf("Failed to publish event %d: %s. Error: %s", event.ID, event.Event, publishErr)
+			err = d.repo.DelayEvent(ctx, event.ID, publishedTo, publishErr.Error())
+		} else {
+			err = d.repo.MarkPublished(ctx, event.ID)
+		}
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	return len(events) == batchSize && ctx.Err() == nil
+}
+
+// prune deletes the events published before the retention.
+func (d *Dispatcher) prune(ctx context.Context) {
+	if d.retention <= 0 {
+		return
+	}
+
+	pruned, err := d.repo.PruneOutbox(ctx, time.Now().Add(-d.retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Println(err)
+		}
+		return
+	}
+	if pruned > 0 {
+		log.Printf("Pruned %d published outbox events", pruned)
+	}
+}
+
+// publish publishes the event to the sinks that did not receive it yet and
+// returns every sink that has received it.
+func (d *Dispatcher) publish(ctx context.Context, event model.OutboxEvent) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	publishedTo := slices.Clone(event.PublishedTo)
+	var errs []error
+	for _, sink := range d.sinks {
+		if slices.Contains(event.PublishedTo, sink.Name()) {
+			continue
+		}
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		publishedTo = append(publishedTo, sink.Name())
+	}
+
+	return publishedTo, errors.Join(errs...)
+}
+
+// LogSink writes a line per event to the server log.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	log.Printf("Event %d: %s of song %d", event.ID, event.Event, event.SongID)
+	return nil
+}
@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"music/internal/model"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// repository hands out its events once and records what the dispatcher
+// does with them.
+type repository struct {
+	events    []model.OutboxEvent
+	lease     time.Duration
+	published []uint64
+	delayed   map[uint64]string
+	sent      map[uint64][]string
+	before    time.Time
+}
+
+func (r *repository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	r.lease = lease
+	claimed := r.events[:min(limit, len(r.events))]
+	r.events = r.events[len(claimed):]
+	return claimed, nil
+}
+
+func (r *repository) MarkPublished(ctx context.Context, eventID uint64) error {
+	r.published = append(r.published, eventID)
+	return nil
+}
+
+func (r *repository) DelayEvent(ctx context.Context, eventID uint64, publishedTo []string, cause string) error {
+	r.delayed[eventID] = cause
+	r.sent[eventID] = publishedTo
+	return nil
+}
+
+func (r *repository) PruneOutbox(ctx context.Context, before time.Time) (int64, error) {
+	r.before = before
+	return 0, nil
+}
+
+// sink fails to publish the events in fail.
+type sink struct {
+	name     string
+	fail     map[uint64]bool
+	received []uint64
+}
+
+func (s *sink) Name() string {
+	return s.name
+}
+
+func (s *sink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	s.received = append(s.received, event.ID)
+	if s.fail[event.ID] {
+		return errors.New("unavailable")
+	}
+
+	return nil
+}
+
+func TestPublishBatch(t *testing.T) {
+	repo := &repository{
+		events:  []model.OutboxEvent{{ID: 1}, {ID: 2}, {ID: 3}},
+		delayed: make(map[uint64]string),
+		sent:    make(map[uint64][]string),
+	}
+	healthy, failing := &sink{name: "healthy"}, &sink{name: "failing", fail: map[uint64]bool{2: true}}
+	d := NewDispatcher(repo, time.Hour, healthy, failing)
+
+	if more := d.publishBatch(context.Background()); more {
+		t.Errorf("publishBatch() = true, want false after a partial batch")
+	}
+
+	if repo.lease != lease {
+		t.Errorf("lease = %s, want %s", repo.lease, lease)
+	}
+	want := []uint64{1, 2, 3}
+	if !reflect.DeepEqual(healthy.received, want) || !reflect.DeepEqual(failing.received, want) {
+		t.Errorf("sinks received %v and %v, want %v each", healthy.received, failing.received, want)
+	}
+	if !reflect.DeepEqual(repo.published, []uint64{1, 3}) {
+		t.Errorf("published = %v, want [1 3]", repo.published)
+	}
+	if cause := repo.delayed[2]; len(repo.delayed) != 1 || cause != "failing: unavailable" {
+		t.Errorf("delayed = %v, want event 2 with the sink error", repo.delayed)
+	}
+	if sent := repo.sent[2]; !reflect.DeepEqual(sent, []string{"healthy"}) {
+		t.Errorf("event 2 published to %v, want [healthy]", sent)
+	}
+}
+
+// TestPublishRetry checks that a delayed event only goes to the sinks that
+// failed, so the others do not receive it twice.
+func TestPublishRetry(t *testing.T) {
+	repo := &repository{
+		events:  []model.OutboxEvent{{ID: 2, PublishedTo: []string{"healthy"}}},
+		delayed: make(map[uint64]string),
+		sent:    make(map[uint64][]string),
+	}
+	healthy, failing := &sink{name: "healthy"}, &sink{name: "failing"}
+	d := NewDispatcher(repo, time.Hour, healthy, failing)
+
+	d.publishBatch(context.Background())
+
+	if len(healthy.received) != 0 {
+		t.Errorf("healthy sink received %v again", healthy.received)
+	}
+	if !reflect.DeepEqual(failing.received, []uint64{2}) {
+		t.Errorf("failing sink received %v, want [2]", failing.received)
+	}
+	if !reflect.DeepEqual(repo.published, []uint64{2}) || len(repo.delayed) != 0 {
+		t.Errorf("published = %v, delayed = %v, want event 2 published", repo.published, repo.delayed)
+	}
+}
+
+// TestLeaseOutlastsEvent guards against another replica claiming an event
+// that is still being published.
+func TestLeaseOutlastsEvent(t *testing.T) {
+	if lease <= timeout {
+		t.Errorf("lease = %s, want longer than the %s timeout of an event", lease, timeout)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		want      time.Duration
+	}{
+		{name: "retention", retention: 24 * time.Hour, want: 24 * time.Hour},
+		{name: "forever", retention: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository{}
+			d := NewDispatcher(repo, tt.retention)
+
+			d.prune(context.Background())
+
+			if tt.want == 0 {
+				if !repo.before.IsZero() {
+					t.Errorf("pruned before %s, want no pruning", repo.before)
+				}
+				return
+			}
+			if age := time.Since(repo.before); age < tt.want || age > tt.want+time.Minute {
+				t.Errorf("pruned events older than %s, want %s", age, tt.want)
+			}
+		})
+	}
+}
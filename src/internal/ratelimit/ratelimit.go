@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket refilled with Rate tokens per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. The in-process MemoryStore serves a single
+// instance, a shared store can implement the same interface for replicas.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Peek reports what Take would return without taking a token.
+	Peek(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	idle    time.Duration
+	swept   time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a store that forgets buckets unused for idle.
+func NewMemoryStore(idle time.Duration) *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		idle:    idle,
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	return m.take(key, limit, 1), nil
+}
+
+func (m *MemoryStore) Peek(_ context.Context, key string, limit Limit) (Result, error) {
+	return m.take(key, limit, 0), nil
+}
+
+// take refills the bucket of key and takes cost tokens if it holds at least
+// one.
+func (m *MemoryStore) take(key string, limit Limit, cost float64) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens -= cost
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+
+	return result
+}
+
+// sweep drops idle buckets at most once per idle period. The idle period
+// should exceed the refill time of the largest limit, so that only full
+// buckets are forgotten.
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.swept) < m.idle {
+		return
+	}
+
+	for key, b := range m.buckets {
+		if now.Sub(b.last) > m.idle {
+			delete(m.buckets, key)
+		}
+	}
+	m.swept = now
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 3}
+	tests := []struct {
+		name          string
+		after         time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{name: "full bucket", wantAllowed: true, wantRemaining: 2},
+		{name: "second token", wantAllowed: true, wantRemaining: 1},
+		{name: "last token", wantAllowed: true, wantRemaining: 0},
+		{name: "empty bucket", wantAllowed: false, wantRemaining: 0, wantRetry: time.Second},
+		{name: "refilled by rate", after: 500 * time.Millisecond, wantAllowed: true, wantRemaining: 0},
+		{name: "refill capped at burst", after: time.Hour, wantAllowed: true, wantRemaining: 2},
+	}
+
+	now := time.Unix(0, 0)
+	store := NewMemoryStore(2 * time.Hour)
+	store.now = func() time.Time { return now }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.after)
+			got, err := store.Take(context.Background(), "client", limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining || got.RetryAfter != tt.wantRetry {
+				t.Errorf("Take() = %+v, want allowed %t, remaining %d, retry after %s", got, tt.wantAllowed, tt.wantRemaining, tt.wantRetry)
+			}
+			if got.Limit != limit.Burst {
+				t.Errorf("Take().Limit = %d, want %d", got.Limit, limit.Burst)
+			}
+		})
+	}
+}
+
+func TestMemoryStorePeek(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 1}
+	store := NewMemoryStore(time.Hour)
+	ctx := context.Background()
+
+	for range 3 {
+		if got, _ := store.Peek(ctx, "client", limit); !got.Allowed || got.Remaining != 1 {
+			t.Fatalf("Peek() = %+v, want allowed with a token left", got)
+		}
+	}
+	store.Take(ctx, "client", limit)
+	if got, _ := store.Peek(ctx, "client", limit); got.Allowed {
+		t.Errorf("Peek() after the last token = %+v, want not allowed", got)
+	}
+	if got, _ := store.Peek(ctx, "other", limit); !got.Allowed {
+		t.Errorf("Peek() of another key = %+v, want allowed", got)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewMemoryStore(time.Minute)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	store.Take(ctx, "idle", Limit{Rate: 1, Burst: 1})
+	now = now.Add(2 * time.Minute)
+	store.Take(ctx, "active", Limit{Rate: 1, Burst: 1})
+
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := store.buckets["active"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	musicv1 "music/api/music/v1"
+	"music/internal/auth"
+	"music/internal/base"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// authorizer resolves the caller from the request metadata with the same
+// authenticator as the HTTP API and checks the role the method requires.
+// MusicLibrary methods without a role are denied, so a new method is not
+// public by accident. Methods of other services, such as health and
+// reflection, are public. Every call is rate limited once the caller is
+// resolved.
+type authorizer struct {
+	auth   *auth.Authenticator
+	roles  map[string]auth.Role
+	limits limiter
+}
+
+func (a authorizer) authorize(ctx context.Context, method string) (context.Context, error) {
+	if err := a.limits.throttled(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	principal, err := a.auth.Resolve(ctx, first(md, strings.ToLower(auth.APIKeyHeader)), first(md, "authorization"))
+	if err != nil {
+		a.limits.failed(ctx)
+		return nil, toStatus(err)
+	}
+
+	role, ok := a.roles[method]
+	if !ok && strings.HasPrefix(method, "/"+musicv1.MusicLibrary_ServiceDesc.ServiceName+"/") {
+		return nil, toStatus(base.Forbidden("Method %s has no role", method))
+	}
+	if ok && !principal.Allows(role) {
+		if principal.Role == auth.Anonymous {
+			return nil, toStatus(base.Unauthenticated("Authentication required"))
+		}
+		return nil, toStatus(base.Forbidden("Role %s is required", role))
+	}
+	if err := a.limits.take(ctx, principal, method); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (a authorizer) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a authorizer) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,124 @@
+package rpc
+
+import (
+	"context"
+	musicv1 "music/api/music/v1"
+	"music/internal/auth"
+	"music/internal/config"
+	"music/internal/ratelimit"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type testConfig struct {
+	config.Config
+}
+
+func (testConfig) GetAPIKeys() map[string]string {
+	return map[string]string{"admin-key": "admin"}
+}
+
+func (testConfig) GetJWKSFile() string {
+	return ""
+}
+
+func TestAuthorize(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(testConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := authorizer{
+		auth:   authenticator,
+		roles:  map[string]auth.Role{musicv1.MusicLibrary_GetSong_FullMethodName: auth.Anonymous},
+		limits: testLimiter(),
+	}
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "admin-key"))
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{name: "public method", ctx: context.Background(), method: musicv1.MusicLibrary_GetSong_FullMethodName, want: codes.OK},
+		{name: "method without a role", ctx: admin, method: musicv1.MusicLibrary_AddSong_FullMethodName, want: codes.PermissionDenied},
+		{name: "other service", ctx: context.Background(), method: "/grpc.health.v1.Health/Check", want: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.authorize(tt.ctx, tt.method)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("authorize() code = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func testLimiter() limiter {
+	return limiter{
+		store:     ratelimit.NewMemoryStore(time.Minute),
+		normal:    ratelimit.Limit{Rate: 0.001, Burst: 3},
+		expensive: ratelimit.Limit{Rate: 0.001, Burst: 1},
+		scans:     map[string]bool{musicv1.MusicLibrary_ListSongs_FullMethodName: true},
+	}
+}
+
+func TestAuthorizeRateLimited(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(testConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles := map[string]auth.Role{
+		musicv1.MusicLibrary_GetSong_FullMethodName:   auth.Anonymous,
+		musicv1.MusicLibrary_ListSongs_FullMethodName: auth.Anonymous,
+	}
+	from := func(ip string, pairs ...string) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50051}})
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(pairs...))
+	}
+
+	tests := []struct {
+		name   string
+		calls  []context.Context
+		method string
+		want   []codes.Code
+	}{
+		{
+			name:   "default limit",
+			calls:  []context.Context{from("10.0.0.1"), from("10.0.0.1"), from("10.0.0.1"), from("10.0.0.1"), from("10.0.0.2")},
+			method: musicv1.MusicLibrary_GetSong_FullMethodName,
+			want:   []codes.Code{codes.OK, codes.OK, codes.OK, codes.ResourceExhausted, codes.OK},
+		},
+		{
+			name:   "expensive method",
+			calls:  []context.Context{from("10.0.0.1"), from("10.0.0.1")},
+			method: musicv1.MusicLibrary_ListSongs_FullMethodName,
+			want:   []codes.Code{codes.OK, codes.ResourceExhausted},
+		},
+		{
+			name:   "failed authentication",
+			calls:  []context.Context{from("10.0.0.1", "x-api-key", "wrong"), from("10.0.0.1", "x-api-key", "admin-key"), from("10.0.0.2", "x-api-key", "admin-key")},
+			method: musicv1.MusicLibrary_GetSong_FullMethodName,
+			want:   []codes.Code{codes.Unauthenticated, codes.ResourceExhausted, codes.OK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := authorizer{auth: authenticator, roles: roles, limits: testLimiter()}
+			for i, ctx := range tt.calls {
+				_, err := a.authorize(ctx, tt.method)
+				if got := status.Code(err); got != tt.want[i] {
+					t.Errorf("call %d: authorize() code = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"context"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/ratelimit"
+	"net"
+
+	"google.golang.org/grpc/peer"
+)
+
+// limiter applies the rate limits of the HTTP API to the calls: the default
+// limit to every call and the expensive one additionally to the methods that
+// scan the whole table. Failed authentication attempts are charged to the
+// address of the caller with the expensive limit, like on the HTTP port.
+type limiter struct {
+	store     ratelimit.Store
+	normal    ratelimit.Limit
+	expensive ratelimit.Limit
+	scans     map[string]bool
+}
+
+// throttled reports whether the address of the caller has exhausted its
+// failed authentication attempts.
+func (l limiter) throttled(ctx context.Context) error {
+	result, err := l.store.Peek(ctx, "unauthenticated:"+address(ctx), l.expensive)
+	if err != nil {
+		log.Printf("Rate limiter is unavailable, request allowed. Error: %s", err.Error())
+		return nil
+	}
+
+	return refused(result)
+}
+
+// failed charges a failed authentication attempt to the address of the
+// caller.
+func (l limiter) failed(ctx context.Context) {
+	if _, err := l.store.Take(ctx, "unauthenticated:"+address(ctx), l.expensive); err != nil {
+		log.Printf("Rate limiter is unavailable, failed authentication not counted. Error: %s", err.Error())
+	}
+}
+
+// take charges the call of method to the caller.
+func (l limiter) take(ctx context.Context, principal auth.Principal, method string) error {
+	client := principal.Subject
+	if client == "" {
+		client = address(ctx)
+	}
+
+	if err := l.limited(ctx, "default:"+client, l.normal); err != nil {
+		return err
+	}
+	if l.scans[method] {
+		return l.limited(ctx, "expensive:"+client, l.expensive)
+	}
+
+	return nil
+}
+
+func (l limiter) limited(ctx context.Context, key string, limit ratelimit.Limit) error {
+	result, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		log.Printf("Rate limiter is unavailable, request allowed. Error: %s", err.Error())
+		return nil
+	}
+
+	return refused(result)
+}
+
+func refused(result ratelimit.Result) error {
+	if result.Allowed {
+		return nil
+	}
+
+	return base.RateLimited("Too many requests, retry in %d seconds", int(result.RetryAfter.Seconds()))
+}
+
+// address identifies an anonymous caller by the IP address of the peer.
+func address(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
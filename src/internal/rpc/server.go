@@ -0,0 +1,243 @@
+// Package rpc serves the song API over gRPC, sharing the repository,
+// validation and authentication with the HTTP handlers.
+package rpc
+
+import (
+	"context"
+	"log"
+	musicv1 "music/api/music/v1"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/config"
+	"music/internal/dto"
+	"music/internal/lyrics"
+	"music/internal/model"
+	"music/internal/ratelimit"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	defaultBatchSize = 100
+	maxBatchSize     = 1000
+	defaultPageSize  = 20
+)
+
+type server struct {
+	musicv1.UnimplementedMusicLibraryServer
+	repo base.Repository
+}
+
+// NewServer creates a gRPC server with the MusicLibrary, health and
+// reflection services.
+func NewServer(cfg config.Config, repo base.Repository, authenticator *auth.Authenticator) *grpc.Server {
+	read := auth.Reader
+	if cfg.GetPublicReads() {
+		read = auth.Anonymous
+	}
+	a := authorizer{
+		auth: authenticator,
+		roles: map[string]auth.Role{
+			musicv1.MusicLibrary_AddSong_FullMethodName:     auth.Editor,
+			musicv1.MusicLibrary_UpdateSong_FullMethodName:  auth.Editor,
+			musicv1.MusicLibrary_DeleteSong_FullMethodName:  auth.Editor,
+			musicv1.MusicLibrary_GetSong_FullMethodName:     read,
+			musicv1.MusicLibrary_ListSongs_FullMethodName:   read,
+			musicv1.MusicLibrary_FilterSongs_FullMethodName: read,
+			musicv1.MusicLibrary_GetLyrics_FullMethodName:   read,
+		},
+		limits: limiter{
+			store: ratelimit.NewMemoryStore(10 * time.Minute),
+			scans: map[string]bool{
+				musicv1.MusicLibrary_ListSongs_FullMethodName:   true,
+				musicv1.MusicLibrary_FilterSongs_FullMethodName: true,
+			},
+		},
+	}
+	rps, burst := cfg.GetRateLimit()
+	a.limits.normal = ratelimit.Limit{Rate: rps, Burst: burst}
+	rps, burst = cfg.GetExpensiveRateLimit()
+	a.limits.expensive = ratelimit.Limit{Rate: rps, Burst: burst}
+
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(a.unary), grpc.ChainStreamInterceptor(a.stream))
+	musicv1.RegisterMusicLibraryServer(s, &server{repo: repo})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(musicv1.MusicLibrary_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+	reflection.Register(s)
+
+	return s
+}
+
+func toSong(song model.Song) *musicv1.Song {
+	return &musicv1.Song{
+		Id:          uint32(song.ID),
+		Group:       song.Group_name,
+		Song:        song.Song,
+		Album:       song.Album,
+		ReleaseDate: song.ReleaseDate,
+		Text:        song.Lyrics,
+	}
+}
+
+// validated runs the normalization and validation of the HTTP payloads.
+func validated(request interface {
+	Normalize()
+	Validate() error
+}) error {
+	request.Normalize()
+	return request.Validate()
+}
+
+func (s *server) AddSong(ctx context.Context, req *musicv1.AddSongRequest) (*musicv1.Song, error) {
+	request := dto.SongRequest{
+		Group:       req.GetGroup(),
+		Song:        req.GetSong(),
+		Album:       req.GetAlbum(),
+		ReleaseDate: req.GetReleaseDate(),
+		Text:        req.GetText(),
+	}
+	if err := validated(&request); err != nil {
+		return nil, toStatus(err)
+	}
+	newSong := request.Model()
+
+	song, err := s.repo.AddSong(ctx, newSong)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toSong(song), nil
+}
+
+func (s *server) GetSong(ctx context.Context, req *musicv1.GetSongRequest) (*musicv1.Song, error) {
+	if req.GetId() == 0 {
+		return nil, toStatus(base.Validation("Invalid id"))
+	}
+
+	song, err := s.repo.GetSong(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toSong(song), nil
+}
+
+func (s *server) UpdateSong(ctx context.Context, req *musicv1.UpdateSongRequest) (*emptypb.Empty, error) {
+	request := dto.SongUpdateRequest{
+		Group:       req.NewGroup,
+		Song:        req.NewSong,
+		Album:       req.Album,
+		ReleaseDate: req.ReleaseDate,
+		Text:        req.Text,
+	}
+	if err := validated(&request); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.repo.UpdateSong(ctx, req.GetGroup(), req.GetSong(), request.Model()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) DeleteSong(ctx context.Context, req *musicv1.DeleteSongRequest) (*emptypb.Empty, error) {
+	if err := s.repo.DeleteSong(ctx, req.GetGroup(), req.GetSong()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) ListSongs(req *musicv1.ListSongsRequest, stream grpc.ServerStreamingServer[musicv1.Song]) error {
+	size := int(req.GetBatchSize())
+	if size == 0 {
+		size = defaultBatchSize
+	}
+	if size < 0 || size > maxBatchSize {
+		return toStatus(base.Validation("Invalid batch size, expected 1 to %d", maxBatchSize))
+	}
+
+	for page := 1; ; page++ {
+		songs, err := s.repo.GetLibraryWithPagination(stream.Context(), page, size)
+		if err != nil {
+			return toStatus(err)
+		}
+		for _, song := range songs {
+			if err := stream.Send(toSong(song)); err != nil {
+				log.Printf("Failed to stream songs. Error: %s", err.Error())
+				return err
+			}
+		}
+		if len(songs) < size {
+			return nil
+		}
+	}
+}
+
+func (s *server) FilterSongs(ctx context.Context, req *musicv1.FilterSongsRequest) (*musicv1.FilterSongsResponse, error) {
+	page, size := int(req.GetPage()), int(req.GetSize())
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = defaultPageSize
+	}
+	if page < 1 || size < 1 {
+		return nil, toStatus(base.Validation("Invalid page number or size"))
+	}
+
+	filter := dto.SongFilter{
+		Group:       req.GetGroup(),
+		Song:        req.GetSong(),
+		Album:       req.GetAlbum(),
+		ReleaseDate: req.GetReleaseDate(),
+		Tags:        req.GetTags(),
+		Sort:        req.GetSort(),
+	}
+	switch req.GetTagMode() {
+	case musicv1.TagMode_TAG_MODE_ALL:
+		filter.TagMode = "all"
+	case musicv1.TagMode_TAG_MODE_ANY:
+		filter.TagMode = "any"
+	}
+
+	songs, err := s.repo.FindWithFilterAndPagination(ctx, filter.Query(), page, size)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &musicv1.FilterSongsResponse{Songs: make([]*musicv1.Song, 0, len(songs))}
+	for _, song := range songs {
+		response.Songs = append(response.Songs, toSong(song))
+	}
+
+	return response, nil
+}
+
+func (s *server) GetLyrics(ctx context.Context, req *musicv1.GetLyricsRequest) (*musicv1.GetLyricsResponse, error) {
+	song, variants, err := s.repo.GetVariantsByName(ctx, req.GetGroup(), req.GetSong())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	languages := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		languages = append(languages, variant.Language)
+	}
+	if i, ok := lyrics.Match(languages, req.GetLang()); ok {
+		return &musicv1.GetLyricsResponse{Language: variants[i].Language, Text: variants[i].Text}, nil
+	}
+	if len(variants) > 0 && variants[0].Original {
+		return &musicv1.GetLyricsResponse{Language: variants[0].Language, Text: variants[0].Text}, nil
+	}
+
+	return &musicv1.GetLyricsResponse{Language: "und", Text: song.Lyrics}, nil
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"errors"
+	"log"
+	"music/internal/base"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var codeByKind = map[base.Kind]codes.Code{
+	base.KindNotFound:    codes.NotFound,
+	base.KindConflict:    codes.AlreadyExists,
+	base.KindValidation:  codes.InvalidArgument,
+	base.KindUnavailable: codes.Unavailable,
+	base.KindTimeout:     codes.DeadlineExceeded,
+
+	base.KindUnauthenticated: codes.Unauthenticated,
+	base.KindForbidden:       codes.PermissionDenied,
+	base.KindRateLimited:     codes.ResourceExhausted,
+}
+
+// toStatus converts err to a gRPC status the way the HTTP handlers write
+// problem responses: domain errors keep their message and violations, other
+// errors are logged and reported without details.
+func toStatus(err error) error {
+	var domain *base.Error
+	if !errors.As(err, &domain) {
+		log.Println(err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+	code, ok := codeByKind[domain.Kind]
+	if !ok {
+		log.Println(err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+
+	st := status.New(code, domain.Message)
+	if len(domain.Violations) == 0 {
+		return st.Err()
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(domain.Violations))
+	for _, v := range domain.Violations {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Message})
+	}
+	detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
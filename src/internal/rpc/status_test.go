@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"music/internal/base"
+	"os"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{name: "not found", err: base.NotFound("Song not found"), code: codes.NotFound, message: "Song not found"},
+		{name: "conflict", err: base.Conflict("Song already exists"), code: codes.AlreadyExists, message: "Song already exists"},
+		{
+			name:    "wrapped",
+			err:     fmt.Errorf("Failed to get song. Error: %w", base.Unavailable(errors.New("connection refused"))),
+			code:    codes.Unavailable,
+			message: "database is unavailable",
+		},
+		{name: "rate limited", err: base.RateLimited("Too many requests"), code: codes.ResourceExhausted, message: "Too many requests"},
+		{name: "unmapped kind", err: base.Unprocessable("secret"), code: codes.Internal, message: "Internal server error"},
+		{name: "unknown error", err: errors.New("secret"), code: codes.Internal, message: "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(toStatus(tt.err))
+			if st.Code() != tt.code || st.Message() != tt.message {
+				t.Errorf("toStatus() = %v %q, want %v %q", st.Code(), st.Message(), tt.code, tt.message)
+			}
+			if len(st.Details()) != 0 {
+				t.Errorf("toStatus() details = %v, want none", st.Details())
+			}
+		})
+	}
+}
+
+func TestToStatusViolations(t *testing.T) {
+	err := base.Invalid([]base.Violation{{Field: "group", Message: "is required"}, {Field: "song", Message: "is required"}})
+	st := status.Convert(toStatus(err))
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("toStatus() code = %v, want %v", st.Code(), codes.InvalidArgument)
+	}
+
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("toStatus() details = %v, want a bad request", details)
+	}
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	if !ok {
+		t.Fatalf("toStatus() detail = %T, want *errdetails.BadRequest", details[0])
+	}
+	violations := badRequest.GetFieldViolations()
+	if len(violations) != 2 || violations[0].GetField() != "group" || violations[1].GetDescription() != "is required" {
+		t.Errorf("toStatus() violations = %v, want group and song", violations)
+	}
+}
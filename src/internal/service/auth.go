@@ -0,0 +1,66 @@
+package service
+
+import (
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"net/http"
+)
+
+// authenticate resolves the caller of every request and stores it in the
+// request context. Invalid credentials are rejected even on public routes.
+// Failed attempts are charged to the address of the caller, which has no
+// verified identity yet, with the expensive limit. Once it is exhausted the
+// address is refused before its credentials are looked up.
+func (s *service) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := "unauthenticated:" + client(r)
+		result, err := s.limiter.Peek(r.Context(), key, s.limits.expensive)
+		if err != nil {
+			log.Printf("Rate limiter is unavailable, request allowed. Error: %s", err.Error())
+		} else if !result.Allowed {
+			s.admit(w, r, result)
+			return
+		}
+
+		principal, err := s.auth.Authenticate(r)
+		if err != nil {
+			if _, err := s.limiter.Take(r.Context(), key, s.limits.expensive); err != nil {
+				log.Printf("Rate limiter is unavailable, failed authentication not counted. Error: %s", err.Error())
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="music"`)
+			s.problem(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// require wraps a handler that may only be used by callers with at least role.
+func (s *service) require(role auth.Role, h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := auth.FromContext(r.Context())
+		if principal.Allows(role) {
+			h(w, r)
+			return
+		}
+
+		if principal.Role == auth.Anonymous {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="music"`)
+			s.problem(w, r, base.Unauthenticated("Authentication required"))
+			return
+		}
+
+		s.problem(w, r, base.Forbidden("Role %s is required", role))
+	})
+}
+
+// readRole is the role required by read-only routes.
+func (s *service) readRole() auth.Role {
+	if s.cfg.GetPublicReads() {
+		return auth.Anonymous
+	}
+
+	return auth.Reader
+}
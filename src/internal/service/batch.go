@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/dto"
+	"music/internal/model"
+	"net/http"
+)
+
+func (s *service) setupBatchRoutes() {
+	s.router.Handle("/music/batch", s.expensive(s.require(auth.Editor, s.Batch))).Methods("POST")
+}
+
+// BatchResponse is the outcome of a batch. Committed is false when an atomic
+// batch was rolled back.
+type BatchResponse struct {
+	Committed bool                   `json:"committed"`
+	Results   []BatchOperationResult `json:"results"`
+}
+
+// BatchOperationResult is the outcome of an operation, in the order of the
+// request. Songs lists the songs the operation wrote.
+type BatchOperationResult struct {
+	Index  int          `json:"index"`
+	Op     string       `json:"op"`
+	Status int          `json:"status"`
+	Songs  []model.Song `json:"songs,omitempty"`
+	Error  *Problem     `json:"error,omitempty"`
+}
+
+// Batch применяет пакет изменений песен
+// @Summary Пакетные изменения
+// @Description Выполняет до 500 операций create, update и delete по порядку. Песни адресуются по id или по group и song, values содержит новую песню или измененные поля. В режиме atomic (по умолчанию) пакет выполняется одной транзакцией и откатывается первой неудачной операцией, в режиме per_item каждая операция выполняется отдельно. Результат каждой операции содержит статус: 201 для create, 200 для update и delete, статус ошибки для неудачной операции и 424 для операций откаченного пакета
+// @Tags music
+// @Accept json
+// @Produce json
+// @Param batch body dto.BatchRequest true "Операции"
+// @Param Idempotency-Key header string false "Ключ идемпотентности, повтор с тем же ключом возвращает сохраненный ответ"
+// @Success 200 {object} BatchResponse "Результаты операций"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 409 {object} Problem "A request with the Idempotency-Key is in progress"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 422 {object} Problem "Idempotency-Key was used with a different request"
+// @Failure 429 {object} Problem "Too many requests"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /music/batch [post]
+func (s *service) Batch(w http.ResponseWriter, r *http.Request) {
+	var request dto.BatchRequest
+	if err := decode(w, r, &request, maxBatchSize); err != nil {
+		log.Printf("Error decoding JSON: %v", err)
+		s.problem(w, r, err)
+		return
+	}
+
+	ops := request.Model()
+	results, err := s.repo.BatchSongs(r.Context(), ops, request.Atomic())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	response := BatchResponse{Committed: true, Results: make([]BatchOperationResult, 0, len(ops))}
+	failed := -1
+	for i, result := range results {
+		if result.Err != nil {
+			log.Println(result.Err)
+			if request.Atomic() {
+				response.Committed = false
+				failed = i
+			}
+		}
+	}
+	for i, result := range results {
+		item := BatchOperationResult{Index: i, Op: ops[i].Op, Songs: result.Songs}
+		switch {
+		case result.Err != nil:
+			p := newProblem(r, result.Err)
+			item.Status, item.Error = p.Status, &p
+		case !response.Committed:
+			p := newProblem(r, base.FailedDependency("Not applied, operation %d failed and the batch was rolled back", failed))
+			item.Status, item.Error = p.Status, &p
+		case ops[i].Op == model.BatchCreate:
+			item.Status = http.StatusCreated
+		default:
+			item.Status = http.StatusOK
+		}
+		response.Results = append(response.Results, item)
+	}
+	log.Printf("Batch of %d operations done, committed: %t", len(ops), response.Committed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"expvar"
+	"fmt"
+	"music/internal/auth"
+	"net/http"
+)
+
+func (s *service) setupMetricsRoutes() {
+	s.router.Handle("/debug/vars", s.require(auth.Admin, expvar.Handler().ServeHTTP)).Methods("GET")
+}
+
+// cacheable lets clients reuse the responses of cached queries for the cache
+// TTL. Responses differ by caller only when reads require authentication,
+// shared caches may store them otherwise. Problems are never stored.
+func (s *service) cacheable(next http.Handler) http.Handler {
+	ttl, size := s.cfg.GetCache()
+	policy := "no-cache"
+	if ttl > 0 && size > 0 {
+		scope := "private"
+		if s.cfg.GetPublicReads() {
+			scope = "public"
+		}
+		policy = fmt.Sprintf("%s, max-age=%d", scope, int(ttl.Seconds()))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", policy)
+		w.Header().Add("Vary", "Authorization, X-API-Key")
+		next.ServeHTTP(w, r)
+	})
+}
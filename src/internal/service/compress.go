@@ -0,0 +1,210 @@
+package service
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// minCompressSize is the smallest body worth compressing, smaller bodies
+// are sent as is.
+const minCompressSize = 1024
+
+// encoder is the common part of the gzip, brotli and zstd writers.
+type encoder interface {
+	io.Writer
+	Flush() error
+	Close() error
+	Reset(w io.Writer)
+}
+
+// encodings are the supported content codings in the order of preference.
+var encodings = []string{"zstd", "br", "gzip"}
+
+var encoders = map[string]*sync.Pool{
+	"zstd": {New: func() any {
+		e, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return e
+	}},
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(nil, 5)
+	}},
+	"gzip": {New: func() any {
+		e, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return e
+	}},
+}
+
+// acceptEncoding picks the supported coding the Accept-Encoding header
+// prefers, or "" for an uncompressed response.
+func acceptEncoding(header string) string {
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, coding := range parseWeighted(header) {
+		if coding.value == "*" {
+			wildcard = coding.quality
+			continue
+		}
+		qualities[coding.value] = coding.quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = max(wildcard, 0)
+		}
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+
+	return best
+}
+
+// compress encodes responses with the coding negotiated by Accept-Encoding.
+// Event streams, empty and small bodies, and bodies the handler encoded
+// itself are sent as is.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := acceptEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer func() {
+			if err := cw.Close(); err != nil {
+				log.Printf("Failed to compress response. Error: %s", err)
+			}
+		}()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter holds back the start of the body until it is large enough
+// to compress, or the handler is done or flushes.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	status   int
+	buf      []byte
+	encoder  encoder
+	// passthrough is set once the response is sent uncompressed.
+	passthrough bool
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+	if !compressible(cw.Header(), status) {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(p)
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= minCompressSize {
+		if err := cw.start(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends what was written so far, compressed if the response is.
+func (cw *compressWriter) Flush() {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.passthrough {
+		if cw.encoder == nil {
+			if err := cw.start(); err != nil {
+				return
+			}
+		}
+		if err := cw.encoder.Flush(); err != nil {
+			return
+		}
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// start sends the header of the compressed response and the buffered start
+// of the body.
+func (cw *compressWriter) start() error {
+	if cw.Header().Get("Content-Type") == "" {
+		// net/http would sniff the compressed bytes.
+		cw.Header().Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	cw.Header().Set("Content-Encoding", cw.encoding)
+	cw.Header().Del("Content-Length")
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	cw.encoder = encoders[cw.encoding].Get().(encoder)
+	cw.encoder.Reset(cw.ResponseWriter)
+	_, err := cw.encoder.Write(cw.buf)
+	cw.buf = nil
+
+	return err
+}
+
+// Close ends the body, a body too small to compress is sent as is.
+func (cw *compressWriter) Close() error {
+	switch {
+	case cw.status == 0 || cw.passthrough:
+		return nil
+	case cw.encoder == nil:
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(cw.status)
+		_, err := cw.ResponseWriter.Write(cw.buf)
+		return err
+	}
+
+	err := cw.encoder.Close()
+	encoders[cw.encoding].Put(cw.encoder)
+	cw.encoder = nil
+
+	return err
+}
+
+func compressible(header http.Header, status int) bool {
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	return !strings.HasPrefix(header.Get("Content-Type"), "text/event-stream")
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "identity", want: ""},
+		{header: "gzip", want: "gzip"},
+		{header: "gzip, deflate, br", want: "br"},
+		{header: "gzip, br, zstd", want: "zstd"},
+		{header: "br;q=0.5, gzip", want: "gzip"},
+		{header: "GZIP", want: "gzip"},
+		{header: "*", want: "zstd"},
+		{header: "*, zstd;q=0", want: "br"},
+		{header: "*;q=0, gzip;q=0.1", want: "gzip"},
+		{header: "gzip;q=0, br;q=0", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := acceptEncoding(tt.header); got != tt.want {
+				t.Errorf("acceptEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+// decodeBody reads the body sent with the content coding.
+func decodeBody(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var r io.Reader
+	switch encoding {
+	case "":
+		return string(body)
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		t.Fatalf("unexpected Content-Encoding %s", encoding)
+	}
+
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to decode %s body. Error: %s", encoding, err)
+	}
+	return string(decoded)
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("Hysteria ", minCompressSize/4)
+	tests := []struct {
+		name         string
+		method       string
+		accept       string
+		handler      http.HandlerFunc
+		wantEncoding string
+		wantBody     string
+		wantType     string
+	}{
+		{
+			name:   "large body",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				io.WriteString(w, large)
+			},
+			wantEncoding: "gzip",
+			wantBody:     large,
+			wantType:     "text/plain",
+		},
+		{
+			name:   "large body in small writes",
+			accept: "br",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				for _, word := range strings.SplitAfter(large, " ") {
+					io.WriteString(w, word)
+				}
+			},
+			wantEncoding: "br",
+			wantBody:     large,
+			wantType:     "text/plain; charset=utf-8",
+		},
+		{
+			name:   "zstd",
+			accept: "zstd, gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, large)
+			},
+			wantEncoding: "zstd",
+			wantBody:     large,
+			wantType:     "application/json",
+		},
+		{
+			name:   "small body",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, `{"song":"Hysteria"}`)
+			},
+			wantBody: `{"song":"Hysteria"}`,
+			wantType: "application/json",
+		},
+		{
+			name:   "not accepted",
+			accept: "",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, large)
+			},
+			wantBody: large,
+			wantType: "text/plain; charset=utf-8",
+		},
+		{
+			name:   "event stream",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, large)
+			},
+			wantBody: large,
+			wantType: "text/event-stream",
+		},
+		{
+			name:   "encoded by the handler",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Encoding", "identity")
+				io.WriteString(w, large)
+			},
+			wantEncoding: "identity",
+			wantBody:     large,
+			wantType:     "text/plain",
+		},
+		{
+			name:   "no content",
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			name:   "head",
+			method: http.MethodHead,
+			accept: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+			},
+			wantType: "text/plain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "/music", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Encoding", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			compress(tt.handler).ServeHTTP(w, r)
+
+			encoding := w.Header().Get("Content-Encoding")
+			if encoding != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", encoding, tt.wantEncoding)
+			}
+			if encoding == "identity" {
+				encoding = ""
+			}
+			if body := decodeBody(t, encoding, w.Body.Bytes()); body != tt.wantBody {
+				t.Errorf("body = %.40q, want %.40q", body, tt.wantBody)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if vary := w.Header().Values("Vary"); !reflect.DeepEqual(vary, []string{"Accept-Encoding"}) {
+				t.Errorf("Vary = %v, want Accept-Encoding", vary)
+			}
+		})
+	}
+}
+
+// TestCompressFlush checks that a flush sends a small body compressed at
+// once, streams do not wait for the buffer to fill.
+func TestCompressFlush(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/music", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, `{"song":"Hysteria"}`+"\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+		if !w.(*compressWriter).ResponseWriter.(*httptest.ResponseRecorder).Flushed {
+			t.Error("the response is not flushed")
+		}
+		io.WriteString(w, `{"song":"Uprising"}`+"\n")
+	})).ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", got)
+	}
+	if body := decodeBody(t, "gzip", w.Body.Bytes()); body != `{"song":"Hysteria"}`+"\n"+`{"song":"Uprising"}`+"\n" {
+		t.Errorf("body = %q, want both songs", body)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"music/internal/base"
+	"music/internal/feed"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const heartbeat = 15 * time.Second
+
+func (s *service) setupEventRoutes() {
+	s.router.Handle("/music/events", s.require(s.readRole(), s.Events)).Methods("GET")
+}
+
+// Events транслирует изменения библиотеки
+// @Summary Поток изменений
+// @Description Server-Sent Events с событиями song.created, song.updated и song.deleted. Данные события — конверт с полями id, type, created_at и data. После переподключения с заголовком Last-Event-ID (или параметром last_event_id) поток продолжается с пропущенных событий, если они еще в буфере, иначе сначала отправляется событие reset: клиент должен перезагрузить данные. Поток получает только события, опубликованные этим экземпляром сервера. Каждые 15 секунд отправляется комментарий heartbeat
+// @Tags music
+// @Produce text/event-stream
+// @Param group query []string false "Только события этих групп" collectionFormat(multi)
+// @Param last_event_id query int false "ID последнего полученного события"
+// @Param Last-Event-ID header int false "ID последнего полученного события"
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {object} Problem "Invalid last event id"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /music/events [get]
+func (s *service) Events(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	groups := query["group"]
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = query.Get("last_event_id")
+	}
+	var last uint64
+	if lastID != "" {
+		var err error
+		if last, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			s.problem(w, r, base.Validation("Invalid last event id"))
+			return
+		}
+	}
+
+	backlog, missed, sub := s.feed.Subscribe(last)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	send := func(event feed.Event) error {
+		if len(groups) > 0 && !slices.ContainsFunc(groups, func(group string) bool { return strings.EqualFold(group, event.Group) }) {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, event.Data)
+		return err
+	}
+	if missed {
+		fmt.Fprintf(w, "event: %s\ndata: {\"type\":%q}\n\n", feed.Reset, feed.Reset)
+	}
+	for _, event := range backlog {
+		if err := send(event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		log.Println("Event stream is not supported:", err)
+		return
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"music/internal/model"
+	"strings"
+)
+
+// songURI identifies a song of the library in exported playlists.
+func songURI(id uint) string {
+	return fmt.Sprintf("urn:music:song:%d", id)
+}
+
+// writeM3U writes the playlist as extended M3U.
+func writeM3U(w io.Writer, playlist model.Playlist, entries []model.PlaylistEntry) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	fmt.Fprintf(&b, "#PLAYLIST:%s\n", oneLine(playlist.Name))
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "#EXTINF:-1,%s - %s\n", oneLine(entry.Song.Group_name), oneLine(entry.Song.Song))
+		b.WriteString(songURI(entry.SongID) + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type xspfTrack struct {
+	Location   string `xml:"location"`
+	Identifier string `xml:"identifier"`
+	Title      string `xml:"title"`
+	Creator    string `xml:"creator"`
+	TrackNum   int    `xml:"trackNum"`
+}
+
+type xspfPlaylist struct {
+	XMLName    xml.Name    `xml:"playlist"`
+	Version    string      `xml:"version,attr"`
+	Namespace  string      `xml:"xmlns,attr"`
+	Title      string      `xml:"title"`
+	Annotation string      `xml:"annotation,omitempty"`
+	Tracks     []xspfTrack `xml:"trackList>track"`
+}
+
+// writeXSPF writes the playlist as XSPF version 1.
+func writeXSPF(w io.Writer, playlist model.Playlist, entries []model.PlaylistEntry) error {
+	doc := xspfPlaylist{
+		Version:    "1",
+		Namespace:  "http://xspf.org/ns/0/",
+		Title:      playlist.Name,
+		Annotation: playlist.Description,
+		Tracks:     make([]xspfTrack, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		doc.Tracks = append(doc.Tracks, xspfTrack{
+			Location:   songURI(entry.SongID),
+			Identifier: songURI(entry.SongID),
+			Title:      entry.Song.Song,
+			Creator:    entry.Song.Group_name,
+			TrackNum:   entry.Position + 1,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func oneLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
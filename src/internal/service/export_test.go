@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"music/internal/model"
+	"testing"
+)
+
+var exported = model.Playlist{Name: "Road\ntrip", Description: "Songs & more"}
+
+var exportedEntries = []model.PlaylistEntry{
+	{SongID: 7, Position: 0, Song: model.Song{Group_name: "Muse", Song: "Hysteria"}},
+	{SongID: 3, Position: 1, Song: model.Song{Group_name: "AC/DC", Song: "Back  in\tBlack"}},
+}
+
+func TestWriteM3U(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []model.PlaylistEntry
+		want    string
+	}{
+		{name: "empty", want: "#EXTM3U\n#PLAYLIST:Road trip\n"},
+		{
+			name:    "entries",
+			entries: exportedEntries,
+			want: "#EXTM3U\n#PLAYLIST:Road trip\n" +
+				"#EXTINF:-1,Muse - Hysteria\nurn:music:song:7\n" +
+				"#EXTINF:-1,AC/DC - Back in Black\nurn:music:song:3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := writeM3U(&b, exported, tt.entries); err != nil {
+				t.Fatalf("writeM3U() error = %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("writeM3U() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteXSPF(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []model.PlaylistEntry
+		want    string
+	}{
+		{
+			name: "empty",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Road&#xA;trip</title>
+  <annotation>Songs &amp; more</annotation>
+  <trackList></trackList>
+</playlist>`,
+		},
+		{
+			name:    "entries",
+			entries: exportedEntries[:1],
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Road&#xA;trip</title>
+  <annotation>Songs &amp; more</annotation>
+  <trackList>
+    <track>
+      <location>urn:music:song:7</location>
+      <identifier>urn:music:song:7</identifier>
+      <title>Hysteria</title>
+      <creator>Muse</creator>
+      <trackNum>1</trackNum>
+    </track>
+  </trackList>
+</playlist>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := writeXSPF(&b, exported, tt.entries); err != nil {
+				t.Fatalf("writeXSPF() error = %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("writeXSPF() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
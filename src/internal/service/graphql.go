@@ -0,0 +1,35 @@
+package service
+
+import (
+	"music/internal/graph"
+	"net/http"
+)
+
+func (s *service) setupGraphQLRoutes() {
+	handler, err := graph.NewHandler(s.repo)
+	if err != nil {
+		// The schema is static, so it only fails to build on a programming error.
+		panic(err)
+	}
+
+	s.router.Handle("/graphql", s.expensive(s.require(s.readRole(), s.GraphQL(handler)))).Methods("GET", "POST")
+	s.router.PathPrefix("/graphiql/").Handler(graph.GraphiQL("/graphql"))
+}
+
+// GraphQL выполняет запрос к каталогу на GraphQL
+// @Summary GraphQL
+// @Description Схема описывает песни, исполнителей, альбомы, теги и тексты. Песни фильтруются, сортируются и разбиваются на страницы так же, как в /music/filter. Запросы глубже 8 уровней или с оценкой более 20000 полей (списки считаются по их size, списки без size, например теги, — по 10) отклоняются до выполнения. На запросы действует лимит тяжелых запросов. IDE GraphiQL доступна на /graphiql/
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body graph.Request true "GraphQL-запрос"
+// @Success 200 {object} object "Результат с полями data и errors"
+// @Failure 400 {object} object "Malformed request or the query exceeds the depth or complexity limit"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /graphql [post]
+func (s *service) GraphQL(handler http.Handler) http.HandlerFunc {
+	return handler.ServeHTTP
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"music/internal/base"
+	"music/internal/model"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxIdempotencyKey = 255
+	// maxReplayedBody bounds the stored responses, larger ones are not kept.
+	maxReplayedBody = 1 << 20
+	// idempotencyPurge is how often the expired keys are dropped.
+	idempotencyPurge = 10 * time.Minute
+)
+
+// unreplayedHeaders describe the current request rather than the stored
+// response.
+var unreplayedHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"}
+
+// idempotent runs a POST or PATCH with an Idempotency-Key once per caller and
+// key, retries within the TTL get the stored response. A retry with another
+// request is rejected with 422, a retry while the first request still runs
+// with 409. Responses marked no-store, which includes every error, are not
+// kept: the key is released and a retry runs the request again.
+func (s *service) idempotent(next http.Handler) http.Handler {
+	ttl := s.cfg.GetIdempotencyTTL()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if ttl <= 0 || key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			s.problem(w, r, base.Validation("Idempotency-Key must be 1 to %d visible ASCII characters", maxIdempotencyKey))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.problem(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		reservation := model.IdempotencyKey{
+			Scope:       client(r),
+			Key:         key,
+			RequestHash: requestHash(r, body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+		stored, reserved, err := s.repo.ReserveIdempotencyKey(r.Context(), reservation)
+		if err != nil {
+			log.Println(err)
+			s.problem(w, r, err)
+			return
+		}
+		if !reserved {
+			switch {
+			case stored.RequestHash != reservation.RequestHash:
+				s.problem(w, r, base.Unprocessable("Idempotency-Key %s was used with a different request", key))
+			case stored.Status == 0:
+				w.Header().Set("Retry-After", "1")
+				s.problem(w, r, base.Conflict("A request with Idempotency-Key %s is in progress", key))
+			default:
+				replay(w, stored)
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		// The response is stored even if the client is gone, its retry
+		// must not find the key in progress until it expires.
+		ctx := context.WithoutCancel(r.Context())
+		panicked := true
+		defer func() {
+			if !panicked && recorder.status == 0 {
+				// Nothing was written, net/http sends 200 with an empty body.
+				recorder.WriteHeader(http.StatusOK)
+			}
+			if panicked || !recorder.replayable() {
+				if err := s.repo.ReleaseIdempotencyKey(ctx, reservation.Scope, key); err != nil {
+					log.Println(err)
+				}
+				return
+			}
+
+			reservation.Status = recorder.status
+			reservation.Body = recorder.body.Bytes()
+			reservation.Header, _ = json.Marshal(recorder.header)
+			if err := s.repo.CompleteIdempotencyKey(ctx, reservation); err != nil {
+				log.Println(err)
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+		panicked = false
+	})
+}
+
+// purgeIdempotencyKeys drops the expired keys of every caller periodically
+// until the server shuts down. A key is otherwise only dropped when its
+// caller uses it again.
+func (s *service) purgeIdempotencyKeys() {
+	ticker := time.NewTicker(idempotencyPurge)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		purged, err := s.repo.PurgeIdempotencyKeys(context.Background())
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if purged > 0 {
+			log.Printf("Purged %d expired idempotency keys", purged)
+		}
+	}
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKey {
+		return false
+	}
+	for _, c := range []byte(key) {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestHash identifies the request a key was first used with.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, stored model.IdempotencyKey) {
+	var header http.Header
+	if err := json.Unmarshal(stored.Header, &header); err != nil {
+		log.Printf("Failed to decode stored headers of idempotency key: %s. Error: %s", stored.Key, err.Error())
+	}
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+// responseRecorder keeps a copy of the response it writes.
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	header   http.Header
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 && status >= http.StatusOK {
+		rr.status = status
+		rr.header = rr.Header().Clone()
+		for _, name := range unreplayedHeaders {
+			rr.header.Del(name)
+		}
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if rr.status == 0 {
+		rr.WriteHeader(http.StatusOK)
+	}
+	if rr.body.Len()+len(p) > maxReplayedBody {
+		rr.overflow = true
+	} else if !rr.overflow {
+		rr.body.Write(p)
+	}
+
+	return rr.ResponseWriter.Write(p)
+}
+
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// replayable reports whether the response may be stored for retries.
+func (rr *responseRecorder) replayable() bool {
+	if rr.overflow || rr.status >= http.StatusInternalServerError {
+		return false
+	}
+
+	return !strings.Contains(rr.header.Get("Cache-Control"), "no-store")
+}
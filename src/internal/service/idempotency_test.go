@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"music/internal/base"
+	"music/internal/config"
+	"music/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+type testConfig struct {
+	config.Config
+	idempotencyTTL time.Duration
+}
+
+func (c testConfig) GetIdempotencyTTL() time.Duration {
+	return c.idempotencyTTL
+}
+
+// keys stores idempotency keys like the database does.
+type keys struct {
+	base.Repository
+	mu     sync.Mutex
+	stored map[[2]string]model.IdempotencyKey
+}
+
+func (k *keys) ReserveIdempotencyKey(ctx context.Context, key model.IdempotencyKey) (model.IdempotencyKey, bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	id := [2]string{key.Scope, key.Key}
+	if stored, ok := k.stored[id]; ok && stored.ExpiresAt.After(time.Now()) {
+		return stored, false, nil
+	}
+
+	k.stored[id] = key
+	return key, true, nil
+}
+
+func (k *keys) CompleteIdempotencyKey(ctx context.Context, key model.IdempotencyKey) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.stored[[2]string{key.Scope, key.Key}] = key
+	return nil
+}
+
+func (k *keys) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.stored, [2]string{scope, key})
+	return nil
+}
+
+func newIdempotent(ttl time.Duration, next http.Handler) (http.Handler, *keys) {
+	repo := &keys{stored: make(map[[2]string]model.IdempotencyKey)}
+	s := &service{repo: repo, cfg: testConfig{idempotencyTTL: ttl}}
+
+	return s.idempotent(next), repo
+}
+
+type call struct {
+	method string
+	target string
+	key    string
+	body   string
+	remote string
+}
+
+func (c call) do(h http.Handler) *httptest.ResponseRecorder {
+	method := c.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	target := c.target
+	if target == "" {
+		target = "/music"
+	}
+	r := httptest.NewRequest(method, target, strings.NewReader(c.body))
+	if c.key != "" {
+		r.Header.Set("Idempotency-Key", c.key)
+	}
+	if c.remote != "" {
+		r.RemoteAddr = c.remote
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+// created answers 201 with a body and counts the requests it runs.
+func created(runs *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*runs++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/music/1")
+		w.Header().Set("RateLimit-Remaining", "3")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	var runs int
+	h, _ := newIdempotent(time.Hour, created(&runs))
+	first := call{key: "key-1", body: `{"group":"Muse","song":"Hysteria"}`}
+
+	w := first.do(h)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first response = %d, replayed %q, want 201 run once", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+
+	replayed := first.do(h)
+	if runs != 1 {
+		t.Errorf("runs = %d, want the retry replayed", runs)
+	}
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != first.body {
+		t.Errorf("replay = %d %s, want 201 %s", replayed.Code, replayed.Body, first.body)
+	}
+	if got := replayed.Header().Get("Idempotent-Replayed"); got != "true" {
+		t.Errorf("Idempotent-Replayed = %q, want true", got)
+	}
+	if got := replayed.Header().Get("Location"); got != "/music/1" {
+		t.Errorf("Location = %q, want the stored header", got)
+	}
+	if got := replayed.Header().Get("RateLimit-Remaining"); got != "" {
+		t.Errorf("RateLimit-Remaining = %q, want the stored one dropped", got)
+	}
+}
+
+func TestIdempotentRejects(t *testing.T) {
+	first := call{key: "key-1", body: `{"group":"Muse","song":"Hysteria"}`}
+	tests := []struct {
+		name     string
+		retry    call
+		want     int
+		wantRuns int
+	}{
+		{name: "same request", retry: first, want: http.StatusCreated, wantRuns: 1},
+		{name: "other body", retry: call{key: "key-1", body: `{"group":"Muse","song":"Uprising"}`}, want: http.StatusUnprocessableEntity, wantRuns: 1},
+		{name: "other path", retry: call{key: "key-1", target: "/music/import", body: first.body}, want: http.StatusUnprocessableEntity, wantRuns: 1},
+		{name: "other method", retry: call{method: http.MethodPatch, key: "key-1", body: first.body}, want: http.StatusUnprocessableEntity, wantRuns: 1},
+		{name: "other key", retry: call{key: "key-2", body: first.body}, want: http.StatusCreated, wantRuns: 2},
+		{name: "other caller", retry: call{key: "key-1", body: first.body, remote: "192.0.2.7:1234"}, want: http.StatusCreated, wantRuns: 2},
+		{name: "no key", retry: call{body: first.body}, want: http.StatusCreated, wantRuns: 2},
+		{name: "invalid key", retry: call{key: "key 1", body: first.body}, want: http.StatusBadRequest, wantRuns: 1},
+		{name: "key too long", retry: call{key: strings.Repeat("k", maxIdempotencyKey+1), body: first.body}, want: http.StatusBadRequest, wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs int
+			h, _ := newIdempotent(time.Hour, created(&runs))
+			first.do(h)
+
+			w := tt.retry.do(h)
+			if w.Code != tt.want || runs != tt.wantRuns {
+				t.Errorf("retry = %d after %d runs, want %d after %d", w.Code, runs, tt.want, tt.wantRuns)
+			}
+			if tt.want >= http.StatusBadRequest && w.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want a problem", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestIdempotentInProgress(t *testing.T) {
+	request := call{key: "key-1", body: `{"group":"Muse","song":"Hysteria"}`}
+	var h http.Handler
+	var concurrent *httptest.ResponseRecorder
+	h, _ = newIdempotent(time.Hour, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if concurrent == nil {
+			// The retry arrives while the first request still runs.
+			concurrent = request.do(h)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	if w := request.do(h); w.Code != http.StatusCreated {
+		t.Fatalf("first response = %d, want 201", w.Code)
+	}
+	if concurrent.Code != http.StatusConflict || concurrent.Header().Get("Retry-After") != "1" {
+		t.Errorf("concurrent retry = %d, Retry-After %q, want 409 with Retry-After 1", concurrent.Code, concurrent.Header().Get("Retry-After"))
+	}
+}
+
+func TestIdempotentReleases(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(s *service) http.HandlerFunc
+	}{
+		{
+			name: "problem",
+			handler: func(s *service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					s.problem(w, r, base.NotFound("Song not found"))
+				}
+			},
+		},
+		{
+			name: "server error",
+			handler: func(s *service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			},
+		},
+		{
+			name: "no-store",
+			handler: func(s *service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Cache-Control", "no-store")
+					w.WriteHeader(http.StatusCreated)
+				}
+			},
+		},
+		{
+			name: "too large",
+			handler: func(s *service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Write(make([]byte, maxReplayedBody+1))
+				}
+			},
+		},
+		{
+			name: "panic",
+			handler: func(s *service) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					panic(http.ErrAbortHandler)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &keys{stored: make(map[[2]string]model.IdempotencyKey)}
+			s := &service{repo: repo, cfg: testConfig{idempotencyTTL: time.Hour}}
+			h := s.idempotent(tt.handler(s))
+
+			func() {
+				defer func() { recover() }()
+				call{key: "key-1", body: "{}"}.do(h)
+			}()
+			if len(repo.stored) != 0 {
+				t.Errorf("stored keys = %v, want the key released", repo.stored)
+			}
+		})
+	}
+}
+
+func TestIdempotentDisabled(t *testing.T) {
+	var runs int
+	h, repo := newIdempotent(0, created(&runs))
+
+	for range 2 {
+		call{key: "key-1", body: "{}"}.do(h)
+	}
+	if runs != 2 || len(repo.stored) != 0 {
+		t.Errorf("runs = %d, stored keys = %d, want every request run without keys", runs, len(repo.stored))
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	tests := []struct {
+		name           string
+		write          func(w http.ResponseWriter)
+		wantStatus     int
+		wantBody       string
+		wantReplayable bool
+	}{
+		{
+			name:           "implicit ok",
+			write:          func(w http.ResponseWriter) { io.WriteString(w, "done") },
+			wantStatus:     http.StatusOK,
+			wantBody:       "done",
+			wantReplayable: true,
+		},
+		{
+			name: "informational first",
+			write: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, "created")
+			},
+			wantStatus:     http.StatusCreated,
+			wantBody:       "created",
+			wantReplayable: true,
+		},
+		{
+			name: "second status ignored",
+			write: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusAccepted)
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantStatus:     http.StatusAccepted,
+			wantReplayable: true,
+		},
+		{
+			name: "client error",
+			write: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusConflict)
+			},
+			wantStatus:     http.StatusConflict,
+			wantReplayable: true,
+		},
+		{
+			name: "server error",
+			write: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "no-store",
+			write: func(w http.ResponseWriter) {
+				w.Header().Set("Cache-Control", "private, no-store")
+				io.WriteString(w, "token")
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "token",
+		},
+		{
+			name: "overflow",
+			write: func(w http.ResponseWriter) {
+				w.Write(make([]byte, maxReplayedBody))
+				io.WriteString(w, "more")
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   string(make([]byte, maxReplayedBody)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.Header().Set("Retry-After", "5")
+			rr := &responseRecorder{ResponseWriter: w}
+			tt.write(rr)
+
+			if rr.status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.status, tt.wantStatus)
+			}
+			if rr.body.String() != tt.wantBody {
+				t.Errorf("recorded body = %.20q, want %.20q", rr.body.String(), tt.wantBody)
+			}
+			if got := rr.replayable(); got != tt.wantReplayable {
+				t.Errorf("replayable() = %v, want %v", got, tt.wantReplayable)
+			}
+			if rr.header.Get("Retry-After") != "" {
+				t.Error("Retry-After is recorded, it describes the current request")
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/lyrics"
+	"music/internal/model"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *service) setupLyricsRoutes() {
+	s.router.Handle("/songs/{id}/lyrics", s.require(s.readRole(), s.SyncedLyrics)).Methods("GET")
+	s.router.Handle("/songs/{id}/lyrics", s.require(auth.Editor, s.UploadLyrics)).Methods("PUT")
+	s.router.Handle("/songs/{id}/lyrics", s.require(auth.Editor, s.DeleteLyrics)).Methods("DELETE")
+}
+
+// SyncedLyricsResponse is the JSON form of synced lyrics.
+type SyncedLyricsResponse struct {
+	SongID uint              `json:"song_id"`
+	Lines  []model.LyricLine `json:"lines"`
+}
+
+func toModelLines(lines []lyrics.Line) []model.LyricLine {
+	result := make([]model.LyricLine, 0, len(lines))
+	for i, line := range lines {
+		var words model.LyricWords
+		for _, word := range line.Words {
+			words = append(words, model.LyricWord{TimeMs: word.Time.Milliseconds(), Text: word.Text})
+		}
+		result = append(result, model.LyricLine{
+			Position: i,
+			TimeMs:   line.Time.Milliseconds(),
+			Text:     line.Text,
+			Words:    words,
+		})
+	}
+
+	return result
+}
+
+func fromModelLines(lines []model.LyricLine) []lyrics.Line {
+	result := make([]lyrics.Line, 0, len(lines))
+	for _, line := range lines {
+		var words []lyrics.Word
+		for _, word := range line.Words {
+			words = append(words, lyrics.Word{Time: time.Duration(word.TimeMs) * time.Millisecond, Text: word.Text})
+		}
+		result = append(result, lyrics.Line{
+			Time:  time.Duration(line.TimeMs) * time.Millisecond,
+			Text:  line.Text,
+			Words: words,
+		})
+	}
+
+	return result
+}
+
+// parseLRC reads an uploaded LRC file, reporting every malformed line as a
+// violation.
+func parseLRC(w http.ResponseWriter, r *http.Request) (lyrics.Lyrics, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	parsed, err := lyrics.Parse(r.Body)
+	if err == nil {
+		return parsed, nil
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return lyrics.Lyrics{}, err
+	}
+	var syntax lyrics.Errors
+	if !errors.As(err, &syntax) {
+		return lyrics.Lyrics{}, base.Validation("Invalid LRC file: %s", err.Error())
+	}
+
+	violations := make([]base.Violation, 0, len(syntax))
+	for _, e := range syntax {
+		field := "file"
+		if e.Line > 0 {
+			field = fmt.Sprintf("line %d", e.Line)
+		}
+		violations = append(violations, base.Violation{Field: field, Message: e.Message})
+	}
+
+	return lyrics.Lyrics{}, base.Invalid(violations)
+}
+
+// UploadLyrics загружает синхронизированный текст песни
+// @Summary Загрузить синхронизированный текст
+// @Description Принимает файл LRC или расширенный LRC с метками слов и заменяет синхронизированный текст песни. Если у песни нет обычного текста, он заполняется строками файла
+// @Tags lyrics
+// @Accept plain
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param lyrics body string true "Файл LRC"
+// @Success 200 {object} SyncedLyricsResponse "Разобранные строки"
+// @Failure 400 {object} Problem "Malformed LRC file"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 413 {object} Problem "File too large"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics [put]
+func (s *service) UploadLyrics(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	parsed, err := parseLRC(w, r)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	lines := toModelLines(parsed.Lines)
+	if err := s.repo.SetSyncedLyrics(r.Context(), songID, lines, lyrics.Text(parsed.Lines)); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SyncedLyricsResponse{SongID: songID, Lines: lines})
+}
+
+// SyncedLyrics возвращает синхронизированный текст песни
+// @Summary Получить синхронизированный текст
+// @Description Возвращает текст песни в формате LRC, JSON или обычным текстом. С параметром at возвращает строку, активную в указанный момент, в том же формате
+// @Tags lyrics
+// @Produce json,plain
+// @Param id path int true "ID песни"
+// @Param format query string false "Формат: json (по умолчанию), lrc или text. С at: строка JSON, строка LRC или текст строки"
+// @Param at query string false "Момент времени в формате mm:ss.xx"
+// @Success 200 {object} SyncedLyricsResponse "Синхронизированный текст"
+// @Failure 400 {object} Problem "Unsupported format or invalid time"
+// @Failure 404 {object} Problem "Song not found, song has no synced lyrics or no line is active yet"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics [get]
+func (s *service) SyncedLyrics(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "lrc" && format != "text" {
+		s.problem(w, r, base.Validation("Unsupported format: %s", format))
+		return
+	}
+	var at time.Duration
+	if value := query.Get("at"); value != "" {
+		if at, err = lyrics.ParseTimestamp(value); err != nil {
+			s.problem(w, r, base.Validation("Invalid at: %s", err.Error()))
+			return
+		}
+	}
+
+	song, lines, err := s.repo.GetSyncedLyrics(r.Context(), songID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	if format == "text" && query.Get("at") == "" {
+		text := song.Lyrics
+		if text == "" {
+			text = lyrics.Text(fromModelLines(lines))
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, text)
+		return
+	}
+	if len(lines) == 0 {
+		s.problem(w, r, base.NotFound("Song %d has no synced lyrics", songID))
+		return
+	}
+
+	if query.Get("at") != "" {
+		parsed := fromModelLines(lines)
+		i := lyrics.Active(parsed, at)
+		if i < 0 {
+			s.problem(w, r, base.NotFound("No line is active at %s", lyrics.FormatTimestamp(at)))
+			return
+		}
+		switch format {
+		case "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			io.WriteString(w, lines[i].Text)
+		case "lrc":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			if err := lyrics.Write(w, lyrics.Lyrics{Lines: parsed[i : i+1]}); err != nil {
+				log.Printf("Failed to write lyrics of song: %d. Error: %s", songID, err.Error())
+			}
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(lines[i])
+		}
+		return
+	}
+
+	if format == "lrc" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="song-%d.lrc"`, songID))
+		tags := map[string]string{"ar": song.Group_name, "ti": song.Song}
+		if err := lyrics.Write(w, lyrics.Lyrics{Tags: tags, Lines: fromModelLines(lines)}); err != nil {
+			log.Printf("Failed to write lyrics of song: %d. Error: %s", songID, err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SyncedLyricsResponse{SongID: songID, Lines: lines})
+}
+
+// DeleteLyrics удаляет синхронизированный текст песни
+// @Summary Удалить синхронизированный текст
+// @Description Удаляет строки с метками времени, обычный текст песни сохраняется
+// @Tags lyrics
+// @Param id path int true "ID песни"
+// @Success 204 "Синхронизированный текст удален"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Song has no synced lyrics"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics [delete]
+func (s *service) DeleteLyrics(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.DeleteSyncedLyrics(r.Context(), songID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
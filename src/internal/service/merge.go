@@ -0,0 +1,87 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/dedup"
+	"music/internal/dto"
+	"net/http"
+	"strconv"
+)
+
+const defaultSimilarity = 0.8
+
+func (s *service) setupMergeRoutes() {
+	s.router.Handle("/songs/duplicates", s.expensive(s.require(auth.Editor, s.Duplicates))).Methods("GET")
+	s.router.Handle("/songs/merge", s.require(auth.Editor, s.Merge)).Methods("POST")
+}
+
+// Duplicates находит вероятные дубликаты песен
+// @Summary Дубликаты песен
+// @Description Группирует песни с одинаковыми названиями после нормализации (регистр, диакритика, пробелы, пометки feat.) и песни одной группы с похожими текстами
+// @Tags music
+// @Produce json
+// @Param threshold query number false "Минимальное сходство текстов от 0 до 1, по умолчанию 0.8"
+// @Success 200 {array} dedup.Cluster "Группы вероятных дубликатов"
+// @Failure 400 {object} Problem "Invalid threshold"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/duplicates [get]
+func (s *service) Duplicates(w http.ResponseWriter, r *http.Request) {
+	threshold := defaultSimilarity
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			s.problem(w, r, base.Validation("Invalid threshold, expected a number above 0 and up to 1"))
+			return
+		}
+	}
+
+	songs, err := s.repo.GetLibrary(r.Context())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dedup.Find(songs, threshold))
+}
+
+// Merge объединяет дубликаты песен
+// @Summary Объединить песни
+// @Description Переносит в песню-победителя выбранные поля, записи плейлистов, избранное, списки, теги и тексты остальных песен, затем удаляет их
+// @Tags music
+// @Accept json
+// @Produce json
+// @Param merge body dto.MergeRequest true "Победитель, объединяемые песни и выбор полей"
+// @Success 200 {object} model.Song "Объединенная песня"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Song not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/merge [post]
+func (s *service) Merge(w http.ResponseWriter, r *http.Request) {
+	var request dto.MergeRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	song, err := s.repo.MergeSongs(r.Context(), request.Winner, request.Losers, request.Fields)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(song)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"music/internal/base"
+	"music/internal/model"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	mimeJSON    = "application/json"
+	mimeNDJSON  = "application/x-ndjson"
+	mimeCSV     = "text/csv"
+	mimeMsgPack = "application/msgpack"
+	mimeText    = "text/plain"
+)
+
+var (
+	// songFormats are the representations of songs and lists of songs, the
+	// first one is the default.
+	songFormats = []string{mimeJSON, mimeNDJSON, mimeCSV, mimeMsgPack}
+	// lyricsFormats are the representations of lyrics.
+	lyricsFormats = []string{mimeJSON, mimeText}
+)
+
+// mediaAliases maps the other names clients use for a media type to the
+// name it is served as.
+var mediaAliases = map[string]string{
+	"application/ndjson":      mimeNDJSON,
+	"application/jsonl":       mimeNDJSON,
+	"application/x-msgpack":   mimeMsgPack,
+	"application/vnd.msgpack": mimeMsgPack,
+}
+
+// weighted is an element of an Accept or Accept-Encoding header.
+type weighted struct {
+	value   string
+	quality float64
+}
+
+// parseWeighted parses a header of comma separated values with optional
+// quality parameters. Other parameters are dropped, malformed qualities count
+// as 1.
+func parseWeighted(header string) []weighted {
+	var result []weighted
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			name, raw, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(raw), 64); err == nil && q >= 0 && q <= 1 {
+				quality = q
+			}
+		}
+		result = append(result, weighted{value: value, quality: quality})
+	}
+
+	return result
+}
+
+// negotiate picks the offer the Accept header prefers. The most specific
+// range matching an offer sets its quality, offers of the same quality are
+// picked in the order given. A missing header accepts the first offer.
+func negotiate(w http.ResponseWriter, r *http.Request, offers ...string) (string, error) {
+	w.Header().Add("Vary", "Accept")
+	header := r.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		return offers[0], nil
+	}
+
+	ranges := parseWeighted(header)
+	for i := range ranges {
+		if alias, ok := mediaAliases[ranges[i].value]; ok {
+			ranges[i].value = alias
+		}
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		quality, specificity := 0.0, -1
+		for _, accepted := range ranges {
+			s := matchMedia(accepted.value, offer)
+			if s > specificity {
+				quality, specificity = accepted.quality, s
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	if best == "" {
+		return "", base.NotAcceptable("Unsupported Accept: %s, expected one of %s", header, strings.Join(offers, ", "))
+	}
+
+	return best, nil
+}
+
+// matchMedia returns how specific the media range is when it matches the
+// media type: 2 for the type itself, 1 for type/*, 0 for */*, -1 otherwise.
+func matchMedia(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+
+	return -1
+}
+
+// writeSongs writes a song or a list of songs in the negotiated format. JSON
+// and MessagePack encode value as is, NDJSON and CSV write a record per song.
+func writeSongs(w http.ResponseWriter, format string, value any, songs []model.Song) error {
+	switch format {
+	case mimeNDJSON:
+		w.Header().Set("Content-Type", mimeNDJSON)
+		encoder := json.NewEncoder(w)
+		for _, song := range songs {
+			if err := encoder.Encode(song); err != nil {
+				return err
+			}
+		}
+		return nil
+	case mimeCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		return writeSongsCSV(w, songs)
+	case mimeMsgPack:
+		w.Header().Set("Content-Type", mimeMsgPack)
+		encoder := msgpack.NewEncoder(w)
+		// The JSON field names keep both formats interchangeable.
+		encoder.SetCustomStructTag("json")
+		return encoder.Encode(value)
+	}
+
+	w.Header().Set("Content-Type", mimeJSON)
+	return json.NewEncoder(w).Encode(value)
+}
+
+func writeSongsCSV(w io.Writer, songs []model.Song) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "group", "song", "album", "release_date", "text"})
+	for _, song := range songs {
+		writer.Write([]string{
+			strconv.FormatUint(uint64(song.ID), 10),
+			song.Group_name,
+			song.Song,
+			song.Album,
+			song.ReleaseDate,
+			song.Lyrics,
+		})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// writeLyrics writes lyrics in the negotiated format. Plain text separates
+// the lyrics of several songs by an empty line.
+func writeLyrics(w http.ResponseWriter, format string, value any, texts []string) error {
+	if format == mimeText {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, err := io.WriteString(w, strings.Join(texts, "\n\n"))
+		return err
+	}
+
+	w.Header().Set("Content-Type", mimeJSON)
+	return json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"music/internal/base"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseWeighted(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []weighted
+	}{
+		{header: "", want: nil},
+		{header: "gzip", want: []weighted{{"gzip", 1}}},
+		{header: "gzip;q=0.5, br", want: []weighted{{"gzip", 0.5}, {"br", 1}}},
+		{header: "Text/HTML; level=1; Q=0.2", want: []weighted{{"text/html", 0.2}}},
+		{header: "gzip;q=2, br;q=abc, zstd;q=-1", want: []weighted{{"gzip", 1}, {"br", 1}, {"zstd", 1}}},
+		{header: "identity;q=0", want: []weighted{{"identity", 0}}},
+		{header: " , ,gzip,", want: []weighted{{"gzip", 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := parseWeighted(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseWeighted(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchMedia(t *testing.T) {
+	tests := []struct {
+		mediaRange string
+		mediaType  string
+		want       int
+	}{
+		{mediaRange: "application/json", mediaType: "application/json", want: 2},
+		{mediaRange: "application/*", mediaType: "application/json", want: 1},
+		{mediaRange: "*/*", mediaType: "application/json", want: 0},
+		{mediaRange: "text/*", mediaType: "application/json", want: -1},
+		{mediaRange: "application/xml", mediaType: "application/json", want: -1},
+		{mediaRange: "app/*", mediaType: "application/json", want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mediaRange, func(t *testing.T) {
+			if got := matchMedia(tt.mediaRange, tt.mediaType); got != tt.want {
+				t.Errorf("matchMedia(%s, %s) = %d, want %d", tt.mediaRange, tt.mediaType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  string
+		offers  []string
+		want    string
+		wantErr bool
+	}{
+		{name: "no header", accept: "", offers: songFormats, want: mimeJSON},
+		{name: "exact", accept: "text/csv", offers: songFormats, want: mimeCSV},
+		{name: "alias", accept: "application/jsonl", offers: songFormats, want: mimeNDJSON},
+		{name: "msgpack alias", accept: "application/vnd.msgpack", offers: songFormats, want: mimeMsgPack},
+		{name: "quality", accept: "application/json;q=0.5, text/csv", offers: songFormats, want: mimeCSV},
+		{name: "wildcard keeps the order", accept: "*/*", offers: songFormats, want: mimeJSON},
+		{name: "type wildcard", accept: "text/*", offers: lyricsFormats, want: mimeText},
+		{name: "specific range wins", accept: "application/*;q=0.9, application/json;q=0.1", offers: songFormats, want: mimeNDJSON},
+		{name: "excluded", accept: "*/*, application/json;q=0", offers: songFormats, want: mimeNDJSON},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", offers: lyricsFormats, want: mimeJSON},
+		{name: "unsupported", accept: "application/xml", offers: songFormats, wantErr: true},
+		{name: "all refused", accept: "*/*;q=0", offers: songFormats, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/music", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			w.Header().Set("Vary", "Accept-Encoding")
+
+			got, err := negotiate(w, r, tt.offers...)
+			if tt.wantErr {
+				var e *base.Error
+				if !errors.As(err, &e) || e.Kind != base.KindNotAcceptable {
+					t.Errorf("negotiate() error = %v, want not acceptable", err)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("negotiate() = %s, %v, want %s", got, err, tt.want)
+			}
+			if vary := w.Header().Values("Vary"); !reflect.DeepEqual(vary, []string{"Accept-Encoding", "Accept"}) {
+				t.Errorf("Vary = %v, want Accept added to Accept-Encoding", vary)
+			}
+		})
+	}
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/dto"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *service) setupPlaylistRoutes() {
+	read := s.readRole()
+	s.router.Handle("/playlists", s.require(read, s.Playlists)).Methods("GET")
+	s.router.Handle("/playlists", s.user(s.CreatePlaylist)).Methods("POST")
+	s.router.Handle("/playlists/{playlist}", s.require(read, s.Playlist)).Methods("GET")
+	s.router.Handle("/playlists/{playlist}", s.user(s.UpdatePlaylist)).Methods("PATCH")
+	s.router.Handle("/playlists/{playlist}", s.user(s.DeletePlaylist)).Methods("DELETE")
+	s.router.Handle("/playlists/{playlist}/export", s.expensive(s.require(read, s.ExportPlaylist))).Methods("GET")
+
+	s.router.Handle("/playlists/{playlist}/entries", s.require(read, s.PlaylistEntries)).Methods("GET")
+	s.router.Handle("/playlists/{playlist}/entries", s.user(s.InsertPlaylistEntry)).Methods("POST")
+	s.router.Handle("/playlists/{playlist}/entries/{entry}", s.user(s.MovePlaylistEntry)).Methods("PATCH")
+	s.router.Handle("/playlists/{playlist}/entries/{entry}", s.user(s.RemovePlaylistEntry)).Methods("DELETE")
+
+	s.router.Handle("/playlists/{playlist}/collaborators/{user}", s.user(s.AddCollaborator)).Methods("PUT")
+	s.router.Handle("/playlists/{playlist}/collaborators/{user}", s.user(s.RemoveCollaborator)).Methods("DELETE")
+}
+
+// Playlists возвращает доступные плейлисты
+// @Summary Список плейлистов
+// @Description Возвращает публичные плейлисты, а также плейлисты, которыми пользователь владеет или которые редактирует
+// @Tags playlists
+// @Produce json
+// @Success 200 {array} model.Playlist "Плейлисты"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security BearerAuth
+// @Router /playlists [get]
+func (s *service) Playlists(w http.ResponseWriter, r *http.Request) {
+	playlists, err := s.repo.GetPlaylists(r.Context(), auth.FromContext(r.Context()).UserID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(playlists)
+}
+
+// CreatePlaylist создает плейлист
+// @Summary Создать плейлист
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist body dto.PlaylistRequest true "Плейлист"
+// @Success 201 {object} model.Playlist "Созданный плейлист"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security BearerAuth
+// @Router /playlists [post]
+func (s *service) CreatePlaylist(w http.ResponseWriter, r *http.Request) {
+	var request dto.PlaylistRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	playlist, err := s.repo.CreatePlaylist(r.Context(), request.Model(auth.FromContext(r.Context()).UserID))
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(playlist)
+}
+
+// Playlist возвращает плейлист
+// @Summary Получить плейлист
+// @Tags playlists
+// @Produce json
+// @Param playlist path int true "ID плейлиста"
+// @Success 200 {object} model.Playlist "Плейлист"
+// @Failure 400 {object} Problem "Invalid playlist"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist} [get]
+func (s *service) Playlist(w http.ResponseWriter, r *http.Request) {
+	playlistID, err := pathID(mux.Vars(r), "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	playlist, err := s.repo.GetPlaylist(r.Context(), auth.FromContext(r.Context()).UserID, playlistID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(playlist)
+}
+
+// UpdatePlaylist изменяет плейлист
+// @Summary Изменить плейлист
+// @Description Изменяет название, описание и видимость плейлиста. Доступно только владельцу
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist path int true "ID плейлиста"
+// @Param fields body dto.PlaylistUpdateRequest true "Изменяемые поля"
+// @Success 200 {object} model.Playlist "Измененный плейлист"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 403 {object} Problem "Only the owner may change the playlist"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist} [patch]
+func (s *service) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
+	playlistID, err := pathID(mux.Vars(r), "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	var request dto.PlaylistUpdateRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	playlist, err := s.repo.UpdatePlaylist(r.Context(), auth.FromContext(r.Context()).UserID, playlistID, request.Fields())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(playlist)
+}
+
+// DeletePlaylist удаляет плейлист
+// @Summary Удалить плейлист
+// @Tags playlists
+// @Param playlist path int true "ID плейлиста"
+// @Success 204 "Плейлист удален"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 403 {object} Problem "Only the owner may delete the playlist"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist} [delete]
+func (s *service) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
+	playlistID, err := pathID(mux.Vars(r), "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.DeletePlaylist(r.Context(), auth.FromContext(r.Context()).UserID, playlistID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PlaylistEntries возвращает песни плейлиста
+// @Summary Песни плейлиста
+// @Description Возвращает записи плейлиста в порядке позиций
+// @Tags playlists
+// @Produce json
+// @Param playlist path int true "ID плейлиста"
+// @Success 200 {array} model.PlaylistEntry "Записи плейлиста"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist}/entries [get]
+func (s *service) PlaylistEntries(w http.ResponseWriter, r *http.Request) {
+	playlistID, err := pathID(mux.Vars(r), "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	entries, err := s.repo.GetPlaylistEntries(r.Context(), auth.FromContext(r.Context()).UserID, playlistID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// InsertPlaylistEntry добавляет песню в плейлист
+// @Summary Добавить песню в плейлист
+// @Description Вставляет песню на указанную позицию, сдвигая следующие записи. Без позиции песня добавляется в конец
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist path int true "ID плейлиста"
+// @Param entry body dto.EntryRequest true "Песня и позиция"
+// @Success 201 {object} model.PlaylistEntry "Добавленная запись"
+// @Failure 400 {object} Problem "Invalid request payload or position"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 403 {object} Problem "Only the owner and collaborators may edit the playlist"
+// @Failure 404 {object} Problem "Playlist or song not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist}/entries [post]
+func (s *service) InsertPlaylistEntry(w http.ResponseWriter, r *http.Request) {
+	playlistID, err := pathID(mux.Vars(r), "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	var request dto.EntryRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	userID := auth.FromContext(r.Context()).UserID
+	entry, err := s.repo.InsertPlaylistEntry(r.Context(), userID, playlistID, request.SongID, request.Position)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// MovePlaylistEntry перемещает запись плейлиста
+// @Summary Переместить песню в плейлисте
+// @Tags playlists
+// @Accept json
+// @Param playlist path int true "ID плейлиста"
+// @Param entry path int true "ID записи"
+// @Param position body dto.MoveRequest true "Новая позиция"
+// @Success 204 "Запись перемещена"
+// @Failure 400 {object} Problem "Invalid request payload or position"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 403 {object} Problem "Only the owner and collaborators may edit the playlist"
+// @Failure 404 {object} Problem "Playlist or entry not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist}/entries/{entry} [patch]
+func (s *service) MovePlaylistEntry(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	playlistID, err := pathID(params, "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	entryID, err := pathID(params, "entry")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	var request dto.MoveRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	userID := auth.FromContext(r.Context()).UserID
+	if err := s.repo.MovePlaylistEntry(r.Context(), userID, playlistID, entryID, *request.Position); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemovePlaylistEntry удаляет запись плейлиста
+// @Summary Удалить песню из плейлиста
+// @Tags playlists
+// @Param playlist path int true "ID плейлиста"
+// @Param entry path int true "ID записи"
+// @Success 204 "Запись удалена"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 403 {object} Problem "Only the owner and collaborators may edit the playlist"
+// @Failure 404 {object} Problem "Playlist or entry not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist}/entries/{entry} [delete]
+func (s *service) RemovePlaylistEntry(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	playlistID, err := pathID(params, "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	entryID, err := pathID(params, "entry")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.RemovePlaylistEntry(r.Context(), auth.FromContext(r.Context()).UserID, playlistID, entryID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddCollaborator разрешает пользователю редактировать плейлист
+// @Summary Добавить соавтора
+// @Tags playlists
+// @Param playlist path int true "ID плейлиста"
+// @Param user path int true "ID пользователя"
+// @Success 204 "Соавтор добавлен"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 403 {object} Problem "Only the owner may change the playlist"
+// @Failure 404 {object} Problem "Playlist or user not found"
+// @Security BearerAuth
+// @Router /playlists/{playlist}/collaborators/{user} [put]
+func (s *service) AddCollaborator(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	playlistID, err := pathID(params, "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	userID, err := pathID(params, "user")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.AddCollaborator(r.Context(), auth.FromContext(r.Context()).UserID, playlistID, userID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveCollaborator запрещает пользователю редактировать плейлист
+// @Summary Удалить соавтора
+// @Tags playlists
+// @Param playlist path int true "ID плейлиста"
+// @Param user path int true "ID пользователя"
+// @Success 204 "Соавтор удален"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 403 {object} Problem "Only the owner may change the playlist"
+// @Failure 404 {object} Problem "Playlist not found or user is not a collaborator"
+// @Security BearerAuth
+// @Router /playlists/{playlist}/collaborators/{user} [delete]
+func (s *service) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	playlistID, err := pathID(params, "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	userID, err := pathID(params, "user")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.RemoveCollaborator(r.Context(), auth.FromContext(r.Context()).UserID, playlistID, userID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportPlaylist выгружает плейлист
+// @Summary Экспорт плейлиста
+// @Description Выгружает плейлист в формате M3U или XSPF. Песни указываются как urn:music:song:{id}
+// @Tags playlists
+// @Produce audio/x-mpegurl,application/xspf+xml
+// @Param playlist path int true "ID плейлиста"
+// @Param format query string false "Формат: m3u (по умолчанию) или xspf"
+// @Success 200 {string} string "Плейлист"
+// @Failure 400 {object} Problem "Unsupported format"
+// @Failure 404 {object} Problem "Playlist not found"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security BearerAuth
+// @Router /playlists/{playlist}/export [get]
+func (s *service) ExportPlaylist(w http.ResponseWriter, r *http.Request) {
+	playlistID, err := pathID(mux.Vars(r), "playlist")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "m3u"
+	}
+	if format != "m3u" && format != "xspf" {
+		s.problem(w, r, base.Validation("Unsupported format: %s", format))
+		return
+	}
+
+	userID := auth.FromContext(r.Context()).UserID
+	playlist, err := s.repo.GetPlaylist(r.Context(), userID, playlistID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+	entries, err := s.repo.GetPlaylistEntries(r.Context(), userID, playlistID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="playlist-%d.%s"`, playlistID, format))
+	if format == "xspf" {
+		w.Header().Set("Content-Type", "application/xspf+xml")
+		err = writeXSPF(w, playlist, entries)
+	} else {
+		w.Header().Set("Content-Type", "audio/x-mpegurl; charset=utf-8")
+		err = writeM3U(w, playlist, entries)
+	}
+	if err != nil {
+		log.Printf("Failed to export playlist: %d. Error: %s", playlistID, err.Error())
+	}
+}
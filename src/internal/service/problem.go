@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"music/internal/base"
+	"music/internal/model"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details response body.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Errors []base.Violation `json:"errors,omitempty"`
+	// Suggestions lists similar songs when a song was not found.
+	Suggestions []model.Suggestion `json:"suggestions,omitempty"`
+}
+
+var statusByKind = map[base.Kind]int{
+	base.KindNotFound:         http.StatusNotFound,
+	base.KindConflict:         http.StatusConflict,
+	base.KindValidation:       http.StatusBadRequest,
+	base.KindUnavailable:      http.StatusServiceUnavailable,
+	base.KindTimeout:          http.StatusGatewayTimeout,
+	base.KindFailedDependency: http.StatusFailedDependency,
+	base.KindUnprocessable:    http.StatusUnprocessableEntity,
+
+	base.KindUnauthenticated: http.StatusUnauthorized,
+	base.KindForbidden:       http.StatusForbidden,
+	base.KindRateLimited:     http.StatusTooManyRequests,
+	base.KindNotAcceptable:   http.StatusNotAcceptable,
+}
+
+// problem writes err as application/problem+json.
+func (s *service) problem(w http.ResponseWriter, r *http.Request, err error) {
+	p := newProblem(r, err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// newProblem describes err. Only domain errors expose their message, anything
+// else is reported as an internal error without details.
+func newProblem(r *http.Request, err error) Problem {
+	p := Problem{
+		Type:     "about:blank",
+		Status:   http.StatusInternalServerError,
+		Instance: r.URL.Path,
+	}
+
+	var domain *base.Error
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &domain):
+		if status, ok := statusByKind[domain.Kind]; ok {
+			p.Status = status
+			p.Detail = domain.Message
+			p.Errors = domain.Violations
+			p.Suggestions = domain.Suggestions
+		}
+	case errors.As(err, &tooLarge):
+		p.Status = http.StatusRequestEntityTooLarge
+		p.Detail = fmt.Sprintf("Request body must not exceed %d bytes", tooLarge.Limit)
+	}
+	p.Title = http.StatusText(p.Status)
+
+	return p
+}
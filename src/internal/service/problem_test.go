@@ -0,0 +1,109 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"music/internal/base"
+	"music/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	violations := []base.Violation{{Field: "song", Message: "is required"}}
+	suggestions := []model.Suggestion{{Group: "Muse", Song: "Hysteria"}}
+	tests := []struct {
+		name string
+		err  error
+		want Problem
+	}{
+		{
+			name: "not found",
+			err:  &base.Error{Kind: base.KindNotFound, Message: "Song not found", Suggestions: suggestions},
+			want: Problem{Status: http.StatusNotFound, Detail: "Song not found", Suggestions: suggestions},
+		},
+		{
+			name: "wrapped validation",
+			err:  fmt.Errorf("Failed to add song. Error: %w", base.Invalid(violations)),
+			want: Problem{Status: http.StatusBadRequest, Detail: "Request validation failed", Errors: violations},
+		},
+		{
+			name: "conflict",
+			err:  base.Conflict("Song already exists"),
+			want: Problem{Status: http.StatusConflict, Detail: "Song already exists"},
+		},
+		{
+			name: "unavailable hides the cause",
+			err:  base.Unavailable(errors.New("dial tcp: connection refused")),
+			want: Problem{Status: http.StatusServiceUnavailable, Detail: "database is unavailable"},
+		},
+		{
+			name: "rate limited",
+			err:  base.RateLimited("Too many requests"),
+			want: Problem{Status: http.StatusTooManyRequests, Detail: "Too many requests"},
+		},
+		{
+			name: "body too large",
+			err:  &http.MaxBytesError{Limit: 1024},
+			want: Problem{Status: http.StatusRequestEntityTooLarge, Detail: "Request body must not exceed 1024 bytes"},
+		},
+		{
+			name: "internal domain error",
+			err:  &base.Error{Kind: base.KindInternal, Message: "secret"},
+			want: Problem{Status: http.StatusInternalServerError},
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("pq: relation does not exist"),
+			want: Problem{Status: http.StatusInternalServerError},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/music/songs/1?x=y", nil)
+			want := tt.want
+			want.Type = "about:blank"
+			want.Title = http.StatusText(want.Status)
+			want.Instance = "/music/songs/1"
+
+			if got := newProblem(r, tt.err); !reflect.DeepEqual(got, want) {
+				t.Errorf("newProblem() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/music/songs/1", nil)
+	(&service{}).problem(w, r, base.NotFound("Song not found"))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	want := map[string]any{
+		"type":     "about:blank",
+		"title":    "Not Found",
+		"status":   float64(http.StatusNotFound),
+		"detail":   "Song not found",
+		"instance": "/music/songs/1",
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("body = %v, want %v", body, want)
+	}
+}
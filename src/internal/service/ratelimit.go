@@ -0,0 +1,70 @@
+package service
+
+import (
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/ratelimit"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// rateLimit applies the default limit to every request.
+func (s *service) rateLimit(next http.Handler) http.Handler {
+	return s.limited("default", s.limits.normal, next)
+}
+
+// expensive additionally applies the stricter limit of routes that scan
+// the whole table.
+func (s *service) expensive(next http.Handler) http.Handler {
+	return s.limited("expensive", s.limits.expensive, next)
+}
+
+func (s *service) limited(class string, limit ratelimit.Limit, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := s.limiter.Take(r.Context(), class+":"+client(r), limit)
+		if err != nil {
+			log.Printf("Rate limiter is unavailable, request allowed. Error: %s", err.Error())
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !s.admit(w, r, result) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// admit sets the RateLimit-* headers of result and answers 429 when it is
+// not allowed.
+func (s *service) admit(w http.ResponseWriter, r *http.Request, result ratelimit.Result) bool {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(int(result.Reset.Seconds())))
+
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(result.RetryAfter.Seconds())))
+		s.problem(w, r, base.RateLimited("Too many requests, retry in %d seconds", int(result.RetryAfter.Seconds())))
+		return false
+	}
+
+	return true
+}
+
+// client identifies the caller for rate limiting: authenticated callers by
+// their subject, anonymous ones by IP address.
+func client(r *http.Request) string {
+	if subject := auth.FromContext(r.Context()).Subject; subject != "" {
+		return subject
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"music/internal/base"
+	"net/http"
+)
+
+const (
+	maxBodySize   = 1 << 20
+	maxImportSize = 32 << 20
+	maxBatchSize  = 8 << 20
+)
+
+type payload interface {
+	Normalize()
+	Validate() error
+}
+
+// decode reads a single JSON object of limited size into dst, rejecting
+// unknown fields, then normalizes and validates it.
+func decode(w http.ResponseWriter, r *http.Request, dst payload, limit int64) error {
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+
+		return base.Validation("Invalid JSON body: %s", err.Error())
+	}
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return base.Validation("Request body must contain a single JSON object")
+	}
+
+	dst.Normalize()
+	return dst.Validate()
+}
@@ -1,250 +1,459 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"music/internal/auth"
 	"music/internal/base"
 	"music/internal/config"
+	"music/internal/dto"
+	"music/internal/feed"
+	"music/internal/lyrics"
 	"music/internal/model"
+	"music/internal/ratelimit"
+	"music/internal/webhook"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 type Service interface {
 	Run() error
+	Shutdown(ctx context.Context) error
 	Router() *mux.Router
 	Close() error
 }
 
 type service struct {
 	router *mux.Router
+	server *http.Server
+	done   chan struct{}
 	repo   base.Repository
 	cfg    config.Config
+	auth   *auth.Authenticator
+
+	webhooks *webhook.Dispatcher
+	feed     *feed.Hub
+
+	stats *lyrics.Cache
+
+	limiter ratelimit.Store
+	limits  struct {
+		normal    ratelimit.Limit
+		expensive ratelimit.Limit
+	}
 }
 
 func (s *service) Router() *mux.Router {
 	return s.router
 }
 
-func NewService(c config.Config, r base.Repository) Service {
+func NewService(c config.Config, r base.Repository, a *auth.Authenticator, d *webhook.Dispatcher, f *feed.Hub) Service {
 	router := mux.NewRouter()
 
 	s := service{
 		router: router,
-		repo:   r,
-		cfg: c,
+		server: &http.Server{
+			Addr:              c.GetPort(),
+			Handler:           router,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		done: make(chan struct{}),
+		repo: r,
+		cfg:  c,
+		auth: a,
+
+		webhooks: d,
+		feed:     f,
+
+		stats: lyrics.NewCache(10000),
+
+		limiter: ratelimit.NewMemoryStore(10 * time.Minute),
 	}
+	rps, burst := c.GetRateLimit()
+	s.limits.normal = ratelimit.Limit{Rate: rps, Burst: burst}
+	rps, burst = c.GetExpensiveRateLimit()
+	s.limits.expensive = ratelimit.Limit{Rate: rps, Burst: burst}
 
 	s.setupRoutes()
 
 	return &s
 }
 
+// Run serves until Shutdown and returns once the open requests are done.
 func (s *service) Run() error {
-	port := s.cfg.GetPort()
-	log.Printf("Server running on port %s", port)
-	if err := http.ListenAndServe(port, s.router); err != nil {
-		return fmt.Errorf("Failed to listen and serve. Error: %s", err.Error())
+	log.Printf("Server running on port %s", s.server.Addr)
+	if s.cfg.GetIdempotencyTTL() > 0 {
+		go s.purgeIdempotencyKeys()
+	}
+	err := s.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		<-s.done
+		return nil
+	}
+
+	return fmt.Errorf("Failed to listen and serve. Error: %s", err.Error())
+}
+
+// Shutdown ends the event streams, which would otherwise never finish, and
+// waits for the other requests until ctx is done.
+func (s *service) Shutdown(ctx context.Context) error {
+	defer close(s.done)
+	log.Println("Shutting down server...")
+	s.feed.Close()
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("Failed to shut down server. Error: %w", err)
 	}
 
 	return nil
 }
 
 func (s *service) setupRoutes() {
-	s.router.HandleFunc("/music", s.Library).Methods("GET")
-	s.router.HandleFunc("/music", s.Add).Methods("POST")
-	s.router.HandleFunc("/music/filter", s.Filter).Methods("GET")
-	s.router.HandleFunc("/music/{group}/{song}", s.Update).Methods("PUT")
-	s.router.HandleFunc("/music/{group}/{song}", s.Delete).Methods("DELETE")
-	s.router.HandleFunc("/music/{group}/{song}/lyrics", s.Lyrics).Methods("GET")
-	s.router.HandleFunc("/music/{page}/{size}", s.LibraryWithPagination).Methods("GET")
-	s.router.HandleFunc("/music/filter/{page}/{size}", s.FilterWithPagination).Methods("GET")
-	s.router.HandleFunc("/music/{group}/{song}/lyrics/{page}/{size}", s.LyricsWithPagination).Methods("GET")
+	s.router.Use(compress, s.authenticate, s.rateLimit, s.idempotent)
+
+	read := s.readRole()
+	s.router.Handle("/music", s.cacheable(s.expensive(s.require(read, s.Library)))).Methods("GET")
+	s.router.Handle("/music", s.require(auth.Editor, s.Add)).Methods("POST")
+	s.router.Handle("/music", s.require(auth.Admin, s.Purge)).Methods("DELETE")
+	s.router.Handle("/music/import", s.expensive(s.require(auth.Admin, s.Import))).Methods("POST")
+	s.router.Handle("/music/suggest", s.expensive(s.require(read, s.Suggest))).Methods("GET")
+	s.router.Handle("/music/filter", s.cacheable(s.expensive(s.require(read, s.Filter)))).Methods("GET")
+	s.router.Handle("/music/{group}/{song}", s.require(auth.Editor, s.Update)).Methods("PUT")
+	s.router.Handle("/music/{group}/{song}", s.require(auth.Editor, s.Delete)).Methods("DELETE")
+	s.router.Handle("/music/{group}/{song}/lyrics", s.cacheable(s.require(read, s.Lyrics))).Methods("GET")
+	s.router.Handle("/music/{page}/{size}", s.cacheable(s.require(read, s.LibraryWithPagination))).Methods("GET")
+	s.router.Handle("/music/filter/{page}/{size}", s.cacheable(s.expensive(s.require(read, s.FilterWithPagination)))).Methods("GET")
+	s.router.Handle("/music/{group}/{song}/lyrics/{page}/{size}", s.cacheable(s.require(read, s.LyricsWithPagination))).Methods("GET")
+
+	s.setupBatchRoutes()
+	s.setupUserRoutes()
+	s.setupPlaylistRoutes()
+	s.setupTagRoutes()
+	s.setupLyricsRoutes()
+	s.setupVariantRoutes()
+	s.setupStatsRoutes()
+	s.setupMergeRoutes()
+	s.setupGraphQLRoutes()
+	s.setupWebhookRoutes()
+	s.setupEventRoutes()
+	s.setupMetricsRoutes()
 }
 
 // @Summary Получить библиотеку песен с пагинацией
 // @Description Возвращает список песен с пагинацией
 // @Tags music
-// @Produce json
+// @Produce json,application/x-ndjson,text/csv,application/msgpack
 // @Param page path int true "Номер страницы"
 // @Param size path int true "Размер страницы"
 // @Success 200 {array} model.Song
-// @Failure 400 {string} string "Invalid page number or size"
-// @Failure 500 {string} string "Failed to fetch library data"
+// @Failure 400 {object} Problem "Invalid page number or size"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 406 {object} Problem "Unsupported Accept"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/{page}/{size} [get]
 func (s *service) LibraryWithPagination(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	page, err := strconv.Atoi(params["page"])
+	page, size, err := pagination(params)
 	if err != nil {
-		http.Error(w, "Invalid page number", http.StatusBadRequest)
+		s.problem(w, r, err)
 		return
 	}
-	size, err := strconv.Atoi(params["size"])
+	format, err := negotiate(w, r, songFormats...)
 	if err != nil {
-		http.Error(w, "Invalid page size", http.StatusBadRequest)
+		s.problem(w, r, err)
 		return
 	}
 
-	lib, err := s.repo.GetLibraryWithPagination(page, size)
+	lib, err := s.repo.GetLibraryWithPagination(r.Context(), page, size)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Failed to fetch library data", http.StatusInternalServerError)
+		s.problem(w, r, err)
 		return
 	}
 	log.Printf("Successfully fetched song library data with page: %d, size: %d", page, size)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(lib)
-
+	if err := writeSongs(w, format, lib, lib); err != nil {
+		log.Printf("Failed to write library. Error: %s", err.Error())
+	}
 }
 
 // @Summary Получить библиотеку песен c фильтром и пагинацией
 // @Description Возвращает список песен c фильтром и пагинацией
 // @Tags music
-// @Produce json
+// @Produce json,application/x-ndjson,text/csv,application/msgpack
 // @Param page path int true "Номер страницы"
 // @Param size path int true "Размер страницы"
+// @Param group query string false "Имя группы"
+// @Param song query string false "Название песни"
+// @Param album query string false "Альбом"
+// @Param release_date query string false "Дата выхода"
+// @Param tag query []string false "Теги, параметр можно повторять" collectionFormat(multi)
+// @Param tag_mode query string false "all (по умолчанию) — песня содержит все теги, any — хотя бы один"
+// @Param sort query string false "Поле сортировки: group, song, album или release_date, с префиксом - по убыванию"
 // @Success 200 {array} model.Song
-// @Failure 400 {string} string "Invalid page number or size"
-// @Failure 404 {string} string "Song not found"
+// @Failure 400 {object} Problem "Invalid page number, size or filter"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 406 {object} Problem "Unsupported Accept"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/filter/{page}/{size} [get]
 func (s *service) FilterWithPagination(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	page, err := strconv.Atoi(params["page"])
+	page, size, err := pagination(params)
 	if err != nil {
-		http.Error(w, "Invalid page number", http.StatusBadRequest)
+		s.problem(w, r, err)
 		return
 	}
-	size, err := strconv.Atoi(params["size"])
+	format, err := negotiate(w, r, songFormats...)
 	if err != nil {
-		http.Error(w, "Invalid page size", http.StatusBadRequest)
+		s.problem(w, r, err)
 		return
 	}
 
 	filter := r.URL.Query().Encode()
-	target, err := s.repo.FindWithFilterAndPagination(filter, page, size)
+	target, err := s.repo.FindWithFilterAndPagination(r.Context(), filter, page, size)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Song not found", http.StatusNotFound)
+		s.problem(w, r, err)
 		return
 	}
 
 	log.Printf("Successfully finded with filter: %s, page: %d, size: %d", filter, page, size)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(target)
+	if err := writeSongs(w, format, target, target); err != nil {
+		log.Printf("Failed to write songs. Error: %s", err.Error())
+	}
 }
 
 // LyricsWithPagination gets lyrics with pagination
 // @Summary Get lyrics with pagination
 // @Description Returns lyrics for a given group and title with pagination support
 // @Tags music
-// @Produce json
+// @Produce json,plain
 // @Param group path string true "Group name"
 // @Param song path string true "Song title"
 // @Param page path int true "Page number"
-// @Param size path int true "Page size"
+// @Param size path int true "Page size, at most 1000"
 // @Success 200 {array} string "Lyrics"
-// @Failure 400 {string} string "Invalid page number or size"
-// @Failure 404 {string} string "Song not found"
+// @Failure 400 {object} Problem "Invalid page number or size"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 406 {object} Problem "Unsupported Accept"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/{group}/{song}/lyrics/{page}/{size} [get]
 func (s *service) LyricsWithPagination(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
-	page, err := strconv.Atoi(params["page"])
+	page, size, err := pagination(params)
 	if err != nil {
-		http.Error(w, "Invalid page number", http.StatusBadRequest)
+		s.problem(w, r, err)
 		return
 	}
-	size, err := strconv.Atoi(params["size"])
+	format, err := negotiate(w, r, lyricsFormats...)
 	if err != nil {
-		http.Error(w, "Invalid page size", http.StatusBadRequest)
+		s.problem(w, r, err)
 		return
 	}
 
 	group := params["group"]
 	song := params["song"]
-	lyrics, err := s.repo.GetLyricsWithPagination(group, song, page, size)
+	lyrics, err := s.repo.GetLyricsWithPagination(r.Context(), group, song, page, size)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Song not found", http.StatusNotFound)
+		s.problem(w, r, err)
 		return
 	}
 	log.Printf("Successfully getting lyrics group: %s, song: %s, page: %d, size: %d", group, song, page, size)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(lyrics)
+	if err := writeLyrics(w, format, lyrics, lyrics); err != nil {
+		log.Printf("Failed to write lyrics. Error: %s", err.Error())
+	}
 }
 
 // Filter фильтрует песни по заданным критериям
 // @Summary Фильтрация песен
 // @Description Возвращает список песен, отфильтрованных по заданным критериям
 // @Tags music
-// @Produce json
-// @Param filter query string false "Критерии фильтрации в формате ключ=значение"
+// @Produce json,application/x-ndjson,text/csv,application/msgpack
+// @Param group query string false "Имя группы"
+// @Param song query string false "Название песни"
+// @Param album query string false "Альбом"
+// @Param release_date query string false "Дата выхода"
+// @Param tag query []string false "Теги, параметр можно повторять" collectionFormat(multi)
+// @Param tag_mode query string false "all (по умолчанию) — песня содержит все теги, any — хотя бы один"
+// @Param sort query string false "Поле сортировки: group, song, album или release_date, с префиксом - по убыванию"
 // @Success 200 {array} model.Song "Список отфильтрованных песен"
-// @Failure 404 {string} string "Song not found"
+// @Failure 400 {object} Problem "Invalid filter"
+// @Failure 404 {object} Problem "Song not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 406 {object} Problem "Unsupported Accept"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/filter [get]
 func (s *service) Filter(w http.ResponseWriter, r *http.Request) {
+	format, err := negotiate(w, r, songFormats...)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
 	filter := r.URL.Query().Encode()
-	target, err := s.repo.FindWithFilter(filter)
+	target, err := s.repo.FindWithFilter(r.Context(), filter)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Song not found", http.StatusNotFound)
+		s.problem(w, r, err)
 		return
 	}
 	log.Printf("Successfully finded with filter: %s", filter)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(target)
+	if err := writeSongs(w, format, target, []model.Song{target}); err != nil {
+		log.Printf("Failed to write song. Error: %s", err.Error())
+	}
 }
 
 // Library возвращает библиотеку песен
 // @Summary Получить библиотеку песен
-// @Description Возвращает полную библиотеку песен
+// @Description Возвращает полную библиотеку песен. Формат ответа выбирается заголовком Accept: JSON (по умолчанию), NDJSON, CSV или MessagePack
 // @Tags music
-// @Produce json
+// @Produce json,application/x-ndjson,text/csv,application/msgpack
 // @Success 200 {array} model.Song "Полный список песен"
-// @Failure 500 {string} string "Failed to fetch library data"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 406 {object} Problem "Unsupported Accept"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/library [get]
 func (s *service) Library(w http.ResponseWriter, r *http.Request) {
-	lib, err := s.repo.GetLibrary()
+	format, err := negotiate(w, r, songFormats...)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	lib, err := s.repo.GetLibrary(r.Context())
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Failed to fetch library data", http.StatusInternalServerError)
+		s.problem(w, r, err)
 		return
 	}
 	log.Println("Successfully fetched song library data")
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(lib)
+	if err := writeSongs(w, format, lib, lib); err != nil {
+		log.Printf("Failed to write library. Error: %s", err.Error())
+	}
 }
 
 // Lyrics возвращает текст песни по указанной группе и названию
 // @Summary Получить текст песни
-// @Description Возвращает текст песни на основе имени группы и названия песни
+// @Description Возвращает текст песни на основе имени группы и названия песни. Язык выбирается по параметру lang или заголовку Accept-Language, если перевода нет, возвращается оригинал. С заголовком Accept: text/plain текст возвращается без JSON
 // @Tags music
-// @Produce json
+// @Produce json,plain
 // @Param group path string true "Имя группы"
 // @Param song path string true "Название песни"
+// @Param lang query string false "Язык в формате BCP-47, имеет приоритет над Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки"
 // @Success 200 {string} string "Текст песни"
-// @Failure 404 {string} string "Song not found"
+// @Header 200 {string} Content-Language "Язык текста"
+// @Failure 404 {object} Problem "Song not found, suggestions lists similar songs"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required when reads are not public"
+// @Failure 406 {object} Problem "Unsupported Accept"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/{group}/{song}/lyrics [get]
 func (s *service) Lyrics(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	group, song := params["group"], params["song"]
-	text, err := s.repo.GetLyrics(group, song)
+	format, err := negotiate(w, r, lyricsFormats...)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	target, variants, err := s.repo.GetVariantsByName(r.Context(), group, song)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Song not found", http.StatusNotFound)
+		s.problem(w, r, err)
 		return
 	}
+	variant := preferredVariant(r, withOriginal(target, variants))
 	log.Printf("Getting lyrics group: %s, song: %s completed", group, song)
 
+	w.Header().Add("Vary", "Accept-Language")
+	if variant.Language != undetermined {
+		w.Header().Set("Content-Language", variant.Language)
+	}
+	if err := writeLyrics(w, format, variant.Text, []string{variant.Text}); err != nil {
+		log.Printf("Failed to write lyrics. Error: %s", err.Error())
+	}
+}
+
+// Suggest подсказывает песни по началу или похожему написанию
+// @Summary Подсказки
+// @Description Ищет песни по группе и названию без учета регистра и диакритики, по префиксу и по сходству триграмм
+// @Tags music
+// @Produce json
+// @Param q query string true "Строка поиска"
+// @Param limit query int false "Количество подсказок, от 1 до 50, по умолчанию 10"
+// @Success 200 {array} model.Suggestion "Подсказки"
+// @Failure 400 {object} Problem "Missing query or invalid limit"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /music/suggest [get]
+func (s *service) Suggest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		s.problem(w, r, base.Validation("Query q is required"))
+		return
+	}
+	limit := 10
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > 50 {
+			s.problem(w, r, base.Validation("Invalid limit, expected 1 to 50"))
+			return
+		}
+	}
+
+	suggestions, err := s.repo.Suggest(r.Context(), q, limit)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(text)
+	json.NewEncoder(w).Encode(suggestions)
 }
 
 // Delete удаляет песню из библиотеки по указанной группе и названию
@@ -254,15 +463,23 @@ func (s *service) Lyrics(w http.ResponseWriter, r *http.Request) {
 // @Param group path string true "Имя группы"
 // @Param song path string true "Название песни"
 // @Success 204 "Песня успешно удалена"
-// @Failure 500 {string} string "Failed to delete song"
+// @Failure 404 {object} Problem "Song not found, suggestions lists similar songs"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/{group}/{song} [delete]
 func (s *service) Delete(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	group, song := params["group"], params["song"]
-	err := s.repo.DeleteSong(group, song)
+	err := s.repo.DeleteSong(r.Context(), group, song)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Failed to delete song", http.StatusInternalServerError)
+		s.problem(w, r, err)
 		return
 	}
 	log.Printf("Group: %s, song:%s successfully removed from the library", group, song)
@@ -278,25 +495,34 @@ func (s *service) Delete(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param group path string true "Имя группы"
 // @Param song path string true "Название песни"
-// @Param song body model.Song true "Обновленная информация о песне"
+// @Param song body dto.SongUpdateRequest true "Обновленная информация о песне"
 // @Success 200 "Песня успешно обновлена"
-// @Failure 400 {string} string "Invalid request payload"
-// @Failure 500 {string} string "Failed to update song"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 404 {object} Problem "Song not found, suggestions lists similar songs"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music/{group}/{song} [put]
 func (s *service) Update(w http.ResponseWriter, r *http.Request) {
-	var updatedSong model.Song
-	if err := json.NewDecoder(r.Body).Decode(&updatedSong); err != nil {
+	var request dto.SongUpdateRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
 		log.Println("Error decoding request body:", err)
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		s.problem(w, r, err)
 		return
 	}
 
 	params := mux.Vars(r)
 	group, song := params["group"], params["song"]
-	err := s.repo.UpdateSong(group, song, updatedSong)
+	err := s.repo.UpdateSong(r.Context(), group, song, request.Model())
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Failed to update song", http.StatusInternalServerError)
+		s.problem(w, r, err)
 		return
 	}
 	log.Printf("Group: %s, song: %s successfully updated", group, song)
@@ -310,39 +536,129 @@ func (s *service) Update(w http.ResponseWriter, r *http.Request) {
 // @Tags music
 // @Accept json
 // @Produce json
-// @Param song body model.Song true "Информация о новой песне"
+// @Param song body dto.SongRequest true "Информация о новой песне"
+// @Param Idempotency-Key header string false "Ключ идемпотентности, повтор с тем же ключом возвращает сохраненный ответ"
 // @Success 201 "Песня успешно добавлена"
-// @Failure 400 {string} string "Ошибка декодирования JSON"
-// @Failure 500 {string} string "Что-то пошло не так"
+// @Failure 400 {object} Problem "Invalid JSON body"
+// @Failure 409 {object} Problem "Song is already in the library or a request with the Idempotency-Key is in progress"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 422 {object} Problem "Idempotency-Key was used with a different request"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /music [post]
 func (s *service) Add(w http.ResponseWriter, r *http.Request) {
-	var newSong model.Song
-	if err := json.NewDecoder(r.Body).Decode(&newSong); err != nil {
+	var request dto.SongRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
 		log.Printf("Error decoding JSON: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		s.problem(w, r, err)
+		return
+	}
+	newSong := request.Model()
+
+	// The unique index on the folded name rejects a song that is already in
+	// the library.
+	if _, err := s.repo.AddSong(r.Context(), newSong); err != nil {
+		log.Println(err.Error())
+		s.problem(w, r, err)
 		return
 	}
 
-	log.Printf("Searching group: %s, song: %s", newSong.Group_name, newSong.Song)
-	status, err := s.repo.Find(newSong.Group_name, newSong.Song)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Import загружает список песен в библиотеку
+// @Summary Импортировать песни
+// @Description Добавляет список песен транзакциями по 500 песен, пропуская уже существующие. Каждая транзакция ограничена QUERY_TIMEOUT; при ошибке уже добавленные части остаются в библиотеке, повторный импорт того же списка пропускает их
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param songs body []dto.SongRequest true "Список песен"
+// @Param Idempotency-Key header string false "Ключ идемпотентности, повтор с тем же ключом возвращает сохраненный ответ"
+// @Success 200 {object} model.ImportResult "Результат импорта"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 409 {object} Problem "A request with the Idempotency-Key is in progress"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 422 {object} Problem "Idempotency-Key was used with a different request"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /music/import [post]
+func (s *service) Import(w http.ResponseWriter, r *http.Request) {
+	var request dto.SongImport
+	if err := decode(w, r, &request, maxImportSize); err != nil {
+		log.Printf("Error decoding JSON: %v", err)
+		s.problem(w, r, err)
+		return
+	}
+
+	result, err := s.repo.ImportSongs(r.Context(), request.Model())
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		s.problem(w, r, err)
 		return
 	}
 
-	if !status {
-		err := s.repo.AddSong(newSong)
-		if err != nil {
-			log.Println(err.Error())
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-	} else {
-		log.Printf("Group: %s, song: %s is already in the library", newSong.Group_name, newSong.Song)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// Purge удаляет все песни из библиотеки
+// @Summary Очистить библиотеку
+// @Description Удаляет все песни из библиотеки транзакциями по 500 песен. Каждая транзакция ограничена QUERY_TIMEOUT; при ошибке уже удаленные песни не восстанавливаются, повторный запрос удаляет оставшиеся
+// @Tags admin
+// @Success 204 "Библиотека очищена"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 500 {object} Problem "Internal server error"
+// @Failure 503 {object} Problem "Database is unavailable"
+// @Failure 504 {object} Problem "Database query timed out"
+// @Failure 429 {object} Problem "Too many requests"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /music [delete]
+func (s *service) Purge(w http.ResponseWriter, r *http.Request) {
+	deleted, err := s.repo.Purge(r.Context())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
 	}
+	log.Printf("Library purged by %s, %d songs removed", auth.FromContext(r.Context()).Subject, deleted)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maxPageSize bounds the size path parameter, like the batches of the gRPC
+// API.
+const maxPageSize = 1000
+
+// pagination reads the page and size path parameters. Both are 1-based.
+func pagination(params map[string]string) (int, int, error) {
+	page, err := strconv.Atoi(params["page"])
+	if err != nil || page < 1 {
+		return 0, 0, base.Validation("Invalid page number")
+	}
+	size, err := strconv.Atoi(params["size"])
+	if err != nil || size < 1 {
+		return 0, 0, base.Validation("Invalid page size")
+	}
+	if size > maxPageSize {
+		return 0, 0, base.Validation("Page size must be at most %d", maxPageSize)
+	}
+
+	return page, size, nil
 }
 
 func (s *service) Close() error {
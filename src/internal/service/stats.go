@@ -0,0 +1,125 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"music/internal/base"
+	"music/internal/lyrics"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultTop = 10
+
+func (s *service) setupStatsRoutes() {
+	read := s.readRole()
+	s.router.Handle("/songs/{id}/lyrics/stats", s.require(read, s.LyricsStats)).Methods("GET")
+	s.router.Handle("/artists/{group}/lyrics/stats", s.expensive(s.require(read, s.ArtistStats))).Methods("GET")
+}
+
+// statsParams reads the stop-word language and the number of top words.
+func statsParams(r *http.Request) (string, int, error) {
+	query := r.URL.Query()
+	top := defaultTop
+	if value := query.Get("top"); value != "" {
+		var err error
+		top, err = strconv.Atoi(value)
+		if err != nil || top < 1 || top > lyrics.MaxTop {
+			return "", 0, base.Validation("Invalid top, expected 1 to %d", lyrics.MaxTop)
+		}
+	}
+
+	return query.Get("lang"), top, nil
+}
+
+// ArtistLyricsStats sums up the lyrics of all songs of a group.
+type ArtistLyricsStats struct {
+	Group string `json:"group"`
+	Songs int    `json:"songs"`
+	lyrics.Stats
+}
+
+// LyricsStats возвращает статистику текста песни
+// @Summary Статистика текста
+// @Description Считает куплеты, строки и слова, долю уникальных слов, самые частые слова без стоп-слов и время чтения. Результат кешируется до изменения текста
+// @Tags lyrics
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param lang query string false "Язык стоп-слов, по умолчанию определяется по тексту"
+// @Param top query int false "Количество частых слов, от 1 до 100"
+// @Success 200 {object} lyrics.Stats "Статистика"
+// @Failure 400 {object} Problem "Invalid top"
+// @Failure 404 {object} Problem "Song not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics/stats [get]
+func (s *service) LyricsStats(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	lang, top, err := statsParams(r)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	song, err := s.repo.GetSong(r.Context(), songID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.stats.Analyze(strconv.FormatUint(uint64(songID), 10), song.Lyrics, lang, top))
+}
+
+// ArtistStats возвращает статистику текстов группы
+// @Summary Статистика текстов группы
+// @Description Суммирует статистику текстов всех песен группы
+// @Tags lyrics
+// @Produce json
+// @Param group path string true "Имя группы"
+// @Param lang query string false "Язык стоп-слов, по умолчанию определяется по текстам"
+// @Param top query int false "Количество частых слов, от 1 до 100"
+// @Success 200 {object} ArtistLyricsStats "Статистика"
+// @Failure 400 {object} Problem "Invalid top"
+// @Failure 404 {object} Problem "Group not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /artists/{group}/lyrics/stats [get]
+func (s *service) ArtistStats(w http.ResponseWriter, r *http.Request) {
+	group := mux.Vars(r)["group"]
+	lang, top, err := statsParams(r)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	songs, err := s.repo.GetArtistSongs(r.Context(), group)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	all := make([]lyrics.Stats, 0, len(songs))
+	for _, song := range songs {
+		all = append(all, s.stats.Analyze(strconv.FormatUint(uint64(song.ID), 10), song.Lyrics, lang, lyrics.MaxTop))
+	}
+	language := lang
+	if language == "" && len(all) > 0 {
+		language = all[0].Language
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ArtistLyricsStats{
+		Group: group,
+		Songs: len(songs),
+		Stats: lyrics.Merge(language, all, top),
+	})
+}
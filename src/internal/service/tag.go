@@ -0,0 +1,231 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"music/internal/auth"
+	"music/internal/dto"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *service) setupTagRoutes() {
+	read := s.readRole()
+	s.router.Handle("/tags", s.require(read, s.Tags)).Methods("GET")
+	s.router.Handle("/tags", s.require(auth.Editor, s.CreateTag)).Methods("POST")
+	s.router.Handle("/tags/cloud", s.require(read, s.TagCloud)).Methods("GET")
+	s.router.Handle("/tags/{tag}", s.require(auth.Editor, s.DeleteTag)).Methods("DELETE")
+
+	s.router.Handle("/songs/{id}/tags", s.require(read, s.SongTags)).Methods("GET")
+	s.router.Handle("/songs/{id}/tags", s.require(auth.Editor, s.TagSong)).Methods("POST")
+	s.router.Handle("/songs/{id}/tags/{tag}", s.require(auth.Editor, s.UntagSong)).Methods("DELETE")
+}
+
+// Tags возвращает теги
+// @Summary Список тегов
+// @Tags tags
+// @Produce json
+// @Param kind query string false "Тип тега: genre, mood, language или other"
+// @Success 200 {array} model.Tag "Теги"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags [get]
+func (s *service) Tags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.repo.GetTags(r.Context(), r.URL.Query().Get("kind"))
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// CreateTag создает тег
+// @Summary Создать тег
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body dto.TagRequest true "Тег"
+// @Success 201 {object} model.Tag "Созданный тег"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 409 {object} Problem "Tag already exists"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags [post]
+func (s *service) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var request dto.TagRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	tag, err := s.repo.CreateTag(r.Context(), request.Model())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+// DeleteTag удаляет тег
+// @Summary Удалить тег
+// @Description Удаляет тег и снимает его со всех песен
+// @Tags tags
+// @Param tag path int true "ID тега"
+// @Success 204 "Тег удален"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Tag not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags/{tag} [delete]
+func (s *service) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := pathID(mux.Vars(r), "tag")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.DeleteTag(r.Context(), tagID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TagCloud возвращает облако тегов
+// @Summary Облако тегов
+// @Description Возвращает теги с количеством песен, начиная с самых популярных
+// @Tags tags
+// @Produce json
+// @Param kind query string false "Тип тега: genre, mood, language или other"
+// @Success 200 {array} model.TagCount "Облако тегов"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags/cloud [get]
+func (s *service) TagCloud(w http.ResponseWriter, r *http.Request) {
+	cloud, err := s.repo.TagCloud(r.Context(), r.URL.Query().Get("kind"))
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cloud)
+}
+
+// SongTags возвращает теги песни
+// @Summary Теги песни
+// @Tags tags
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {array} model.Tag "Теги песни"
+// @Failure 404 {object} Problem "Song not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/tags [get]
+func (s *service) SongTags(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	tags, err := s.repo.GetSongTags(r.Context(), songID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// TagSong отмечает песню тегом
+// @Summary Добавить тег песне
+// @Description Отмечает песню тегом, создавая тег при необходимости
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param tag body dto.TagRequest true "Тег"
+// @Success 200 {object} model.Tag "Тег"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Song not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/tags [post]
+func (s *service) TagSong(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	var request dto.TagRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	tag, err := s.repo.TagSong(r.Context(), songID, request.Model())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+// UntagSong снимает тег с песни
+// @Summary Снять тег с песни
+// @Tags tags
+// @Param id path int true "ID песни"
+// @Param tag path int true "ID тега"
+// @Success 204 "Тег снят"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Song is not tagged with the tag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/tags/{tag} [delete]
+func (s *service) UntagSong(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	songID, err := pathID(params, "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	tagID, err := pathID(params, "tag")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.UntagSong(r.Context(), songID, tagID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,487 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/dto"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionResponse is the session token issued at login.
+type SessionResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (s *service) setupUserRoutes() {
+	s.router.HandleFunc("/users", s.Register).Methods("POST")
+	s.router.Handle("/sessions", s.expensive(http.HandlerFunc(s.Login))).Methods("POST")
+	s.router.Handle("/sessions", s.user(s.Logout)).Methods("DELETE")
+
+	s.router.Handle("/me", s.user(s.Me)).Methods("GET")
+	s.router.Handle("/me/favorites", s.user(s.Favorites)).Methods("GET")
+	s.router.Handle("/me/favorites/{page}/{size}", s.user(s.FavoritesWithPagination)).Methods("GET")
+	s.router.Handle("/me/favorites/{id}", s.user(s.AddFavorite)).Methods("PUT")
+	s.router.Handle("/me/favorites/{id}", s.user(s.RemoveFavorite)).Methods("DELETE")
+
+	s.router.Handle("/me/lists", s.user(s.Lists)).Methods("GET")
+	s.router.Handle("/me/lists", s.user(s.CreateList)).Methods("POST")
+	s.router.Handle("/me/lists/{list}", s.user(s.DeleteList)).Methods("DELETE")
+	s.router.Handle("/me/lists/{list}/songs", s.user(s.ListSongs)).Methods("GET")
+	s.router.Handle("/me/lists/{list}/songs/{page}/{size}", s.user(s.ListSongs)).Methods("GET")
+	s.router.Handle("/me/lists/{list}/songs/{id}", s.user(s.AddToList)).Methods("PUT")
+	s.router.Handle("/me/lists/{list}/songs/{id}", s.user(s.RemoveFromList)).Methods("DELETE")
+}
+
+// user wraps a handler that needs a signed in user.
+func (s *service) user(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.FromContext(r.Context()).UserID == 0 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="music"`)
+			s.problem(w, r, base.Unauthenticated("User session required"))
+			return
+		}
+
+		h(w, r)
+	})
+}
+
+// pathID reads a numeric path parameter.
+func pathID(params map[string]string, name string) (uint, error) {
+	id, err := strconv.ParseUint(params[name], 10, 32)
+	if err != nil || id == 0 {
+		return 0, base.Validation("Invalid %s", name)
+	}
+
+	return uint(id), nil
+}
+
+// Register регистрирует нового пользователя
+// @Summary Регистрация
+// @Description Создает учетную запись пользователя
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param credentials body dto.Credentials true "Логин и пароль"
+// @Success 201 {object} model.User "Созданный пользователь"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 409 {object} Problem "Login is already taken"
+// @Failure 429 {object} Problem "Too many requests"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /users [post]
+func (s *service) Register(w http.ResponseWriter, r *http.Request) {
+	var request dto.Credentials
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	hash, err := auth.HashPassword(request.Password)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		s.problem(w, r, base.Validation("Password must be at most 72 bytes long"))
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	user, err := s.repo.CreateUser(r.Context(), request.Login, hash)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// Login выдает токен сессии
+// @Summary Вход
+// @Description Проверяет логин и пароль и выдает токен сессии для заголовка Authorization: Bearer
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param credentials body dto.Credentials true "Логин и пароль"
+// @Success 201 {object} SessionResponse "Токен сессии"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "Invalid login or password"
+// @Failure 429 {object} Problem "Too many requests"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /sessions [post]
+func (s *service) Login(w http.ResponseWriter, r *http.Request) {
+	var request dto.Credentials
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	user, err := s.repo.GetUserByLogin(r.Context(), request.Login)
+	if err != nil && !errors.Is(err, base.ErrNotFound) {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+	// An unknown login has an empty hash, which takes as long to check as a
+	// wrong password, so the response does not tell the logins that exist.
+	if !auth.CheckPassword(user.PasswordHash, request.Password) {
+		s.problem(w, r, base.Unauthenticated("Invalid login or password"))
+		return
+	}
+
+	token, hash, err := auth.NewSessionToken()
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(s.cfg.GetSessionTTL())
+	if err := s.repo.CreateSession(r.Context(), user.ID, hash, expiresAt); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+	log.Printf("User: %s signed in", user.Login)
+
+	w.Header().Set("Content-Type", "application/json")
+	// The token must not be cached, nor kept for idempotent retries.
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(SessionResponse{Token: token, ExpiresAt: expiresAt})
+}
+
+// Logout завершает текущую сессию
+// @Summary Выход
+// @Description Отзывает токен сессии, переданный в заголовке Authorization
+// @Tags users
+// @Success 204 "Сессия завершена"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security BearerAuth
+// @Router /sessions [delete]
+func (s *service) Logout(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+	if err := s.repo.DeleteSession(r.Context(), auth.HashToken(token)); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me возвращает текущего пользователя
+// @Summary Текущий пользователь
+// @Tags users
+// @Produce json
+// @Success 200 {object} auth.Principal "Текущий пользователь"
+// @Failure 401 {object} Problem "User session required"
+// @Security BearerAuth
+// @Router /me [get]
+func (s *service) Me(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.FromContext(r.Context()))
+}
+
+// Favorites возвращает избранные песни пользователя
+// @Summary Избранное
+// @Description Возвращает избранные песни, поддерживает те же фильтры, что и /music/filter
+// @Tags users
+// @Produce json
+// @Param filter query string false "Критерии фильтрации в формате ключ=значение"
+// @Success 200 {array} model.Song "Избранные песни"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security BearerAuth
+// @Router /me/favorites [get]
+func (s *service) Favorites(w http.ResponseWriter, r *http.Request) {
+	userID := auth.FromContext(r.Context()).UserID
+	songs, err := s.repo.GetFavorites(r.Context(), userID, r.URL.Query().Encode())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(songs)
+}
+
+// FavoritesWithPagination возвращает избранные песни с пагинацией
+// @Summary Избранное с пагинацией
+// @Tags users
+// @Produce json
+// @Param page path int true "Номер страницы"
+// @Param size path int true "Размер страницы"
+// @Param filter query string false "Критерии фильтрации в формате ключ=значение"
+// @Success 200 {array} model.Song "Избранные песни"
+// @Failure 400 {object} Problem "Invalid page number or size"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security BearerAuth
+// @Router /me/favorites/{page}/{size} [get]
+func (s *service) FavoritesWithPagination(w http.ResponseWriter, r *http.Request) {
+	page, size, err := pagination(mux.Vars(r))
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	userID := auth.FromContext(r.Context()).UserID
+	songs, err := s.repo.GetFavoritesWithPagination(r.Context(), userID, r.URL.Query().Encode(), page, size)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(songs)
+}
+
+// AddFavorite добавляет песню в избранное
+// @Summary Добавить в избранное
+// @Tags users
+// @Param id path int true "ID песни"
+// @Success 204 "Песня добавлена в избранное"
+// @Failure 400 {object} Problem "Invalid id"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 404 {object} Problem "Song not found"
+// @Security BearerAuth
+// @Router /me/favorites/{id} [put]
+func (s *service) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.AddFavorite(r.Context(), auth.FromContext(r.Context()).UserID, songID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFavorite удаляет песню из избранного
+// @Summary Удалить из избранного
+// @Tags users
+// @Param id path int true "ID песни"
+// @Success 204 "Песня удалена из избранного"
+// @Failure 400 {object} Problem "Invalid id"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 404 {object} Problem "Song is not in favorites"
+// @Security BearerAuth
+// @Router /me/favorites/{id} [delete]
+func (s *service) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.RemoveFavorite(r.Context(), auth.FromContext(r.Context()).UserID, songID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Lists возвращает списки пользователя
+// @Summary Списки прослушивания
+// @Tags users
+// @Produce json
+// @Success 200 {array} model.List "Списки пользователя"
+// @Failure 401 {object} Problem "User session required"
+// @Security BearerAuth
+// @Router /me/lists [get]
+func (s *service) Lists(w http.ResponseWriter, r *http.Request) {
+	lists, err := s.repo.GetLists(r.Context(), auth.FromContext(r.Context()).UserID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lists)
+}
+
+// CreateList создает список прослушивания
+// @Summary Создать список
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param list body dto.ListRequest true "Название списка"
+// @Success 201 {object} model.List "Созданный список"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 409 {object} Problem "List already exists"
+// @Security BearerAuth
+// @Router /me/lists [post]
+func (s *service) CreateList(w http.ResponseWriter, r *http.Request) {
+	var request dto.ListRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	list, err := s.repo.CreateList(r.Context(), auth.FromContext(r.Context()).UserID, request.Name)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+// DeleteList удаляет список прослушивания
+// @Summary Удалить список
+// @Tags users
+// @Param list path int true "ID списка"
+// @Success 204 "Список удален"
+// @Failure 400 {object} Problem "Invalid list"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 404 {object} Problem "List not found"
+// @Security BearerAuth
+// @Router /me/lists/{list} [delete]
+func (s *service) DeleteList(w http.ResponseWriter, r *http.Request) {
+	listID, err := pathID(mux.Vars(r), "list")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.DeleteList(r.Context(), auth.FromContext(r.Context()).UserID, listID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSongs возвращает песни списка
+// @Summary Песни списка
+// @Description Возвращает песни списка в порядке добавления, с пагинацией при указании page и size
+// @Tags users
+// @Produce json
+// @Param list path int true "ID списка"
+// @Success 200 {array} model.Song "Песни списка"
+// @Failure 400 {object} Problem "Invalid list, page number or size"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 404 {object} Problem "List not found"
+// @Security BearerAuth
+// @Router /me/lists/{list}/songs [get]
+func (s *service) ListSongs(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	listID, err := pathID(params, "list")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	page, size := 1, 0
+	if _, ok := params["page"]; ok {
+		if page, size, err = pagination(params); err != nil {
+			s.problem(w, r, err)
+			return
+		}
+	}
+
+	songs, err := s.repo.GetListSongs(r.Context(), auth.FromContext(r.Context()).UserID, listID, page, size)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(songs)
+}
+
+// AddToList добавляет песню в список
+// @Summary Добавить песню в список
+// @Tags users
+// @Param list path int true "ID списка"
+// @Param id path int true "ID песни"
+// @Success 204 "Песня добавлена"
+// @Failure 400 {object} Problem "Invalid list or id"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 404 {object} Problem "List or song not found"
+// @Security BearerAuth
+// @Router /me/lists/{list}/songs/{id} [put]
+func (s *service) AddToList(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	listID, err := pathID(params, "list")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	songID, err := pathID(params, "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.AddToList(r.Context(), auth.FromContext(r.Context()).UserID, listID, songID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFromList удаляет песню из списка
+// @Summary Удалить песню из списка
+// @Tags users
+// @Param list path int true "ID списка"
+// @Param id path int true "ID песни"
+// @Success 204 "Песня удалена"
+// @Failure 400 {object} Problem "Invalid list or id"
+// @Failure 401 {object} Problem "User session required"
+// @Failure 404 {object} Problem "List not found or song is not in the list"
+// @Security BearerAuth
+// @Router /me/lists/{list}/songs/{id} [delete]
+func (s *service) RemoveFromList(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	listID, err := pathID(params, "list")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	songID, err := pathID(params, "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.RemoveFromList(r.Context(), auth.FromContext(r.Context()).UserID, listID, songID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
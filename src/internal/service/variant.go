@@ -0,0 +1,233 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/dto"
+	"music/internal/lyrics"
+	"music/internal/model"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/text/language"
+)
+
+// undetermined is the BCP-47 tag of song lyrics that have no variants yet.
+const undetermined = "und"
+
+func (s *service) setupVariantRoutes() {
+	read := s.readRole()
+	s.router.Handle("/songs/{id}/lyrics/variants", s.require(read, s.Variants)).Methods("GET")
+	s.router.Handle("/songs/{id}/lyrics/variants/{lang}", s.require(auth.Editor, s.SetVariant)).Methods("PUT")
+	s.router.Handle("/songs/{id}/lyrics/variants/{lang}", s.require(auth.Editor, s.DeleteVariant)).Methods("DELETE")
+	s.router.Handle("/songs/{id}/lyrics/aligned", s.require(read, s.AlignedLyrics)).Methods("GET")
+}
+
+// withOriginal lists the variants with the original first. Songs without an
+// original variant fall back to their lyrics in an undetermined language.
+func withOriginal(song model.Song, variants []model.LyricsVariant) []model.LyricsVariant {
+	if len(variants) > 0 && variants[0].Original {
+		return variants
+	}
+
+	original := model.LyricsVariant{SongID: song.ID, Language: undetermined, Original: true, Text: song.Lyrics}
+	return append([]model.LyricsVariant{original}, variants...)
+}
+
+// preferredLanguages returns ?lang= or else the Accept-Language header.
+func preferredLanguages(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return lang
+	}
+
+	return r.Header.Get("Accept-Language")
+}
+
+// preferredVariant picks the variant in the preferred language, falling back
+// to the original, which comes first.
+func preferredVariant(r *http.Request, variants []model.LyricsVariant) model.LyricsVariant {
+	languages := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		languages = append(languages, variant.Language)
+	}
+	index, _ := lyrics.Match(languages, preferredLanguages(r))
+
+	return variants[index]
+}
+
+func languageTag(params map[string]string) (string, error) {
+	tag, err := language.Parse(params["lang"])
+	if err != nil {
+		return "", base.Validation("Invalid language tag: %s", params["lang"])
+	}
+
+	return tag.String(), nil
+}
+
+// Variants возвращает варианты текста песни
+// @Summary Варианты текста
+// @Description Возвращает оригинал и переводы текста песни, оригинал первым
+// @Tags lyrics
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {array} model.LyricsVariant "Варианты текста"
+// @Failure 404 {object} Problem "Song not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics/variants [get]
+func (s *service) Variants(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	song, variants, err := s.repo.GetVariants(r.Context(), songID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withOriginal(song, variants))
+}
+
+// SetVariant сохраняет вариант текста песни
+// @Summary Сохранить вариант текста
+// @Description Создает или заменяет текст песни на указанном языке. Вариант, отмеченный как оригинал, заменяет текст песни
+// @Tags lyrics
+// @Accept json
+// @Param id path int true "ID песни"
+// @Param lang path string true "Язык в формате BCP-47"
+// @Param variant body dto.VariantRequest true "Текст"
+// @Success 204 "Вариант сохранен"
+// @Failure 400 {object} Problem "Invalid language tag or payload"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Song not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics/variants/{lang} [put]
+func (s *service) SetVariant(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	songID, err := pathID(params, "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	lang, err := languageTag(params)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	var request dto.VariantRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	variant := model.LyricsVariant{Language: lang, Original: request.Original, Text: request.Text}
+	if err := s.repo.SetVariant(r.Context(), songID, variant); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteVariant удаляет вариант текста песни
+// @Summary Удалить вариант текста
+// @Description Удаляет перевод. Оригинал удалить нельзя
+// @Tags lyrics
+// @Param id path int true "ID песни"
+// @Param lang path string true "Язык в формате BCP-47"
+// @Success 204 "Вариант удален"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Editor role required"
+// @Failure 404 {object} Problem "Variant not found"
+// @Failure 409 {object} Problem "The original can not be deleted"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics/variants/{lang} [delete]
+func (s *service) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	songID, err := pathID(params, "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	lang, err := languageTag(params)
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.DeleteVariant(r.Context(), songID, lang); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AlignedLyrics is the original and a translation side by side.
+type AlignedLyrics struct {
+	Original    string        `json:"original"`
+	Translation string        `json:"translation"`
+	Verses      []lyrics.Pair `json:"verses"`
+}
+
+// AlignedLyrics возвращает оригинал и перевод куплет за куплетом
+// @Summary Оригинал и перевод рядом
+// @Description Сопоставляет куплеты оригинала и перевода по порядку. Куплеты разделяются пустой строкой
+// @Tags lyrics
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param lang query string false "Язык перевода в формате BCP-47, имеет приоритет над Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки"
+// @Success 200 {object} AlignedLyrics "Куплеты"
+// @Failure 404 {object} Problem "Song not found or no translation in the language"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/lyrics/aligned [get]
+func (s *service) AlignedLyrics(w http.ResponseWriter, r *http.Request) {
+	songID, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	song, variants, err := s.repo.GetVariants(r.Context(), songID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+	variants = withOriginal(song, variants)
+	original, translations := variants[0], variants[1:]
+
+	languages := make([]string, 0, len(translations))
+	for _, variant := range translations {
+		languages = append(languages, variant.Language)
+	}
+	index, ok := lyrics.Match(languages, preferredLanguages(r))
+	if !ok {
+		s.problem(w, r, base.NotFound("Song %d has no translation in %s", songID, preferredLanguages(r)))
+		return
+	}
+	translation := translations[index]
+
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AlignedLyrics{
+		Original:    original.Language,
+		Translation: translation.Language,
+		Verses:      lyrics.Align(original.Text, translation.Text),
+	})
+}
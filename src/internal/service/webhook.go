@@ -0,0 +1,252 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/dto"
+	"music/internal/model"
+	"music/internal/webhook"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const deliveryLogSize = 100
+
+func (s *service) setupWebhookRoutes() {
+	s.router.Handle("/webhooks", s.require(auth.Admin, s.Webhooks)).Methods("GET")
+	s.router.Handle("/webhooks", s.require(auth.Admin, s.CreateWebhook)).Methods("POST")
+	s.router.Handle("/webhooks/dead-letters", s.require(auth.Admin, s.DeadLetters)).Methods("GET")
+	s.router.Handle("/webhooks/deliveries/{delivery}/retry", s.require(auth.Admin, s.RetryDelivery)).Methods("POST")
+	s.router.Handle("/webhooks/{webhook}", s.require(auth.Admin, s.Webhook)).Methods("GET")
+	s.router.Handle("/webhooks/{webhook}", s.require(auth.Admin, s.DeleteWebhook)).Methods("DELETE")
+	s.router.Handle("/webhooks/{webhook}/deliveries", s.require(auth.Admin, s.Deliveries)).Methods("GET")
+}
+
+// Webhooks возвращает подписки на события
+// @Summary Список вебхуков
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} model.Webhook "Вебхуки без секретов"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (s *service) Webhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := s.repo.GetWebhooks(r.Context())
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// CreateWebhook подписывает URL на события
+// @Summary Создать вебхук
+// @Description Подписывает URL на события song.created, song.updated и song.deleted, пустой список событий подписывает на все. Время отправки в секундах Unix и тело запроса, соединенные точкой, подписываются HMAC-SHA256 с секретом вебхука: время передается в заголовке X-Music-Timestamp, подпись — в заголовке X-Music-Signature в виде sha256=<hex>. Получатель отклоняет запросы старше 5 минут. Если секрет не задан, он генерируется и возвращается только в этом ответе
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body dto.WebhookRequest true "URL, секрет и события"
+// @Success 201 {object} model.Webhook "Созданный вебхук с секретом"
+// @Failure 400 {object} Problem "Invalid request payload"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (s *service) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var request dto.WebhookRequest
+	if err := decode(w, r, &request, maxBodySize); err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	newWebhook := request.Model()
+	if newWebhook.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			log.Println(err)
+			s.problem(w, r, err)
+			return
+		}
+		newWebhook.Secret = secret
+	}
+
+	created, err := s.repo.CreateWebhook(r.Context(), newWebhook)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// The secret must not be cached, nor kept for idempotent retries.
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// Webhook возвращает вебхук
+// @Summary Вебхук
+// @Tags webhooks
+// @Produce json
+// @Param webhook path int true "ID вебхука"
+// @Success 200 {object} model.Webhook "Вебхук без секрета"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 404 {object} Problem "Webhook not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{webhook} [get]
+func (s *service) Webhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(mux.Vars(r), "webhook")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	found, err := s.repo.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+	found.Secret = ""
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(found)
+}
+
+// DeleteWebhook удаляет вебхук
+// @Summary Удалить вебхук
+// @Description Удаляет вебхук вместе с его журналом доставок
+// @Tags webhooks
+// @Param webhook path int true "ID вебхука"
+// @Success 204 "Вебхук удален"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 404 {object} Problem "Webhook not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{webhook} [delete]
+func (s *service) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(mux.Vars(r), "webhook")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+
+	if err := s.repo.DeleteWebhook(r.Context(), webhookID); err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries возвращает журнал доставок вебхука
+// @Summary Журнал доставок
+// @Description Возвращает последние 100 доставок вебхука, начиная с новых
+// @Tags webhooks
+// @Produce json
+// @Param webhook path int true "ID вебхука"
+// @Param status query string false "Статус: pending, delivered или dead"
+// @Success 200 {array} model.WebhookDelivery "Доставки"
+// @Failure 400 {object} Problem "Invalid status"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 404 {object} Problem "Webhook not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{webhook}/deliveries [get]
+func (s *service) Deliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(mux.Vars(r), "webhook")
+	if err != nil {
+		s.problem(w, r, err)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", model.DeliveryPending, model.DeliveryDelivered, model.DeliveryDead:
+	default:
+		s.problem(w, r, base.Validation("Invalid status, expected pending, delivered or dead"))
+		return
+	}
+
+	s.writeDeliveries(w, r, webhookID, status)
+}
+
+// DeadLetters возвращает недоставленные события
+// @Summary Недоставленные события
+// @Description Возвращает последние 100 доставок всех вебхуков, исчерпавших попытки
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} model.WebhookDelivery "Недоставленные события"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/dead-letters [get]
+func (s *service) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	s.writeDeliveries(w, r, 0, model.DeliveryDead)
+}
+
+func (s *service) writeDeliveries(w http.ResponseWriter, r *http.Request, webhookID uint, status string) {
+	deliveries, err := s.repo.GetDeliveries(r.Context(), webhookID, status, deliveryLogSize)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// RetryDelivery повторяет недоставленное событие
+// @Summary Повторить доставку
+// @Description Возвращает недоставленное событие в очередь с новым набором попыток
+// @Tags webhooks
+// @Produce json
+// @Param delivery path int true "ID доставки"
+// @Success 202 {object} model.WebhookDelivery "Доставка в очереди"
+// @Failure 400 {object} Problem "Invalid delivery id"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 404 {object} Problem "Delivery not found"
+// @Failure 409 {object} Problem "Delivery is not dead"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/deliveries/{delivery}/retry [post]
+func (s *service) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := strconv.ParseUint(mux.Vars(r)["delivery"], 10, 64)
+	if err != nil || deliveryID == 0 {
+		s.problem(w, r, base.Validation("Invalid delivery"))
+		return
+	}
+
+	delivery, err := s.repo.RetryDelivery(r.Context(), deliveryID)
+	if err != nil {
+		log.Println(err)
+		s.problem(w, r, err)
+		return
+	}
+	s.webhooks.Wake()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
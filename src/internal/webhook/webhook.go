@@ -0,0 +1,257 @@
+// Package webhook delivers catalog events to the subscribed webhooks.
+//
+// The dispatcher is an outbox sink: it queues a delivery per subscribed
+// webhook in the database, so deliveries survive restarts and every replica
+// can make them. Payloads are signed with HMAC-SHA256 of the send time and
+// the body keyed by the webhook secret, failed deliveries are retried with exponential
+// backoff and end up in the dead-letter list after MaxAttempts.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"music/internal/base"
+	"music/internal/model"
+	"music/internal/outbox"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	MaxAttempts = 8
+
+	firstRetry  = 30 * time.Second
+	maxRetry    = 6 * time.Hour
+	timeout     = 10 * time.Second
+	poll        = 5 * time.Second
+	batchSize   = 20
+	maxResponse = 1024
+
+	// lease outlasts the sequential delivery of a whole batch, so no other
+	// worker reclaims a delivery that is still in flight.
+	lease = batchSize*timeout + time.Minute
+)
+
+// SignatureTolerance is how far the X-Music-Timestamp of a request may be
+// from the clock of the receiver. Older requests are rejected, so a captured
+// request cannot be replayed later.
+const SignatureTolerance = 5 * time.Minute
+
+type Dispatcher struct {
+	repo   base.WebhookRepository
+	client *http.Client
+	wake   chan struct{}
+}
+
+func NewDispatcher(repo base.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: timeout},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhook"
+}
+
+// Publish queues the outbox event for the subscribed webhooks and wakes the
+// worker. The webhooks receive the outbox envelope.
+func (d *Dispatcher) Publish(ctx context.Context, event model.OutboxEvent) error {
+	payload, err := json.Marshal(outbox.NewEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("Failed to encode event: %d. Error: %w", event.ID, err)
+	}
+
+	queued, err := d.repo.QueueEvent(ctx, event.ID, event.Event, payload)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		d.Wake()
+	}
+
+	return nil
+}
+
+// Wake makes the worker look for due deliveries without waiting for the poll.
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers the due deliveries until ctx is canceled. It wakes up on
+// Publish and polls for retries and events queued by other replicas.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		for d.deliverBatch(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliverBatch reports whether a full batch was claimed and more may be due.
+// When ctx is canceled the deliveries not attempted yet are released, so
+// they neither count an attempt nor die unsent.
+func (d *Dispatcher) deliverBatch(ctx context.Context) bool {
+	claimed, err := d.repo.ClaimDeliveries(ctx, batchSize, lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Println(err)
+		}
+		return false
+	}
+
+	for i, pending := range claimed {
+		if ctx.Err() != nil {
+			d.release(context.WithoutCancel(ctx), claimed[i:])
+			return false
+		}
+		delivery := d.deliver(ctx, pending)
+		if delivery.Status != model.DeliveryDelivered && ctx.Err() != nil {
+			// The attempt failed because of the cancellation, not the webhook.
+			d.release(context.WithoutCancel(ctx), claimed[i:])
+			return false
+		}
+		if err := d.repo.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+			log.Println(err)
+		}
+	}
+
+	return len(claimed) == batchSize
+}
+
+func (d *Dispatcher) release(ctx context.Context, claimed []model.PendingDelivery) {
+	ids := make([]uint64, 0, len(claimed))
+	for _, pending := range claimed {
+		ids = append(ids, pending.Delivery.ID)
+	}
+	if err := d.repo.ReleaseDeliveries(ctx, ids); err != nil {
+		log.Println(err)
+	}
+}
+
+// deliver posts the payload once and returns the delivery with the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, pending model.PendingDelivery) model.WebhookDelivery {
+	delivery := pending.Delivery
+	delivery.LastStatusCode = 0
+	delivery.LastError = ""
+
+	status, err := d.post(ctx, pending)
+	delivery.LastStatusCode = status
+	if err == nil {
+		now := time.Now()
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = now
+		log.Printf("Delivered %s %d to webhook %d", delivery.Event, delivery.ID, delivery.WebhookID)
+		return delivery
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= MaxAttempts {
+		delivery.Status = model.DeliveryDead
+		log.Printf("Webhook delivery %d is dead after %d attempts. Error: %s", delivery.ID, delivery.Attempts, err)
+		return delivery
+	}
+	delivery.Status = model.DeliveryPending
+	delivery.NextAttemptAt = time.Now().Add(Backoff(delivery.Attempts))
+	log.Printf("Webhook delivery %d failed, retrying at %s. Error: %s", delivery.ID, delivery.NextAttemptAt.Format(time.RFC3339), err)
+
+	return delivery
+}
+
+func (d *Dispatcher) post(ctx context.Context, pending model.PendingDelivery) (int, error) {
+	body := []byte(pending.Delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pending.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "music-webhooks")
+	req.Header.Set("X-Music-Event", pending.Delivery.Event)
+	req.Header.Set("X-Music-Delivery", strconv.FormatUint(pending.Delivery.ID, 10))
+	timestamp := time.Now().Unix()
+	req.Header.Set("X-Music-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Music-Signature", Sign(pending.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("Webhook responded %s: %s", resp.Status, bytes.TrimSpace(response))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Music-Signature header of the body sent at the Unix
+// timestamp, "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot and
+// the body keyed by the secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the X-Music-Signature and X-Music-Timestamp headers of a
+// request received at now, as a receiver should.
+func Verify(secret, signature, timestamp string, body []byte, now time.Time) error {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid webhook timestamp: %s", timestamp)
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("Webhook timestamp %s is outside the tolerance", timestamp)
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, sent, body))) {
+		return fmt.Errorf("Invalid webhook signature")
+	}
+
+	return nil
+}
+
+// Backoff is the wait after the given failed attempt: 30s doubling up to 6h.
+func Backoff(attempt int) time.Duration {
+	wait := firstRetry
+	for i := 1; i < attempt && wait < maxRetry; i++ {
+		wait *= 2
+	}
+
+	return min(wait, maxRetry)
+}
+
+// NewSecret generates a signing secret for a webhook created without one.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("Failed to generate webhook secret. Error: %w", err)
+	}
+
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log"
+	"music/internal/base"
+	"music/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// repository keeps a single delivery and claims it like the database does:
+// a due pending delivery is claimed with one more attempt.
+type repository struct {
+	base.WebhookRepository
+	mu       sync.Mutex
+	delivery model.WebhookDelivery
+	url      string
+	secret   string
+}
+
+func (r *repository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.PendingDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.delivery.Status != model.DeliveryPending {
+		return nil, nil
+	}
+
+	r.delivery.Attempts++
+	return []model.PendingDelivery{{Delivery: r.delivery, URL: r.url, Secret: r.secret}}, nil
+}
+
+func (r *repository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delivery = delivery
+	return nil
+}
+
+func (r *repository) ReleaseDeliveries(ctx context.Context, deliveryIDs []uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range deliveryIDs {
+		if id == r.delivery.ID {
+			r.delivery.Attempts--
+		}
+	}
+	return nil
+}
+
+// receiver records the requests it gets and answers them with status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := rc.status
+	rc.mu.Unlock()
+
+	w.WriteHeader(status)
+	io.WriteString(w, "received")
+}
+
+func newDelivery(t *testing.T, status int) (*Dispatcher, *repository, *receiver) {
+	t.Helper()
+	rc := &receiver{status: status}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	repo := &repository{
+		delivery: model.WebhookDelivery{
+			ID:        42,
+			WebhookID: 3,
+			Event:     model.SongCreated,
+			Payload:   model.RawJSON(`{"type":"song.created","data":{"ID":1,"group":"Muse","song":"Hysteria"}}`),
+			Status:    model.DeliveryPending,
+		},
+		url:    server.URL,
+		secret: "0123456789abcdef",
+	}
+
+	return NewDispatcher(repo), repo, rc
+}
+
+func TestDeliverSigned(t *testing.T) {
+	d, repo, rc := newDelivery(t, http.StatusNoContent)
+
+	d.deliverBatch(context.Background())
+
+	if len(rc.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(rc.requests))
+	}
+	req, body := rc.requests[0], rc.bodies[0]
+	if string(body) != string(repo.delivery.Payload) {
+		t.Errorf("body = %s, want the payload", body)
+	}
+	if err := Verify(repo.secret, req.Header.Get("X-Music-Signature"), req.Header.Get("X-Music-Timestamp"), body, time.Now()); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if got := req.Header.Get("X-Music-Event"); got != model.SongCreated {
+		t.Errorf("X-Music-Event = %s, want %s", got, model.SongCreated)
+	}
+	if got := req.Header.Get("X-Music-Delivery"); got != "42" {
+		t.Errorf("X-Music-Delivery = %s, want 42", got)
+	}
+
+	if repo.delivery.Status != model.DeliveryDelivered || repo.delivery.DeliveredAt == nil || repo.delivery.LastStatusCode != http.StatusNoContent {
+		t.Errorf("delivery = %+v, want delivered with status 204", repo.delivery)
+	}
+}
+
+func TestDeliverRetriesUntilDead(t *testing.T) {
+	d, repo, rc := newDelivery(t, http.StatusBadGateway)
+
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		before := time.Now()
+		d.deliverBatch(context.Background())
+		delivery := repo.delivery
+
+		if delivery.Attempts != attempt || delivery.LastStatusCode != http.StatusBadGateway || delivery.LastError == "" {
+			t.Fatalf("attempt %d: delivery = %+v, want the 502 of this attempt", attempt, delivery)
+		}
+		if attempt == MaxAttempts {
+			if delivery.Status != model.DeliveryDead {
+				t.Errorf("attempt %d: status = %s, want %s", attempt, delivery.Status, model.DeliveryDead)
+			}
+			break
+		}
+
+		if delivery.Status != model.DeliveryPending {
+			t.Fatalf("attempt %d: status = %s, want %s", attempt, delivery.Status, model.DeliveryPending)
+		}
+		wait := Backoff(attempt)
+		if delivery.NextAttemptAt.Before(before.Add(wait)) || delivery.NextAttemptAt.After(time.Now().Add(wait)) {
+			t.Errorf("attempt %d: next attempt at %s, want %s after it", attempt, delivery.NextAttemptAt, wait)
+		}
+	}
+
+	d.deliverBatch(context.Background())
+	if len(rc.requests) != MaxAttempts {
+		t.Errorf("requests = %d, want %d, a dead delivery is not sent again", len(rc.requests), MaxAttempts)
+	}
+}
+
+// TestDeliverCanceled checks that deliveries claimed when the server stops
+// are released instead of failing, even on their last attempt.
+func TestDeliverCanceled(t *testing.T) {
+	d, repo, rc := newDelivery(t, http.StatusNoContent)
+	repo.delivery.Attempts = MaxAttempts - 1
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if more := d.deliverBatch(ctx); more {
+		t.Error("deliverBatch() = true, want false when canceled")
+	}
+
+	if len(rc.requests) != 0 {
+		t.Errorf("requests = %d, want none", len(rc.requests))
+	}
+	if delivery := repo.delivery; delivery.Status != model.DeliveryPending || delivery.Attempts != MaxAttempts-1 || delivery.LastError != "" {
+		t.Errorf("delivery = %+v, want pending with %d attempts", delivery, MaxAttempts-1)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 30 * time.Second},
+		{attempt: 1, want: 30 * time.Second},
+		{attempt: 2, want: time.Minute},
+		{attempt: 3, want: 2 * time.Minute},
+		{attempt: 7, want: 32 * time.Minute},
+		{attempt: 10, want: 4*time.Hour + 16*time.Minute},
+		{attempt: 11, want: 6 * time.Hour},
+		{attempt: 100, want: 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.attempt), func(t *testing.T) {
+			if got := Backoff(tt.attempt); got != tt.want {
+				t.Errorf("Backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLeaseOutlastsBatch guards against another worker reclaiming
+// deliveries of a batch that is still being posted.
+func TestLeaseOutlastsBatch(t *testing.T) {
+	if worst := batchSize * timeout; lease <= worst {
+		t.Errorf("lease = %s, want longer than the %s a batch of timeouts takes", lease, worst)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 of "1700000000.hello" keyed by "secret".
+	want := "sha256=47b1df0ab12338b2685470b0d2b37033add7c3b2bc8172f313e77413f1bb78c8"
+	if got := Sign("secret", 1700000000, []byte("hello")); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	sent := time.Unix(1700000000, 0)
+	body := []byte("hello")
+	signature := Sign("secret", sent.Unix(), body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		now       time.Time
+		wantErr   bool
+	}{
+		{name: "valid", secret: "secret", timestamp: "1700000000", body: "hello", now: sent.Add(time.Minute)},
+		{name: "clock behind", secret: "secret", timestamp: "1700000000", body: "hello", now: sent.Add(-time.Minute)},
+		{name: "replayed", secret: "secret", timestamp: "1700000000", body: "hello", now: sent.Add(SignatureTolerance + time.Second), wantErr: true},
+		{name: "other timestamp", secret: "secret", timestamp: "1700000001", body: "hello", now: sent, wantErr: true},
+		{name: "other body", secret: "secret", timestamp: "1700000000", body: "hello!", now: sent, wantErr: true},
+		{name: "other secret", secret: "other", timestamp: "1700000000", body: "hello", now: sent, wantErr: true},
+		{name: "invalid timestamp", secret: "secret", timestamp: "yesterday", body: "hello", now: sent, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, signature, tt.timestamp, []byte(tt.body), tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package client
+
+import "net/http"
+
+// Auth sets the credentials of a request.
+type Auth interface {
+	Apply(r *http.Request) error
+}
+
+// AuthFunc adapts a function to Auth.
+type AuthFunc func(r *http.Request) error
+
+func (f AuthFunc) Apply(r *http.Request) error {
+	return f(r)
+}
+
+// APIKey authenticates with a key of the API_KEYS configuration.
+func APIKey(key string) Auth {
+	return AuthFunc(func(r *http.Request) error {
+		r.Header.Set("X-API-Key", key)
+		return nil
+	})
+}
+
+// Bearer authenticates with a JWT or a session token returned by Login.
+func Bearer(token string) Auth {
+	return AuthFunc(func(r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Modes of a batch.
+const (
+	BatchAtomic  = "atomic"
+	BatchPerItem = "per_item"
+)
+
+// Operations of a batch.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchRequest is a list of song writes, applied all or nothing unless Mode
+// is BatchPerItem.
+type BatchRequest struct {
+	Mode       string           `json:"mode,omitempty"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is a create, update or delete. Updates and deletes address
+// songs by ID, or by Group and Song which may match several songs. Values
+// holds the new song of a create and the changed fields of an update.
+type BatchOperation struct {
+	Op     string             `json:"op"`
+	ID     uint               `json:"id,omitempty"`
+	Group  string             `json:"group,omitempty"`
+	Song   string             `json:"song,omitempty"`
+	Values *SongUpdateRequest `json:"values,omitempty"`
+}
+
+// BatchResult is the outcome of an operation of a batch. Err is set when the
+// operation failed or, with a 424 status, was rolled back with its batch.
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status int    `json:"status"`
+	Songs  []Song `json:"songs,omitempty"`
+	Err    *Error `json:"error,omitempty"`
+}
+
+// Batch applies the operations in order, atomically unless the mode of the
+// batch is BatchPerItem. The results follow the order of the operations,
+// committed is false when an atomic batch was rolled back.
+func (c *Client) Batch(ctx context.Context, batch BatchRequest) (results []BatchResult, committed bool, err error) {
+	var response struct {
+		Committed bool          `json:"committed"`
+		Results   []BatchResult `json:"results"`
+	}
+	err = c.do(ctx, request{method: http.MethodPost, path: "/music/batch", body: batch, header: withIdempotencyKey()}, &response)
+	return response.Results, response.Committed, err
+}
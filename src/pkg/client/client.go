@@ -0,0 +1,226 @@
+// Package client is a Go SDK for the music library HTTP API.
+//
+// Every endpoint has a typed method taking a context, the request and
+// response bodies are the types of this package. Idempotent requests,
+// and the song writes which carry an Idempotency-Key, are retried with
+// exponential backoff on 5xx responses and network errors, any request is
+// retried on 429 honoring Retry-After. Errors of the API are
+// returned as *Error decoded from application/problem+json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	baseURL   *url.URL
+	http      *http.Client
+	auth      Auth
+	retry     Retry
+	userAgent string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set a timeout or a
+// transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithAuth authenticates every request, see APIKey and Bearer.
+func WithAuth(auth Auth) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithRetry replaces DefaultRetry. Retry{} disables retries.
+func WithRetry(retry Retry) Option {
+	return func(c *Client) {
+		c.retry = retry
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client of the API served at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse base URL: %s. Error: %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("Unsupported base URL scheme: %q", u.Scheme)
+	}
+
+	c := &Client{
+		baseURL:   u,
+		http:      http.DefaultClient,
+		retry:     DefaultRetry,
+		userAgent: "music-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// request describes one API call. body is sent as JSON unless it is a
+// []byte, which is sent as is with contentType.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        any
+	contentType string
+}
+
+// endpoint escapes the segments and joins them into a path.
+func endpoint(segments ...any) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(fmt.Sprint(segment)))
+	}
+
+	return b.String()
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	u := *c.baseURL
+	u.RawPath = c.baseURL.EscapedPath() + path
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// do sends the request, retrying it according to the retry policy, and
+// decodes a successful JSON response into out when out is not nil.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("Failed to decode response of %s %s. Error: %w", req.method, req.path, err)
+	}
+
+	return nil
+}
+
+// send returns a response with a 2xx status, anything else becomes an error.
+// The caller closes the body.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var payload []byte
+	contentType := req.contentType
+	switch body := req.body.(type) {
+	case nil:
+	case []byte:
+		payload = body
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("Failed to encode request of %s %s. Error: %w", req.method, req.path, err)
+		}
+		contentType = "application/json"
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, req, payload, contentType)
+		if err == nil && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		wait, retry := c.retry.next(attempt, idempotent(req), resp, err)
+		if resp != nil {
+			if !retry {
+				defer resp.Body.Close()
+				return nil, decodeError(resp)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if !retry {
+			return nil, fmt.Errorf("Failed to send %s %s. Error: %w", req.method, req.path, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req request, payload []byte, contentType string) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	r, err := http.NewRequestWithContext(ctx, req.method, c.url(req.path, req.query), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range req.header {
+		r.Header[name] = values
+	}
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", "application/json")
+	}
+	r.Header.Set("User-Agent", c.userAgent)
+	if c.auth != nil {
+		if err := c.auth.Apply(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.http.Do(r)
+}
+
+// text sends the request and returns the response body as a string, for the
+// endpoints that answer with plain text or files.
+func (c *Client) text(ctx context.Context, req request) (string, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read response of %s %s. Error: %w", req.method, req.path, err)
+	}
+
+	return string(body), nil
+}
@@ -0,0 +1,378 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"music/internal/auth"
+	"music/internal/base"
+	"music/internal/feed"
+	"music/internal/model"
+	"music/internal/service"
+	"music/internal/webhook"
+	"music/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	adminKey     = "test-admin-key"
+	readerKey    = "test-reader-key"
+	sessionToken = "test-session-token"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+type config struct{}
+
+func (config) GetConfigSQL() string                  { return "" }
+func (config) GetPort() string                       { return "" }
+func (config) GetGRPCPort() string                   { return "" }
+func (config) GetQueryTimeout() time.Duration        { return time.Second }
+func (config) GetJWKSFile() string                   { return "" }
+func (config) GetPublicReads() bool                  { return true }
+func (config) GetRateLimit() (float64, int)          { return 1000, 1000 }
+func (config) GetExpensiveRateLimit() (float64, int) { return 1000, 1000 }
+func (config) GetSessionTTL() time.Duration          { return time.Hour }
+func (config) GetAutoMigrate() bool                  { return false }
+func (config) GetCache() (time.Duration, int64)      { return 0, 0 }
+func (config) GetIdempotencyTTL() time.Duration      { return 0 }
+func (config) GetEventBusPrefix() string             { return "" }
+func (config) GetOutboxRetention() time.Duration     { return 0 }
+
+func (config) GetAPIKeys() map[string]string {
+	return map[string]string{adminKey: "admin", readerKey: "reader"}
+}
+
+// repository serves the library from memory, the other methods of
+// base.Repository are not used by these tests.
+type repository struct {
+	base.Repository
+	songs []model.Song
+	pages atomic.Int32
+}
+
+func (r *repository) GetLibraryWithPagination(ctx context.Context, page, size int) ([]model.Song, error) {
+	r.pages.Add(1)
+	start := min((page-1)*size, len(r.songs))
+	return r.songs[start:min(start+size, len(r.songs))], nil
+}
+
+func (r *repository) DeleteSong(ctx context.Context, group, song string) error {
+	if group == "Muse" && song == "Hysteria" {
+		return nil
+	}
+
+	return &base.Error{
+		Kind:        base.KindNotFound,
+		Message:     "Song not found: group " + group + ", song " + song,
+		Suggestions: []model.Suggestion{{ID: 1, Group: "Muse", Song: "Hysteria", Score: 0.6}},
+	}
+}
+
+func (r *repository) ImportSongs(ctx context.Context, songs []model.Song) (model.ImportResult, error) {
+	return model.ImportResult{Imported: len(songs)}, nil
+}
+
+func (r *repository) SessionUser(ctx context.Context, tokenHash string) (model.User, error) {
+	if tokenHash != auth.HashToken(sessionToken) {
+		return model.User{}, base.ErrNotFound
+	}
+
+	return model.User{ID: 7, Login: "listener"}, nil
+}
+
+// newServer serves the router of the service over the repository. Requests
+// pass through wrap first, when it is not nil.
+func newServer(t *testing.T, repo *repository, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	authenticator, err := auth.NewAuthenticator(config{}, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var handler http.Handler = service.NewService(config{}, repo, authenticator, webhook.NewDispatcher(nil), feed.NewHub(10)).Router()
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newClient(t *testing.T, url string, opts ...client.Option) *client.Client {
+	t.Helper()
+	c, err := client.New(url, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// failing answers the first n requests with the status and Retry-After
+// before passing the others on, and counts every request.
+func failing(n int32, status int, retryAfter string, count *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if count.Add(1) <= n {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(status)
+				io.WriteString(w, `{"type":"about:blank","title":"`+http.StatusText(status)+`","status":`+strconv.Itoa(status)+`}`)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	retry := client.Retry{Attempts: 3, Base: time.Millisecond, Max: 50 * time.Millisecond}
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		retryAfter   string
+		call         func(c *client.Client) error
+		wantErr      error
+		wantRequests int32
+	}{
+		{
+			name:     "429 with Retry-After",
+			failures: 2, status: http.StatusTooManyRequests, retryAfter: "0",
+			call:         func(c *client.Client) error { _, err := c.LibraryPage(context.Background(), 1, 10); return err },
+			wantRequests: 3,
+		},
+		{
+			name:     "503 of a read",
+			failures: 1, status: http.StatusServiceUnavailable,
+			call:         func(c *client.Client) error { _, err := c.LibraryPage(context.Background(), 1, 10); return err },
+			wantRequests: 2,
+		},
+		{
+			name:     "500 of an import with an Idempotency-Key",
+			failures: 1, status: http.StatusInternalServerError,
+			call: func(c *client.Client) error {
+				_, err := c.Import(context.Background(), []client.SongRequest{{Group: "Muse", Song: "Hysteria"}})
+				return err
+			},
+			wantRequests: 2,
+		},
+		{
+			name:     "500 of a write without a key",
+			failures: 1, status: http.StatusInternalServerError,
+			call: func(c *client.Client) error {
+				_, err := c.CreateTag(context.Background(), client.TagRequest{Name: "rock", Kind: client.TagGenre})
+				return err
+			},
+			wantErr:      client.ErrInternal,
+			wantRequests: 1,
+		},
+		{
+			name:     "attempts exhausted",
+			failures: 10, status: http.StatusServiceUnavailable, retryAfter: "0",
+			call:         func(c *client.Client) error { _, err := c.LibraryPage(context.Background(), 1, 10); return err },
+			wantErr:      client.ErrUnavailable,
+			wantRequests: 4,
+		},
+		{
+			name:     "Retry-After beyond the maximum",
+			failures: 1, status: http.StatusTooManyRequests, retryAfter: "30",
+			call:         func(c *client.Client) error { _, err := c.LibraryPage(context.Background(), 1, 10); return err },
+			wantErr:      client.ErrRateLimited,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count atomic.Int32
+			server := newServer(t, &repository{}, failing(tt.failures, tt.status, tt.retryAfter, &count))
+			c := newClient(t, server.URL, client.WithRetry(retry), client.WithAuth(client.APIKey(adminKey)))
+
+			if err := tt.call(c); !matches(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got := count.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetryAfterOfError(t *testing.T) {
+	var count atomic.Int32
+	server := newServer(t, &repository{}, failing(1, http.StatusTooManyRequests, "30", &count))
+	c := newClient(t, server.URL, client.WithRetry(client.Retry{Attempts: 3, Base: time.Millisecond, Max: time.Second}))
+
+	_, err := c.LibraryPage(context.Background(), 1, 10)
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *client.Error", err)
+	}
+	if apiErr.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %s, want 30s", apiErr.RetryAfter)
+	}
+}
+
+func TestSongsPagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		songs     int
+		size      int
+		take      int
+		wantSongs int
+		wantPages int32
+	}{
+		{name: "last page short", songs: 5, size: 2, wantSongs: 5, wantPages: 3},
+		{name: "last page full", songs: 4, size: 2, wantSongs: 4, wantPages: 3},
+		{name: "empty library", songs: 0, size: 2, wantSongs: 0, wantPages: 1},
+		{name: "stopped early", songs: 5, size: 2, take: 3, wantSongs: 3, wantPages: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository{}
+			for i := range tt.songs {
+				repo.songs = append(repo.songs, model.Song{ID: uint(i + 1), Group_name: "Muse", Song: "Song " + strconv.Itoa(i+1)})
+			}
+			c := newClient(t, newServer(t, repo, nil).URL)
+
+			var got []client.Song
+			for song, err := range c.Songs(context.Background(), tt.size) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, song)
+				if len(got) == tt.take {
+					break
+				}
+			}
+
+			if len(got) != tt.wantSongs {
+				t.Fatalf("songs = %d, want %d", len(got), tt.wantSongs)
+			}
+			for i, song := range got {
+				if song.ID != uint(i+1) || song.Group != "Muse" {
+					t.Errorf("song %d = %+v, want ID %d of Muse", i, song, i+1)
+				}
+			}
+			if pages := repo.pages.Load(); pages != tt.wantPages {
+				t.Errorf("pages = %d, want %d", pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestProblemErrors(t *testing.T) {
+	server := newServer(t, &repository{}, nil)
+	c := newClient(t, server.URL, client.WithAuth(client.APIKey(adminKey)))
+
+	t.Run("not found with suggestions", func(t *testing.T) {
+		err := c.DeleteSong(context.Background(), "Muse", "Hysterya")
+		if !errors.Is(err, client.ErrNotFound) {
+			t.Fatalf("error = %v, want not found", err)
+		}
+		var apiErr *client.Error
+		errors.As(err, &apiErr)
+		if apiErr.Title != "Not Found" || apiErr.Detail != "Song not found: group Muse, song Hysterya" || apiErr.Instance != "/music/Muse/Hysterya" {
+			t.Errorf("error = %+v, want the problem details of the song", apiErr)
+		}
+		want := client.Suggestion{ID: 1, Group: "Muse", Song: "Hysteria", Score: 0.6}
+		if len(apiErr.Suggestions) != 1 || apiErr.Suggestions[0] != want {
+			t.Errorf("suggestions = %+v, want %+v", apiErr.Suggestions, want)
+		}
+	})
+
+	t.Run("violations", func(t *testing.T) {
+		_, err := c.Import(context.Background(), []client.SongRequest{{Group: "Muse", Song: "Hysteria"}, {Song: "Starlight"}})
+		if !errors.Is(err, client.ErrValidation) {
+			t.Fatalf("error = %v, want validation error", err)
+		}
+		var apiErr *client.Error
+		errors.As(err, &apiErr)
+		if len(apiErr.Errors) != 1 || apiErr.Errors[0].Field != "[1].group" {
+			t.Errorf("violations = %+v, want one of [1].group", apiErr.Errors)
+		}
+	})
+}
+
+func TestAuth(t *testing.T) {
+	repo := &repository{}
+	server := newServer(t, repo, nil)
+
+	tests := []struct {
+		name          string
+		auth          client.Auth
+		wantDeleteErr error
+		wantMeErr     error
+		wantUserID    uint
+	}{
+		{name: "anonymous", wantDeleteErr: client.ErrUnauthenticated, wantMeErr: client.ErrUnauthenticated},
+		{name: "admin API key", auth: client.APIKey(adminKey), wantMeErr: client.ErrUnauthenticated},
+		{name: "reader API key", auth: client.APIKey(readerKey), wantDeleteErr: client.ErrForbidden, wantMeErr: client.ErrUnauthenticated},
+		{name: "session", auth: client.Bearer(sessionToken), wantDeleteErr: client.ErrForbidden, wantUserID: 7},
+		{name: "invalid API key", auth: client.APIKey("wrong"), wantDeleteErr: client.ErrUnauthenticated, wantMeErr: client.ErrUnauthenticated},
+		{name: "invalid session", auth: client.Bearer("expired"), wantDeleteErr: client.ErrUnauthenticated, wantMeErr: client.ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []client.Option{client.WithRetry(client.Retry{})}
+			if tt.auth != nil {
+				opts = append(opts, client.WithAuth(tt.auth))
+			}
+			c := newClient(t, server.URL, opts...)
+
+			err := c.DeleteSong(context.Background(), "Muse", "Hysteria")
+			if !matches(err, tt.wantDeleteErr) {
+				t.Errorf("DeleteSong() error = %v, want %v", err, tt.wantDeleteErr)
+			}
+
+			principal, err := c.Me(context.Background())
+			if !matches(err, tt.wantMeErr) {
+				t.Fatalf("Me() error = %v, want %v", err, tt.wantMeErr)
+			}
+			if err == nil && (principal.UserID != tt.wantUserID || principal.Role != "reader" || principal.Subject == "") {
+				t.Errorf("Me() = %+v, want reader user %d", principal, tt.wantUserID)
+			}
+		})
+	}
+}
+
+func TestEventsReset(t *testing.T) {
+	c := newClient(t, newServer(t, &repository{}, nil).URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for event, err := range c.Events(ctx, nil, 5) {
+		if err != nil {
+			t.Fatalf("Events() error = %v", err)
+		}
+		if event.Type != client.Reset || event.ID != 0 {
+			t.Errorf("first event = %+v, want a reset, the server buffers no events after 5", event)
+		}
+		break
+	}
+}
+
+func matches(err, want error) bool {
+	if want == nil {
+		return err == nil
+	}
+
+	return errors.Is(err, want)
+}
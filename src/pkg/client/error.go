@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is an error response of the API, decoded from its RFC 7807 problem
+// details. Responses without problem details keep the status and the body
+// in Detail.
+type Error struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Errors []Violation `json:"errors,omitempty"`
+	// Suggestions lists similar songs when a song was not found.
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
+
+	// RetryAfter is the delay the server asked for on 429 and 503 responses.
+	RetryAfter time.Duration `json:"-"`
+}
+
+// Violation describes a single invalid field of a request.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+var (
+	ErrValidation      = &Error{Status: http.StatusBadRequest}
+	ErrUnauthenticated = &Error{Status: http.StatusUnauthorized}
+	ErrForbidden       = &Error{Status: http.StatusForbidden}
+	ErrNotFound        = &Error{Status: http.StatusNotFound}
+	ErrConflict        = &Error{Status: http.StatusConflict}
+	ErrRateLimited     = &Error{Status: http.StatusTooManyRequests}
+	ErrInternal        = &Error{Status: http.StatusInternalServerError}
+	ErrUnavailable     = &Error{Status: http.StatusServiceUnavailable}
+)
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s", e.Status, e.Title)
+	if e.Detail != "" {
+		fmt.Fprintf(&b, ": %s", e.Detail)
+	}
+	for _, v := range e.Errors {
+		fmt.Fprintf(&b, "; %s %s", v.Field, v.Message)
+	}
+
+	return b.String()
+}
+
+// Is matches errors of the same status, so errors.Is(err, ErrNotFound) works
+// for any not found response.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Status == e.Status
+}
+
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	e := &Error{}
+	if err := json.Unmarshal(body, e); err != nil || e.Status == 0 {
+		e = &Error{Detail: strings.TrimSpace(string(body))}
+	}
+	e.Status = resp.StatusCode
+	if e.Title == "" {
+		e.Title = http.StatusText(resp.StatusCode)
+	}
+	e.RetryAfter, _ = retryAfter(resp.Header, time.Now())
+
+	return e
+}
+
+// retryAfter parses the Retry-After header given either in seconds or as an
+// HTTP date.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+
+	return 0, false
+}
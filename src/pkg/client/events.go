@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEventSize bounds a line of the event stream, songs carry their lyrics.
+const maxEventSize = 4 << 20
+
+// Types of events.
+const (
+	SongCreated = "song.created"
+	SongUpdated = "song.updated"
+	SongDeleted = "song.deleted"
+
+	// Reset is sent instead of the missed events when the server no longer
+	// buffers the events after the resumed one. The receiver reloads the
+	// songs, the event has no ID and song.
+	Reset = "reset"
+)
+
+// Event is a change of the library received from the event stream.
+type Event struct {
+	ID        uint64
+	Type      string
+	CreatedAt time.Time
+	Song      Song
+}
+
+// Events streams the changes of the songs of the groups, or of every song
+// when groups is empty. A non-zero lastID resumes after that event as far as
+// the server still buffers it, otherwise the stream starts with a Reset
+// event. The iteration ends when ctx is canceled or the
+// server closes the stream, reconnect with the ID of the last event to resume.
+func (c *Client) Events(ctx context.Context, groups []string, lastID uint64) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		req := request{
+			method: http.MethodGet,
+			path:   "/music/events",
+			query:  url.Values{"group": groups},
+			header: http.Header{"Accept": {"text/event-stream"}},
+		}
+		if lastID != 0 {
+			req.header.Set("Last-Event-ID", strconv.FormatUint(lastID, 10))
+		}
+		resp, err := c.send(ctx, req)
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), maxEventSize)
+		var id, kind, data string
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if data == "" {
+					continue
+				}
+				event, err := parseEvent(id, kind, data)
+				id, kind, data = "", "", ""
+				if !yield(event, err) || err != nil {
+					return
+				}
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				kind = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			yield(Event{}, fmt.Errorf("Failed to read event stream. Error: %w", err))
+		}
+	}
+}
+
+func parseEvent(id, kind, data string) (Event, error) {
+	if kind == Reset {
+		return Event{Type: Reset}, nil
+	}
+
+	var envelope struct {
+		Type      string    `json:"type"`
+		CreatedAt time.Time `json:"created_at"`
+		Data      Song      `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil {
+		return Event{}, fmt.Errorf("Failed to decode event %s. Error: %w", id, err)
+	}
+	eventID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return Event{}, fmt.Errorf("Invalid event id: %q", id)
+	}
+
+	return Event{ID: eventID, Type: envelope.Type, CreatedAt: envelope.CreatedAt, Song: envelope.Data}, nil
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is an error of a GraphQL response. A response with errors may
+// still carry partial data.
+type GraphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+
+	return fmt.Sprintf("graphql: %s", strings.Join(messages, "; "))
+}
+
+// GraphQL runs a read-only query and decodes its data into out. Errors of
+// the query are returned as GraphQLErrors after out is filled.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	body := map[string]any{"query": query, "variables": variables}
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/graphql", body: body}, &resp); err != nil {
+		return err
+	}
+	if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("Failed to decode GraphQL data. Error: %w", err)
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+
+	return nil
+}
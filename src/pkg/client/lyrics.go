@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SyncedLyrics is the parsed LRC file of a song.
+type SyncedLyrics struct {
+	SongID uint        `json:"song_id"`
+	Lines  []LyricLine `json:"lines"`
+}
+
+// LyricLine is a line of synced lyrics, TimeMs is its start in milliseconds
+// from the beginning of the song. Words are set for enhanced LRC lines.
+type LyricLine struct {
+	Position int         `json:"position"`
+	TimeMs   int64       `json:"time_ms"`
+	Text     string      `json:"text"`
+	Words    []LyricWord `json:"words,omitempty"`
+}
+
+type LyricWord struct {
+	TimeMs int64  `json:"time_ms"`
+	Text   string `json:"text"`
+}
+
+// LyricsVariant is the lyrics of a song in a BCP-47 language.
+type LyricsVariant struct {
+	Language string `json:"language"`
+	Original bool   `json:"original"`
+	Text     string `json:"text"`
+}
+
+// VariantRequest sets the lyrics of a language. Original makes them the
+// lyrics of the song.
+type VariantRequest struct {
+	Text     string `json:"text"`
+	Original bool   `json:"original,omitempty"`
+}
+
+// AlignedLyrics pairs the verses of the original with a translation.
+type AlignedLyrics struct {
+	Original    string      `json:"original"`
+	Translation string      `json:"translation"`
+	Verses      []VersePair `json:"verses"`
+}
+
+// VersePair is a verse of the original and the verse at the same position of
+// the translation.
+type VersePair struct {
+	Original    string `json:"original"`
+	Translation string `json:"translation"`
+}
+
+// LyricsStats describes the words of lyrics.
+type LyricsStats struct {
+	Language           string      `json:"language"`
+	Verses             int         `json:"verses"`
+	Lines              int         `json:"lines"`
+	Words              int         `json:"words"`
+	UniqueWords        int         `json:"unique_words"`
+	UniqueRatio        float64     `json:"unique_ratio"`
+	TopWords           []WordCount `json:"top_words"`
+	ReadingTimeSeconds int         `json:"reading_time_seconds"`
+}
+
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// ArtistStats is the lyrics stats of all songs of a group.
+type ArtistStats struct {
+	Group string `json:"group"`
+	Songs int    `json:"songs"`
+	LyricsStats
+}
+
+// UploadLyrics replaces the synced lyrics of the song with an LRC file.
+func (c *Client) UploadLyrics(ctx context.Context, songID uint, lrc string) (SyncedLyrics, error) {
+	var synced SyncedLyrics
+	req := request{
+		method:      http.MethodPut,
+		path:        endpoint("songs", songID, "lyrics"),
+		body:        []byte(lrc),
+		contentType: "text/plain; charset=utf-8",
+	}
+	err := c.do(ctx, req, &synced)
+	return synced, err
+}
+
+func (c *Client) SyncedLyrics(ctx context.Context, songID uint) (SyncedLyrics, error) {
+	var synced SyncedLyrics
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("songs", songID, "lyrics")}, &synced)
+	return synced, err
+}
+
+// LyricsLine returns the line sung at the moment of the song.
+func (c *Client) LyricsLine(ctx context.Context, songID uint, at time.Duration) (LyricLine, error) {
+	var line LyricLine
+	req := request{
+		method: http.MethodGet,
+		path:   endpoint("songs", songID, "lyrics"),
+		query:  url.Values{"at": {timestamp(at)}},
+	}
+	err := c.do(ctx, req, &line)
+	return line, err
+}
+
+// ExportLyrics returns the synced lyrics in the format, lrc or text.
+func (c *Client) ExportLyrics(ctx context.Context, songID uint, format string) (string, error) {
+	return c.text(ctx, request{
+		method: http.MethodGet,
+		path:   endpoint("songs", songID, "lyrics"),
+		query:  url.Values{"format": {format}},
+		header: http.Header{"Accept": {"text/plain"}},
+	})
+}
+
+func (c *Client) DeleteLyrics(ctx context.Context, songID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("songs", songID, "lyrics")}, nil)
+}
+
+// Variants returns the original lyrics of the song followed by its
+// translations.
+func (c *Client) Variants(ctx context.Context, songID uint) ([]LyricsVariant, error) {
+	var variants []LyricsVariant
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("songs", songID, "lyrics", "variants")}, &variants)
+	return variants, err
+}
+
+// SetVariant creates or replaces the lyrics of the song in the BCP-47
+// language.
+func (c *Client) SetVariant(ctx context.Context, songID uint, lang string, variant VariantRequest) error {
+	req := request{method: http.MethodPut, path: endpoint("songs", songID, "lyrics", "variants", lang), body: variant}
+	return c.do(ctx, req, nil)
+}
+
+func (c *Client) DeleteVariant(ctx context.Context, songID uint, lang string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("songs", songID, "lyrics", "variants", lang)}, nil)
+}
+
+// AlignedLyrics returns the original next to the translation best matching
+// lang, which may be a list in the Accept-Language format.
+func (c *Client) AlignedLyrics(ctx context.Context, songID uint, lang string) (AlignedLyrics, error) {
+	var aligned AlignedLyrics
+	req := request{
+		method: http.MethodGet,
+		path:   endpoint("songs", songID, "lyrics", "aligned"),
+		query:  url.Values{"lang": {lang}},
+	}
+	err := c.do(ctx, req, &aligned)
+	return aligned, err
+}
+
+// LyricsStats analyzes the lyrics of the song. An empty lang is detected by
+// the server, top 0 uses the server default.
+func (c *Client) LyricsStats(ctx context.Context, songID uint, lang string, top int) (LyricsStats, error) {
+	var stats LyricsStats
+	req := request{method: http.MethodGet, path: endpoint("songs", songID, "lyrics", "stats"), query: statsQuery(lang, top)}
+	err := c.do(ctx, req, &stats)
+	return stats, err
+}
+
+func (c *Client) ArtistStats(ctx context.Context, group, lang string, top int) (ArtistStats, error) {
+	var stats ArtistStats
+	req := request{method: http.MethodGet, path: endpoint("artists", group, "lyrics", "stats"), query: statsQuery(lang, top)}
+	err := c.do(ctx, req, &stats)
+	return stats, err
+}
+
+func statsQuery(lang string, top int) url.Values {
+	query := url.Values{}
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+	if top > 0 {
+		query.Set("top", strconv.Itoa(top))
+	}
+
+	return query
+}
+
+// timestamp formats the moment as an LRC timestamp, mm:ss.xx.
+func timestamp(at time.Duration) string {
+	hundredths := int64(at / (10 * time.Millisecond))
+	return fmt.Sprintf("%02d:%02d.%02d", hundredths/6000, hundredths/100%60, hundredths%100)
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Playlist struct {
+	ID          uint      `json:"id"`
+	OwnerID     uint      `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PlaylistEntry is a song at a 0-based position of a playlist. AddedBy is
+// nil for entries of deleted users.
+type PlaylistEntry struct {
+	ID         uint      `json:"id"`
+	PlaylistID uint      `json:"playlist_id"`
+	SongID     uint      `json:"song_id"`
+	Position   int       `json:"position"`
+	AddedBy    *uint     `json:"added_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	Song       Song      `json:"song"`
+}
+
+type PlaylistRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Public      bool   `json:"public,omitempty"`
+}
+
+// PlaylistUpdateRequest changes the fields of a playlist that are not nil.
+type PlaylistUpdateRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Public      *bool   `json:"public,omitempty"`
+}
+
+// EntryRequest adds a song to a playlist, at the end when Position is nil.
+type EntryRequest struct {
+	SongID   uint `json:"song_id"`
+	Position *int `json:"position,omitempty"`
+}
+
+// Playlists returns the playlists the caller owns or collaborates on and the
+// public ones.
+func (c *Client) Playlists(ctx context.Context) ([]Playlist, error) {
+	var playlists []Playlist
+	err := c.do(ctx, request{method: http.MethodGet, path: "/playlists"}, &playlists)
+	return playlists, err
+}
+
+func (c *Client) CreatePlaylist(ctx context.Context, playlist PlaylistRequest) (Playlist, error) {
+	var created Playlist
+	err := c.do(ctx, request{method: http.MethodPost, path: "/playlists", body: playlist}, &created)
+	return created, err
+}
+
+func (c *Client) Playlist(ctx context.Context, playlistID uint) (Playlist, error) {
+	var playlist Playlist
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("playlists", playlistID)}, &playlist)
+	return playlist, err
+}
+
+// UpdatePlaylist changes the fields of the playlist that are not nil.
+func (c *Client) UpdatePlaylist(ctx context.Context, playlistID uint, fields PlaylistUpdateRequest) (Playlist, error) {
+	var playlist Playlist
+	err := c.do(ctx, request{method: http.MethodPatch, path: endpoint("playlists", playlistID), body: fields}, &playlist)
+	return playlist, err
+}
+
+func (c *Client) DeletePlaylist(ctx context.Context, playlistID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("playlists", playlistID)}, nil)
+}
+
+// ExportPlaylist returns the playlist file in the format, m3u or xspf.
+func (c *Client) ExportPlaylist(ctx context.Context, playlistID uint, format string) (string, error) {
+	return c.text(ctx, request{
+		method: http.MethodGet,
+		path:   endpoint("playlists", playlistID, "export"),
+		query:  url.Values{"format": {format}},
+		header: http.Header{"Accept": {"*/*"}},
+	})
+}
+
+func (c *Client) PlaylistEntries(ctx context.Context, playlistID uint) ([]PlaylistEntry, error) {
+	var entries []PlaylistEntry
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("playlists", playlistID, "entries")}, &entries)
+	return entries, err
+}
+
+// InsertEntry adds the song at the position, or at the end when the position
+// is nil.
+func (c *Client) InsertEntry(ctx context.Context, playlistID uint, entry EntryRequest) (PlaylistEntry, error) {
+	var inserted PlaylistEntry
+	req := request{method: http.MethodPost, path: endpoint("playlists", playlistID, "entries"), body: entry}
+	err := c.do(ctx, req, &inserted)
+	return inserted, err
+}
+
+func (c *Client) MoveEntry(ctx context.Context, playlistID, entryID uint, position int) error {
+	req := request{
+		method: http.MethodPatch,
+		path:   endpoint("playlists", playlistID, "entries", entryID),
+		body:   map[string]int{"position": position},
+	}
+	return c.do(ctx, req, nil)
+}
+
+func (c *Client) RemoveEntry(ctx context.Context, playlistID, entryID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("playlists", playlistID, "entries", entryID)}, nil)
+}
+
+func (c *Client) AddCollaborator(ctx context.Context, playlistID, userID uint) error {
+	return c.do(ctx, request{method: http.MethodPut, path: endpoint("playlists", playlistID, "collaborators", userID)}, nil)
+}
+
+func (c *Client) RemoveCollaborator(ctx context.Context, playlistID, userID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("playlists", playlistID, "collaborators", userID)}, nil)
+}
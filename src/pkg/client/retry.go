@@ -0,0 +1,83 @@
+package client
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Retry is the retry policy of a client. Attempt n waits a random delay of up
+// to Base*2^n capped by Max, or the Retry-After of the response when the
+// server sent one. A Retry-After longer than Max is not waited for, the
+// error is returned with its RetryAfter instead.
+type Retry struct {
+	// Attempts is the number of retries after the first request.
+	Attempts int
+	Base     time.Duration
+	Max      time.Duration
+}
+
+var DefaultRetry = Retry{Attempts: 3, Base: 200 * time.Millisecond, Max: 10 * time.Second}
+
+// next decides whether a failed attempt is retried and how long to wait.
+// Requests that may have changed the server state are retried only on 429,
+// which the server answers before running the handler, unless they carry an
+// Idempotency-Key.
+func (p Retry) next(attempt int, safe bool, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.Attempts {
+		return 0, false
+	}
+
+	switch {
+	case resp == nil:
+		if !safe {
+			return 0, false
+		}
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode >= 500 && safe:
+	default:
+		return 0, false
+	}
+
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header, time.Now()); ok {
+			return wait, wait <= p.Max
+		}
+	}
+
+	return p.backoff(attempt), true
+}
+
+func (p Retry) backoff(attempt int) time.Duration {
+	ceiling := p.Max
+	if shift := uint(attempt); shift < 32 && p.Base<<shift < p.Max {
+		ceiling = p.Base << shift
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return rand.N(ceiling) + 1
+}
+
+// idempotent reports whether the request may be sent again after a failure
+// that may have reached the server.
+func idempotent(req request) bool {
+	switch req.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	return req.header.Get("Idempotency-Key") != ""
+}
+
+// withIdempotencyKey returns a header with a new Idempotency-Key. The server
+// runs the request once and replays its response to the retries.
+func withIdempotencyKey() http.Header {
+	key := make([]byte, 16)
+	crand.Read(key)
+
+	return http.Header{"Idempotency-Key": {hex.EncodeToString(key)}}
+}
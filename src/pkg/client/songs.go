@@ -0,0 +1,271 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultPageSize is the page size of the iterators.
+const DefaultPageSize = 100
+
+// Song is a song of the library, Text holds its lyrics.
+type Song struct {
+	ID          uint   `json:"ID"`
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	Album       string `json:"album"`
+	ReleaseDate string `json:"release_date"`
+	Text        string `json:"text"`
+}
+
+// SongRequest is a new song. Group and Song are required, ReleaseDate is
+// formatted as 2006-01-02.
+type SongRequest struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	Album       string `json:"album,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	Text        string `json:"text,omitempty"`
+}
+
+// SongUpdateRequest changes the fields of a song that are not nil.
+type SongUpdateRequest struct {
+	Group       *string `json:"group,omitempty"`
+	Song        *string `json:"song,omitempty"`
+	Album       *string `json:"album,omitempty"`
+	ReleaseDate *string `json:"release_date,omitempty"`
+	Text        *string `json:"text,omitempty"`
+}
+
+// SongFilter selects songs, every list matches any of its values. TagMode is
+// any or all, Sort is a field name with an optional - prefix.
+type SongFilter struct {
+	Group       []string
+	Song        []string
+	Album       []string
+	ReleaseDate []string
+	Tags        []string
+	TagMode     string
+	Sort        string
+}
+
+// ImportResult counts the imported songs and the existing ones skipped.
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// Suggestion is a song with a name similar to the searched one, Score is the
+// similarity from 0 to 1.
+type Suggestion struct {
+	ID    uint    `json:"id"`
+	Group string  `json:"group"`
+	Song  string  `json:"song"`
+	Score float64 `json:"score"`
+}
+
+// Reasons of a duplicate cluster.
+const (
+	ReasonName   = "name"
+	ReasonLyrics = "lyrics"
+)
+
+// Cluster is a set of songs that are probably the same song.
+type Cluster struct {
+	Key     string   `json:"key"`
+	Reasons []string `json:"reasons"`
+	Songs   []Song   `json:"songs"`
+}
+
+// MergeRequest merges the losers into the winner. Fields picks, by field
+// name, the song whose value the survivor keeps.
+type MergeRequest struct {
+	Winner uint            `json:"winner"`
+	Losers []uint          `json:"losers"`
+	Fields map[string]uint `json:"fields,omitempty"`
+}
+
+// Library returns every song of the library.
+func (c *Client) Library(ctx context.Context) ([]Song, error) {
+	var songs []Song
+	err := c.do(ctx, request{method: http.MethodGet, path: "/music"}, &songs)
+	return songs, err
+}
+
+// LibraryPage returns the 1-based page of the library ordered by id.
+func (c *Client) LibraryPage(ctx context.Context, page, size int) ([]Song, error) {
+	var songs []Song
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("music", page, size)}, &songs)
+	return songs, err
+}
+
+// Songs iterates over the library page by page. The iteration stops after
+// the first error.
+func (c *Client) Songs(ctx context.Context, size int) iter.Seq2[Song, error] {
+	return paginate(size, func(page, size int) ([]Song, error) {
+		return c.LibraryPage(ctx, page, size)
+	})
+}
+
+// Filter returns the first song matching the filter, use FilterSongs for
+// all of them.
+func (c *Client) Filter(ctx context.Context, filter SongFilter) (Song, error) {
+	var song Song
+	err := c.do(ctx, request{method: http.MethodGet, path: "/music/filter", query: filterQuery(filter)}, &song)
+	return song, err
+}
+
+func (c *Client) FilterPage(ctx context.Context, filter SongFilter, page, size int) ([]Song, error) {
+	var songs []Song
+	req := request{method: http.MethodGet, path: endpoint("music", "filter", page, size), query: filterQuery(filter)}
+	err := c.do(ctx, req, &songs)
+	return songs, err
+}
+
+// FilterSongs iterates over the songs matching the filter page by page.
+func (c *Client) FilterSongs(ctx context.Context, filter SongFilter, size int) iter.Seq2[Song, error] {
+	return paginate(size, func(page, size int) ([]Song, error) {
+		return c.FilterPage(ctx, filter, page, size)
+	})
+}
+
+// AddSong adds the song. Retries after a lost response do not add it twice.
+func (c *Client) AddSong(ctx context.Context, song SongRequest) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/music", body: song, header: withIdempotencyKey()}, nil)
+}
+
+// UpdateSong changes the fields of the song that are not nil.
+func (c *Client) UpdateSong(ctx context.Context, group, song string, fields SongUpdateRequest) error {
+	return c.do(ctx, request{method: http.MethodPut, path: endpoint("music", group, song), body: fields}, nil)
+}
+
+func (c *Client) DeleteSong(ctx context.Context, group, song string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("music", group, song)}, nil)
+}
+
+// Lyrics returns the lyrics of the song in the language, or in the original
+// language when lang is empty or has no translation.
+func (c *Client) Lyrics(ctx context.Context, group, song, lang string) (string, error) {
+	query := url.Values{}
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+
+	var text string
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("music", group, song, "lyrics"), query: query}, &text)
+	return text, err
+}
+
+// LyricsPage returns the 1-based page of the lyrics of the songs matching
+// the group and name.
+func (c *Client) LyricsPage(ctx context.Context, group, song string, page, size int) ([]string, error) {
+	var texts []string
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("music", group, song, "lyrics", page, size)}, &texts)
+	return texts, err
+}
+
+// LyricsPages iterates over the lyrics of the matching songs page by page.
+func (c *Client) LyricsPages(ctx context.Context, group, song string, size int) iter.Seq2[string, error] {
+	return paginate(size, func(page, size int) ([]string, error) {
+		return c.LyricsPage(ctx, group, song, page, size)
+	})
+}
+
+// Suggest returns up to limit songs with a name similar to q, limit 0 uses
+// the server default.
+func (c *Client) Suggest(ctx context.Context, q string, limit int) ([]Suggestion, error) {
+	query := url.Values{"q": {q}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var suggestions []Suggestion
+	err := c.do(ctx, request{method: http.MethodGet, path: "/music/suggest", query: query}, &suggestions)
+	return suggestions, err
+}
+
+// Import adds the songs in one transaction, skipping the existing ones.
+func (c *Client) Import(ctx context.Context, songs []SongRequest) (ImportResult, error) {
+	var result ImportResult
+	err := c.do(ctx, request{method: http.MethodPost, path: "/music/import", body: songs, header: withIdempotencyKey()}, &result)
+	return result, err
+}
+
+// Purge deletes every song of the library.
+func (c *Client) Purge(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/music"}, nil)
+}
+
+// Duplicates returns the clusters of likely duplicate songs, threshold 0 uses
+// the server default.
+func (c *Client) Duplicates(ctx context.Context, threshold float64) ([]Cluster, error) {
+	query := url.Values{}
+	if threshold > 0 {
+		query.Set("threshold", strconv.FormatFloat(threshold, 'f', -1, 64))
+	}
+
+	var clusters []Cluster
+	err := c.do(ctx, request{method: http.MethodGet, path: "/songs/duplicates", query: query}, &clusters)
+	return clusters, err
+}
+
+// Merge merges the losers into the winner and returns the merged song.
+func (c *Client) Merge(ctx context.Context, merge MergeRequest) (Song, error) {
+	var song Song
+	err := c.do(ctx, request{method: http.MethodPost, path: "/songs/merge", body: merge}, &song)
+	return song, err
+}
+
+func filterQuery(filter SongFilter) url.Values {
+	query := url.Values{}
+	for key, list := range map[string][]string{
+		"group":        filter.Group,
+		"song":         filter.Song,
+		"album":        filter.Album,
+		"release_date": filter.ReleaseDate,
+		"tag":          filter.Tags,
+	} {
+		for _, value := range list {
+			query.Add(key, value)
+		}
+	}
+	if filter.TagMode != "" {
+		query.Set("tag_mode", filter.TagMode)
+	}
+	if filter.Sort != "" {
+		query.Set("sort", filter.Sort)
+	}
+
+	return query
+}
+
+// paginate yields the items of the pages starting from the first one until
+// a page is shorter than size.
+func paginate[T any](size int, fetch func(page, size int) ([]T, error)) iter.Seq2[T, error] {
+	if size < 1 {
+		size = DefaultPageSize
+	}
+
+	return func(yield func(T, error) bool) {
+		for page := 1; ; page++ {
+			items, err := fetch(page, size)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < size {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Kinds of tags.
+const (
+	TagGenre    = "genre"
+	TagMood     = "mood"
+	TagLanguage = "language"
+	TagOther    = "other"
+)
+
+// Tag classifies songs, names are unique within a kind.
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+type TagRequest struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// TagCount is a tag cloud entry.
+type TagCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+// Tags returns the tags of the kind, or every tag when kind is empty.
+func (c *Client) Tags(ctx context.Context, kind string) ([]Tag, error) {
+	var tags []Tag
+	err := c.do(ctx, request{method: http.MethodGet, path: "/tags", query: kindQuery(kind)}, &tags)
+	return tags, err
+}
+
+func (c *Client) CreateTag(ctx context.Context, tag TagRequest) (Tag, error) {
+	var created Tag
+	err := c.do(ctx, request{method: http.MethodPost, path: "/tags", body: tag}, &created)
+	return created, err
+}
+
+func (c *Client) DeleteTag(ctx context.Context, tagID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("tags", tagID)}, nil)
+}
+
+// TagCloud counts the songs of every tag, most used tags first.
+func (c *Client) TagCloud(ctx context.Context, kind string) ([]TagCount, error) {
+	var cloud []TagCount
+	err := c.do(ctx, request{method: http.MethodGet, path: "/tags/cloud", query: kindQuery(kind)}, &cloud)
+	return cloud, err
+}
+
+func (c *Client) SongTags(ctx context.Context, songID uint) ([]Tag, error) {
+	var tags []Tag
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("songs", songID, "tags")}, &tags)
+	return tags, err
+}
+
+// TagSong attaches the tag to the song, creating the tag when it does not
+// exist yet.
+func (c *Client) TagSong(ctx context.Context, songID uint, tag TagRequest) (Tag, error) {
+	var tagged Tag
+	err := c.do(ctx, request{method: http.MethodPost, path: endpoint("songs", songID, "tags"), body: tag}, &tagged)
+	return tagged, err
+}
+
+func (c *Client) UntagSong(ctx context.Context, songID, tagID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("songs", songID, "tags", tagID)}, nil)
+}
+
+func kindQuery(kind string) url.Values {
+	query := url.Values{}
+	if kind != "" {
+		query.Set("kind", kind)
+	}
+
+	return query
+}
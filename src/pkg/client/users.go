@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// Credentials are the login and password of a user.
+type Credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type User struct {
+	ID        uint      `json:"id"`
+	Login     string    `json:"login"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// List is a personal listening list.
+type List struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListRequest struct {
+	Name string `json:"name"`
+}
+
+// Session is a session token returned by Login, use it with Bearer.
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Subject string `json:"subject"`
+	Role    string `json:"role"`
+	UserID  uint   `json:"user_id,omitempty"`
+}
+
+func (c *Client) Register(ctx context.Context, credentials Credentials) (User, error) {
+	var user User
+	err := c.do(ctx, request{method: http.MethodPost, path: "/users", body: credentials}, &user)
+	return user, err
+}
+
+func (c *Client) Login(ctx context.Context, credentials Credentials) (Session, error) {
+	var session Session
+	err := c.do(ctx, request{method: http.MethodPost, path: "/sessions", body: credentials}, &session)
+	return session, err
+}
+
+// Logout ends the session the client is authenticated with.
+func (c *Client) Logout(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/sessions"}, nil)
+}
+
+func (c *Client) Me(ctx context.Context) (Principal, error) {
+	var principal Principal
+	err := c.do(ctx, request{method: http.MethodGet, path: "/me"}, &principal)
+	return principal, err
+}
+
+// Favorites returns the favorite songs matching the filter.
+func (c *Client) Favorites(ctx context.Context, filter SongFilter) ([]Song, error) {
+	var songs []Song
+	err := c.do(ctx, request{method: http.MethodGet, path: "/me/favorites", query: filterQuery(filter)}, &songs)
+	return songs, err
+}
+
+func (c *Client) FavoritesPage(ctx context.Context, filter SongFilter, page, size int) ([]Song, error) {
+	var songs []Song
+	req := request{method: http.MethodGet, path: endpoint("me", "favorites", page, size), query: filterQuery(filter)}
+	err := c.do(ctx, req, &songs)
+	return songs, err
+}
+
+// FavoriteSongs iterates over the favorite songs page by page.
+func (c *Client) FavoriteSongs(ctx context.Context, filter SongFilter, size int) iter.Seq2[Song, error] {
+	return paginate(size, func(page, size int) ([]Song, error) {
+		return c.FavoritesPage(ctx, filter, page, size)
+	})
+}
+
+func (c *Client) AddFavorite(ctx context.Context, songID uint) error {
+	return c.do(ctx, request{method: http.MethodPut, path: endpoint("me", "favorites", songID)}, nil)
+}
+
+func (c *Client) RemoveFavorite(ctx context.Context, songID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("me", "favorites", songID)}, nil)
+}
+
+func (c *Client) Lists(ctx context.Context) ([]List, error) {
+	var lists []List
+	err := c.do(ctx, request{method: http.MethodGet, path: "/me/lists"}, &lists)
+	return lists, err
+}
+
+func (c *Client) CreateList(ctx context.Context, list ListRequest) (List, error) {
+	var created List
+	err := c.do(ctx, request{method: http.MethodPost, path: "/me/lists", body: list}, &created)
+	return created, err
+}
+
+func (c *Client) DeleteList(ctx context.Context, listID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("me", "lists", listID)}, nil)
+}
+
+// ListSongs returns every song of the list in the order they were added.
+func (c *Client) ListSongs(ctx context.Context, listID uint) ([]Song, error) {
+	var songs []Song
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("me", "lists", listID, "songs")}, &songs)
+	return songs, err
+}
+
+func (c *Client) ListSongsPage(ctx context.Context, listID uint, page, size int) ([]Song, error) {
+	var songs []Song
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("me", "lists", listID, "songs", page, size)}, &songs)
+	return songs, err
+}
+
+// AllListSongs iterates over the songs of the list page by page.
+func (c *Client) AllListSongs(ctx context.Context, listID uint, size int) iter.Seq2[Song, error] {
+	return paginate(size, func(page, size int) ([]Song, error) {
+		return c.ListSongsPage(ctx, listID, page, size)
+	})
+}
+
+func (c *Client) AddToList(ctx context.Context, listID, songID uint) error {
+	return c.do(ctx, request{method: http.MethodPut, path: endpoint("me", "lists", listID, "songs", songID)}, nil)
+}
+
+func (c *Client) RemoveFromList(ctx context.Context, listID, songID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("me", "lists", listID, "songs", songID)}, nil)
+}
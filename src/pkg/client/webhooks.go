@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Delivery statuses. A dead delivery failed every attempt and waits to be
+// retried by hand.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Webhook subscribes a URL to events, an empty Events list to every event.
+// Secret is only returned on creation.
+type Webhook struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRequest subscribes a URL to events. A secret of 16 to 255
+// characters is generated when empty.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook.
+type WebhookDelivery struct {
+	ID             uint64          `json:"id"`
+	WebhookID      uint            `json:"webhook_id"`
+	EventID        *uint64         `json:"event_id,omitempty"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// Webhooks returns the webhooks without their secrets.
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.do(ctx, request{method: http.MethodGet, path: "/webhooks"}, &webhooks)
+	return webhooks, err
+}
+
+// CreateWebhook returns the webhook with its secret, the only response that
+// carries it.
+func (c *Client) CreateWebhook(ctx context.Context, webhook WebhookRequest) (Webhook, error) {
+	var created Webhook
+	err := c.do(ctx, request{method: http.MethodPost, path: "/webhooks", body: webhook}, &created)
+	return created, err
+}
+
+func (c *Client) Webhook(ctx context.Context, webhookID uint) (Webhook, error) {
+	var webhook Webhook
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("webhooks", webhookID)}, &webhook)
+	return webhook, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: endpoint("webhooks", webhookID)}, nil)
+}
+
+// Deliveries returns the latest deliveries of the webhook with the status,
+// or of any status when status is empty.
+func (c *Client) Deliveries(ctx context.Context, webhookID uint, status string) ([]WebhookDelivery, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, request{method: http.MethodGet, path: endpoint("webhooks", webhookID, "deliveries"), query: query}, &deliveries)
+	return deliveries, err
+}
+
+// DeadLetters returns the latest deliveries that failed every attempt.
+func (c *Client) DeadLetters(ctx context.Context) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, request{method: http.MethodGet, path: "/webhooks/dead-letters"}, &deliveries)
+	return deliveries, err
+}
+
+// RetryDelivery queues a dead delivery again.
+func (c *Client) RetryDelivery(ctx context.Context, deliveryID uint64) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := c.do(ctx, request{method: http.MethodPost, path: endpoint("webhooks", "deliveries", deliveryID, "retry")}, &delivery)
+	return delivery, err
+}